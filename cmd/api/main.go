@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
@@ -14,8 +16,12 @@ import (
 
 	"backend-challenge/internal/application"
 	"backend-challenge/internal/config"
+	"backend-challenge/internal/domain"
 	jwtinfra "backend-challenge/internal/infrastructure/jwt"
+	"backend-challenge/internal/infrastructure/mailer"
+	"backend-challenge/internal/infrastructure/memory"
 	mongorepo "backend-challenge/internal/infrastructure/mongo"
+	"backend-challenge/internal/infrastructure/password"
 	grpcsvc "backend-challenge/internal/transport/grpcsvc"
 	transport "backend-challenge/internal/transport/http"
 
@@ -46,16 +52,77 @@ func main() {
 	}
 
 	db := client.Database(cfg.MongoDatabase)
-	userRepo, err := mongorepo.NewUserRepository(db)
+	userRepo, err := mongorepo.NewUserRepository(db, mongorepo.WithUnverifiedAccountTTL(cfg.UnverifiedAccountTTL))
 	if err != nil {
 		log.Fatalf("init user repository: %v", err)
 	}
 
-	userService := application.NewUserService(userRepo)
-	jwtManager := jwtinfra.NewManager(cfg.JWTSecret, cfg.JWTExpiry, cfg.JWTIssuer)
+	refreshRepo, err := mongorepo.NewRefreshTokenRepository(db)
+	if err != nil {
+		log.Fatalf("init refresh token repository: %v", err)
+	}
+
+	revocationStore, err := mongorepo.NewTokenRevocationStore(db)
+	if err != nil {
+		log.Fatalf("init token revocation store: %v", err)
+	}
+
+	oneTimeTokenRepo, err := mongorepo.NewOneTimeTokenRepository(db)
+	if err != nil {
+		log.Fatalf("init one-time token repository: %v", err)
+	}
+
+	loginAttempts, err := mongorepo.NewLoginAttemptStore(db)
+	if err != nil {
+		log.Fatalf("init login attempt store: %v", err)
+	}
+
+	mailSender, err := newMailer(cfg)
+	if err != nil {
+		log.Fatalf("init mailer: %v", err)
+	}
+	if closer, ok := mailSender.(io.Closer); ok {
+		defer closer.Close()
+	}
 
-	httpHandler := transport.NewHandler(userService, jwtManager)
-	httpRouter := transport.NewRouter(httpHandler, jwtManager)
+	passwordHasher := newPasswordHasher(cfg)
+	passwordPolicy := newPasswordPolicy(cfg)
+	eventPublisher, eventSource := newUserEvents(cfg, db)
+	jwtManager, err := newJWTManager(cfg)
+	if err != nil {
+		log.Fatalf("init jwt manager: %v", err)
+	}
+	sessionService := application.NewSessionService(jwtManager, refreshRepo, revocationStore, cfg.RefreshTTL, application.WithPasswordChangeCheck(userRepo))
+	loginThrottle := application.NewLoginThrottle(loginAttempts, application.LoginThrottlePolicy{
+		FreeAttempts:     cfg.LoginFreeAttempts,
+		IPFreeAttempts:   cfg.LoginIPFreeAttempts,
+		BaseDelay:        cfg.LoginBackoffBase,
+		MaxDelay:         cfg.LoginBackoffMax,
+		LockoutThreshold: cfg.LockoutThreshold,
+		LockoutDuration:  cfg.LockoutDuration,
+		Window:           cfg.LoginAttemptWindow,
+	})
+	userService := application.NewUserService(userRepo,
+		application.WithVerificationMode(application.VerificationMode(cfg.EmailVerification)),
+		application.WithPasswordHasher(passwordHasher),
+		application.WithPasswordPolicy(passwordPolicy),
+		application.WithLoginThrottle(loginThrottle),
+		application.WithEventPublisher(eventPublisher),
+		application.WithEventSource(eventSource),
+		application.WithSessions(sessionService),
+		application.WithOneTimeTokens(oneTimeTokenRepo),
+	)
+	if err := userService.PromoteAdmins(context.Background(), cfg.AdminEmails); err != nil {
+		log.Fatalf("promote admins: %v", err)
+	}
+	resetService := application.NewPasswordResetService(userRepo, passwordHasher, oneTimeTokenRepo, sessionService, mailSender, cfg.ResetTTL, cfg.ResetURL, application.WithResetPasswordPolicy(passwordPolicy))
+	mfaService := application.NewMFAService(userRepo, oneTimeTokenRepo, cfg.MFAIssuer, cfg.MFAChallengeTTL,
+		application.WithMFAEventPublisher(eventPublisher), application.WithMFALoginThrottle(loginThrottle))
+	verificationService := application.NewEmailVerificationService(userRepo, oneTimeTokenRepo, mailSender, cfg.VerificationTTL, cfg.VerifyURL,
+		application.WithVerificationEventPublisher(eventPublisher))
+
+	httpHandler := transport.NewHandler(userService, sessionService, resetService, verificationService, mfaService)
+	httpRouter := transport.NewRouter(httpHandler, sessionService, jwtManager)
 	httpServer := &http.Server{
 		Addr:    ":" + cfg.Port,
 		Handler: httpRouter,
@@ -67,10 +134,15 @@ func main() {
 	}
 	defer grpcListener.Close()
 
+	authPolicies, err := grpcsvc.DefaultPolicies()
+	if err != nil {
+		log.Fatalf("load grpc auth policies: %v", err)
+	}
 	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(grpcsvc.AuthUnaryInterceptor(jwtManager)),
+		grpc.ChainUnaryInterceptor(grpcsvc.AuthUnaryInterceptor(sessionService, authPolicies)),
+		grpc.ChainStreamInterceptor(grpcsvc.AuthStreamInterceptor(sessionService, authPolicies)),
 	)
-	grpcService := grpcsvc.NewUserServer(userService, jwtManager)
+	grpcService := grpcsvc.NewUserServer(userService, sessionService, resetService, verificationService, mfaService)
 	grpcService.Register(grpcServer)
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
@@ -141,6 +213,18 @@ func main() {
 		return nil
 	})
 
+	group.Go(func() error {
+		runUserPurgeWorker(groupCtx, userService, cfg.UserPurgeInterval, cfg.DeletedUserRetention)
+		return nil
+	})
+
+	if cfg.JWTKeyringFile != "" {
+		group.Go(func() error {
+			watchKeyringReloads(groupCtx, jwtManager, cfg.JWTKeyringFile)
+			return nil
+		})
+	}
+
 	if err := group.Wait(); err != nil {
 		log.Printf("server stopped with error: %v", err)
 	} else {
@@ -148,6 +232,160 @@ func main() {
 	}
 }
 
+// newPasswordHasher hashes with the configured algorithm while still
+// accepting the other, so switching PASSWORD_HASH upgrades users as they log in.
+func newPasswordHasher(cfg config.Config) *password.Hasher {
+	bcryptHasher := password.Bcrypt{Cost: cfg.BcryptCost}
+	argon2Hasher := password.DefaultArgon2id
+	argon2Hasher.Memory = uint32(cfg.Argon2Memory)
+	argon2Hasher.Iterations = uint32(cfg.Argon2Iterations)
+	argon2Hasher.Parallelism = uint8(cfg.Argon2Parallelism)
+
+	if cfg.PasswordHash == "bcrypt" {
+		return password.New(cfg.HashConcurrency, bcryptHasher, argon2Hasher)
+	}
+	return password.New(cfg.HashConcurrency, argon2Hasher, bcryptHasher)
+}
+
+func newPasswordPolicy(cfg config.Config) domain.PasswordPolicy {
+	policy := domain.PasswordPolicy{MinLength: cfg.PasswordMinLength, MaxLength: cfg.PasswordMaxLength}
+	for _, rule := range cfg.PasswordRequire {
+		switch rule {
+		case "upper":
+			policy.RequireUpper = true
+		case "lower":
+			policy.RequireLower = true
+		case "digit":
+			policy.RequireDigit = true
+		case "symbol":
+			policy.RequireSymbol = true
+		}
+	}
+	return policy
+}
+
+func newMailer(cfg config.Config) (application.Mailer, error) {
+	if cfg.Mailer == "smtp" {
+		smtpMailer, err := mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return smtpMailer, nil
+	}
+
+	var (
+		logMailer *mailer.LogMailer
+		err       error
+	)
+	if cfg.MailLogFile != "" {
+		logMailer, err = mailer.OpenFileMailer(cfg.MailLogFile, cfg.MailFrom)
+	} else {
+		logMailer, err = mailer.NewLogMailer(os.Stdout, cfg.MailFrom)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return logMailer, nil
+}
+
+// newUserEvents picks where WatchUsers reads changes from. The in-process
+// broker only sees writes made by this instance; the Mongo change stream sees
+// every instance's writes, so nothing needs to be published to it.
+func newUserEvents(cfg config.Config, db *mongo.Database) (application.UserEventPublisher, application.UserEventSource) {
+	if cfg.UserEvents == "mongo" {
+		return nil, mongorepo.NewUserEventSource(db)
+	}
+	broker := memory.NewUserEventBroker(cfg.UserEventHistory)
+	return broker, broker
+}
+
+func newJWTManager(cfg config.Config) (*jwtinfra.Manager, error) {
+	active, verify, err := loadKeyring(cfg.JWTKeyring)
+	if err != nil {
+		return nil, err
+	}
+	keyring, err := jwtinfra.NewKeyring(active, verify...)
+	if err != nil {
+		return nil, err
+	}
+	return jwtinfra.NewManagerWithKeyring(keyring, cfg.JWTExpiry, cfg.JWTIssuer), nil
+}
+
+func loadKeyring(ring config.JWTKeyringConfig) (jwtinfra.SigningKey, []jwtinfra.VerificationKey, error) {
+	var (
+		active jwtinfra.SigningKey
+		verify []jwtinfra.VerificationKey
+	)
+	for _, entry := range ring.Keys {
+		isActive := entry.ID == ring.Active
+		key, err := loadKey(entry, isActive)
+		if err != nil {
+			return jwtinfra.SigningKey{}, nil, fmt.Errorf("load key %q: %w", entry.ID, err)
+		}
+		if isActive {
+			active = key
+			continue
+		}
+		v := jwtinfra.VerificationKey{Key: key}
+		if entry.RetiresAt != nil {
+			v.RetiresAt = *entry.RetiresAt
+		}
+		verify = append(verify, v)
+	}
+	return active, verify, nil
+}
+
+func loadKey(entry config.JWTKeyConfig, signing bool) (jwtinfra.SigningKey, error) {
+	switch {
+	case entry.Secret != "":
+		return jwtinfra.NewHMACKey(entry.ID, []byte(entry.Secret)), nil
+	case signing:
+		return jwtinfra.LoadSigningKeyFile(entry.ID, entry.File)
+	default:
+		return jwtinfra.LoadVerificationKeyFile(entry.ID, entry.File)
+	}
+}
+
+// watchKeyringReloads re-reads the keyring file on SIGHUP, promoting a new
+// active key without a restart. The previous key keeps verifying until the
+// tokens it signed expire; other keys removed from the file stop verifying.
+func watchKeyringReloads(ctx context.Context, manager *jwtinfra.Manager, path string) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			if err := reloadKeyring(manager, path); err != nil {
+				log.Printf("keyring reload error: %v", err)
+				continue
+			}
+			log.Printf("keyring reloaded, active key %s", manager.Keyring().Active().ID)
+		}
+	}
+}
+
+func reloadKeyring(manager *jwtinfra.Manager, path string) error {
+	ringCfg, err := config.LoadKeyring(path)
+	if err != nil {
+		return err
+	}
+	active, verify, err := loadKeyring(ringCfg)
+	if err != nil {
+		return err
+	}
+	return manager.Reload(active, verify)
+}
+
 func runUserCountWorker(ctx context.Context, service *application.UserService, interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
@@ -168,3 +406,28 @@ func runUserCountWorker(ctx context.Context, service *application.UserService, i
 		}
 	}
 }
+
+// runUserPurgeWorker permanently removes users deleted more than retention
+// ago, checking every interval.
+func runUserPurgeWorker(ctx context.Context, service *application.UserService, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purgeCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			purged, err := service.PurgeDeleted(purgeCtx, retention)
+			cancel()
+			if err != nil {
+				log.Printf("user purge worker error: %v", err)
+				continue
+			}
+			if purged > 0 {
+				log.Printf("purged %d deleted users", purged)
+			}
+		}
+	}
+}
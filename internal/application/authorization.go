@@ -0,0 +1,102 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"backend-challenge/internal/domain"
+)
+
+// Actor is the authenticated caller a use case runs on behalf of.
+type Actor struct {
+	UserID string
+	Roles  []domain.Role
+}
+
+// ActorFromClaims builds the actor described by a verified access token.
+func ActorFromClaims(claims AccessClaims) Actor {
+	return Actor{UserID: claims.UserID, Roles: claims.Roles}
+}
+
+// HasRole reports whether the actor holds role.
+func (a Actor) HasRole(role domain.Role) bool {
+	return domain.HasRole(a.Roles, role)
+}
+
+type actorKey struct{}
+
+// WithActor records the caller that use cases invoked with ctx act on behalf
+// of. Transports set it through authctx once a token has been verified.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor recorded by WithActor.
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
+
+// Action names an operation subject to authorization.
+type Action string
+
+const (
+	ActionListUsers      Action = "users.list"
+	ActionWatchUsers     Action = "users.watch"
+	ActionReadUser       Action = "users.read"
+	ActionUpdateUser     Action = "users.update"
+	ActionDeleteUser     Action = "users.delete"
+	ActionRestoreUser    Action = "users.restore"
+	ActionChangePassword Action = "users.change_password"
+)
+
+// ForbiddenError reports which action an actor was refused. It matches
+// ErrForbidden with errors.Is.
+type ForbiddenError struct {
+	Action   Action
+	TargetID string
+}
+
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("%s: not allowed to %s", ErrForbidden, e.Action)
+}
+
+func (e *ForbiddenError) Unwrap() error {
+	return ErrForbidden
+}
+
+// Policy decides whether an actor may perform an action. targetID is the user
+// acted on, or empty for actions without a single target.
+type Policy interface {
+	Authorize(actor Actor, action Action, targetID string) error
+}
+
+// RolePolicy lets admins read, update, and delete any account and everyone
+// else only their own. Listing, watching, and restoring users is reserved for
+// admins, and a password can only be changed by its owner.
+type RolePolicy struct{}
+
+// Authorize returns a *ForbiddenError when the actor may not perform the
+// action.
+func (RolePolicy) Authorize(actor Actor, action Action, targetID string) error {
+	if actor.UserID == "" {
+		return &ForbiddenError{Action: action, TargetID: targetID}
+	}
+	self := targetID != "" && targetID == actor.UserID
+
+	switch action {
+	case ActionReadUser, ActionUpdateUser, ActionDeleteUser:
+		if self || actor.HasRole(domain.RoleAdmin) {
+			return nil
+		}
+	case ActionListUsers, ActionWatchUsers, ActionRestoreUser:
+		if actor.HasRole(domain.RoleAdmin) {
+			return nil
+		}
+	case ActionChangePassword:
+		if self {
+			return nil
+		}
+	}
+	return &ForbiddenError{Action: action, TargetID: targetID}
+}
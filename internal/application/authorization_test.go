@@ -0,0 +1,129 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+	"backend-challenge/internal/infrastructure/memory"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRolePolicy(t *testing.T) {
+	policy := application.RolePolicy{}
+	user := application.Actor{UserID: "u1", Roles: []domain.Role{domain.RoleUser}}
+	admin := application.Actor{UserID: "a1", Roles: []domain.Role{domain.RoleUser, domain.RoleAdmin}}
+
+	tests := []struct {
+		name   string
+		actor  application.Actor
+		action application.Action
+		target string
+		allow  bool
+	}{
+		{"user lists", user, application.ActionListUsers, "", false},
+		{"user reads self", user, application.ActionReadUser, "u1", true},
+		{"user reads other", user, application.ActionReadUser, "u2", false},
+		{"admin reads other", admin, application.ActionReadUser, "u2", true},
+		{"user changes own password", user, application.ActionChangePassword, "u1", true},
+		{"admin changes other password", admin, application.ActionChangePassword, "u2", false},
+		{"admin lists", admin, application.ActionListUsers, "", true},
+		{"user updates self", user, application.ActionUpdateUser, "u1", true},
+		{"user updates other", user, application.ActionUpdateUser, "u2", false},
+		{"user deletes self", user, application.ActionDeleteUser, "u1", true},
+		{"user deletes other", user, application.ActionDeleteUser, "u2", false},
+		{"admin updates other", admin, application.ActionUpdateUser, "u2", true},
+		{"admin deletes other", admin, application.ActionDeleteUser, "u2", true},
+		{"anonymous", application.Actor{}, application.ActionUpdateUser, "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.Authorize(tc.actor, tc.action, tc.target)
+			if tc.allow {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, application.ErrForbidden)
+			}
+		})
+	}
+}
+
+func TestUserServiceEnforcesPolicy(t *testing.T) {
+	service, ctx := newService()
+	alice, err := service.Register(ctx, application.RegisterInput{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	require.Equal(t, []domain.Role{domain.RoleUser}, alice.Roles)
+
+	_, err = service.List(ctx, application.ListInput{})
+	require.ErrorIs(t, err, application.ErrForbidden, "calls without an actor are refused")
+
+	mallory := asUser(ctx, "mallory")
+	_, err = service.List(mallory, application.ListInput{})
+	require.ErrorIs(t, err, application.ErrForbidden)
+	_, err = service.Get(mallory, alice.ID)
+	require.ErrorIs(t, err, application.ErrForbidden)
+	_, err = service.Update(mallory, alice.ID, application.UpdateInput{Name: strPtr("Pwned")})
+	var forbidden *application.ForbiddenError
+	require.ErrorAs(t, err, &forbidden)
+	require.Equal(t, application.ActionUpdateUser, forbidden.Action)
+	require.Equal(t, alice.ID, forbidden.TargetID)
+	require.ErrorIs(t, service.Delete(mallory, alice.ID), application.ErrForbidden)
+	require.ErrorIs(t, service.ChangePassword(mallory, alice.ID, "password123", "newpassword"), application.ErrForbidden)
+
+	self := asUser(ctx, alice.ID)
+	_, err = service.Get(self, alice.ID)
+	require.NoError(t, err)
+	require.NoError(t, service.ChangePassword(self, alice.ID, "password123", "newpassword"))
+
+	require.ErrorIs(t, service.ChangePassword(asAdmin(ctx), alice.ID, "newpassword", "otherpassword"), application.ErrForbidden, "only the owner changes a password")
+	page, err := service.List(asAdmin(ctx), application.ListInput{})
+	require.NoError(t, err)
+	require.Len(t, page.Users, 1)
+	_, err = service.Get(asAdmin(ctx), alice.ID)
+	require.NoError(t, err)
+}
+
+func TestPromoteAdmins(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewUserRepository()
+	service := application.NewUserService(repo)
+	user, err := service.Register(ctx, application.RegisterInput{Name: "Root", Email: "root@example.com", Password: "password123"})
+	require.NoError(t, err)
+	unverified, err := service.Register(ctx, application.RegisterInput{Name: "Squatter", Email: "ops@example.com", Password: "password123"})
+	require.NoError(t, err)
+	_, err = repo.MarkVerified(ctx, user.ID, time.Now())
+	require.NoError(t, err)
+
+	require.NoError(t, service.PromoteAdmins(ctx, []string{" ROOT@example.com ", "missing@example.com", "ops@example.com"}))
+	require.NoError(t, service.PromoteAdmins(ctx, []string{"root@example.com"}))
+
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, []domain.Role{domain.RoleUser, domain.RoleAdmin}, stored.Roles)
+	stored, err = repo.GetByID(ctx, unverified.ID)
+	require.NoError(t, err)
+	require.Equal(t, []domain.Role{domain.RoleUser}, stored.Roles)
+}
+
+func TestPromoteAdminsSkipsChangedEmail(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewUserRepository()
+	service := application.NewUserService(repo)
+	user, err := service.Register(ctx, application.RegisterInput{Name: "Mallory", Email: "mallory@example.com", Password: "password123"})
+	require.NoError(t, err)
+	_, err = repo.MarkVerified(ctx, user.ID, time.Now())
+	require.NoError(t, err)
+
+	// Verifying the old address must not vouch for the one listed as admin.
+	_, err = service.Update(asUser(ctx, user.ID), user.ID, application.UpdateInput{Email: strPtr("root@example.com")})
+	require.NoError(t, err)
+	require.NoError(t, service.PromoteAdmins(ctx, []string{"root@example.com"}))
+
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, []domain.Role{domain.RoleUser}, stored.Roles)
+}
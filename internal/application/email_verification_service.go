@@ -0,0 +1,132 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend-challenge/internal/domain"
+)
+
+// EmailVerificationService sends and redeems email verification tokens.
+type EmailVerificationService struct {
+	users     UserRepository
+	tokens    OneTimeTokenRepository
+	mailer    Mailer
+	ttl       time.Duration
+	verifyURL string
+	now       func() time.Time
+	publisher UserEventPublisher
+}
+
+// EmailVerificationOption customises an EmailVerificationService.
+type EmailVerificationOption func(*EmailVerificationService)
+
+// WithVerificationClock sets the clock verification tokens are issued and
+// checked against. It defaults to time.Now.
+func WithVerificationClock(now func() time.Time) EmailVerificationOption {
+	return func(s *EmailVerificationService) {
+		s.now = now
+	}
+}
+
+// WithVerificationEventPublisher publishes an update whenever a user confirms
+// their email address. A nil publisher keeps the default, which discards
+// events.
+func WithVerificationEventPublisher(publisher UserEventPublisher) EmailVerificationOption {
+	return func(s *EmailVerificationService) {
+		if publisher != nil {
+			s.publisher = publisher
+		}
+	}
+}
+
+// NewEmailVerificationService constructs an email verification service. Emails
+// link to verifyURL with the token in the "token" query parameter.
+func NewEmailVerificationService(users UserRepository, tokens OneTimeTokenRepository, mailer Mailer, ttl time.Duration, verifyURL string, opts ...EmailVerificationOption) *EmailVerificationService {
+	s := &EmailVerificationService{
+		users:     users,
+		tokens:    tokens,
+		mailer:    mailer,
+		ttl:       ttl,
+		verifyURL: verifyURL,
+		now:       time.Now,
+		publisher: noopPublisher{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Send emails a fresh verification link to the user, invalidating any link
+// sent earlier. Verified users are skipped.
+func (s *EmailVerificationService) Send(ctx context.Context, user domain.User) error {
+	if user.Verified() {
+		return nil
+	}
+
+	if err := s.tokens.DeleteByUser(ctx, user.ID, domain.PurposeEmailVerification); err != nil {
+		return err
+	}
+
+	raw, err := randomToken()
+	if err != nil {
+		return err
+	}
+
+	now := s.now().UTC()
+	record := domain.OneTimeToken{
+		UserID:    user.ID,
+		Purpose:   domain.PurposeEmailVerification,
+		TokenHash: hashToken(raw),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if _, err := s.tokens.Create(ctx, record); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Confirm your email address by following this link:\n\n%s\n\nThe link expires in %s.\n", withToken(s.verifyURL, raw), s.ttl)
+	return s.mailer.Send(ctx, Message{
+		To:      user.Email,
+		Subject: "Confirm your email address",
+		Body:    body,
+	})
+}
+
+// Resend looks the user up and sends them a new verification link.
+func (s *EmailVerificationService) Resend(ctx context.Context, userID string) error {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.Send(ctx, user)
+}
+
+// Verify redeems a verification token and marks the user's email as confirmed.
+func (s *EmailVerificationService) Verify(ctx context.Context, token string) (domain.User, error) {
+	if token == "" {
+		return domain.User{}, ErrInvalidVerificationToken
+	}
+
+	now := s.now().UTC()
+	redeemed, err := s.tokens.Consume(ctx, domain.PurposeEmailVerification, hashToken(token), now)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return domain.User{}, ErrInvalidVerificationToken
+		}
+		return domain.User{}, err
+	}
+
+	user, err := s.users.MarkVerified(ctx, redeemed.UserID, now)
+	if err != nil {
+		return domain.User{}, err
+	}
+	s.publisher.Publish(ctx, domain.NewUserEvent(domain.UserUpdated, user))
+	if err := s.tokens.DeleteByUser(ctx, user.ID, domain.PurposeEmailVerification); err != nil {
+		return domain.User{}, err
+	}
+	return user, nil
+}
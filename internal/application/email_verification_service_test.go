@@ -0,0 +1,144 @@
+package application_test
+
+import (
+	"testing"
+	"time"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+	"backend-challenge/internal/infrastructure/memory"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailVerificationFlow(t *testing.T) {
+	f := newAccountFixture(t, application.WithVerificationMode(application.VerificationRequired))
+
+	_, err := f.users.Authenticate(f.ctx, "jane@example.com", "password123")
+	require.ErrorIs(t, err, application.ErrEmailNotVerified)
+
+	require.NoError(t, f.verifications.Send(f.ctx, f.user))
+	msg := f.mailer.last(t)
+	require.Equal(t, "jane@example.com", msg.To)
+	token := tokenFromLink(t, msg)
+
+	verified, err := f.verifications.Verify(f.ctx, token)
+	require.NoError(t, err)
+	require.True(t, verified.Verified())
+	require.True(t, verified.Sanitize().EmailVerified)
+
+	_, err = f.users.Authenticate(f.ctx, "jane@example.com", "password123")
+	require.NoError(t, err)
+
+	_, err = f.verifications.Verify(f.ctx, token)
+	require.ErrorIs(t, err, application.ErrInvalidVerificationToken)
+}
+
+func TestEmailVerificationSkipsVerifiedUsers(t *testing.T) {
+	f := newAccountFixture(t)
+
+	require.NoError(t, f.verifications.Send(f.ctx, f.user))
+	_, err := f.verifications.Verify(f.ctx, tokenFromLink(t, f.mailer.last(t)))
+	require.NoError(t, err)
+
+	require.NoError(t, f.verifications.Resend(f.ctx, f.user.ID))
+	require.Len(t, f.mailer.sent, 1)
+}
+
+func TestEmailVerificationResendInvalidatesOldToken(t *testing.T) {
+	f := newAccountFixture(t)
+
+	require.NoError(t, f.verifications.Send(f.ctx, f.user))
+	first := tokenFromLink(t, f.mailer.last(t))
+	require.NoError(t, f.verifications.Resend(f.ctx, f.user.ID))
+	second := tokenFromLink(t, f.mailer.last(t))
+
+	_, err := f.verifications.Verify(f.ctx, first)
+	require.ErrorIs(t, err, application.ErrInvalidVerificationToken)
+	_, err = f.verifications.Verify(f.ctx, second)
+	require.NoError(t, err)
+}
+
+func TestEmailVerificationExpiredToken(t *testing.T) {
+	f := newAccountFixture(t)
+
+	require.NoError(t, f.verifications.Send(f.ctx, f.user))
+	token := tokenFromLink(t, f.mailer.last(t))
+	f.clock.Advance(time.Hour + time.Millisecond)
+
+	_, err := f.verifications.Verify(f.ctx, token)
+	require.ErrorIs(t, err, application.ErrInvalidVerificationToken)
+	_, err = f.verifications.Verify(f.ctx, "")
+	require.ErrorIs(t, err, application.ErrInvalidVerificationToken)
+}
+
+func TestVerificationModes(t *testing.T) {
+	tests := []struct {
+		mode       application.VerificationMode
+		canSignIn  bool
+		restricted bool
+	}{
+		{mode: application.VerificationOptional, canSignIn: true, restricted: false},
+		{mode: application.VerificationLimited, canSignIn: true, restricted: true},
+		{mode: application.VerificationRequired, canSignIn: false, restricted: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			f := newAccountFixture(t, application.WithVerificationMode(tc.mode))
+
+			require.Equal(t, tc.canSignIn, f.users.CanSignIn(f.user))
+			self := application.WithActor(f.ctx, application.Actor{UserID: f.user.ID})
+			_, err := f.users.Update(self, f.user.ID, application.UpdateInput{Name: strPtr("Janet")})
+			if tc.restricted {
+				require.ErrorIs(t, err, application.ErrEmailNotVerified)
+				require.ErrorIs(t, f.users.Delete(self, f.user.ID), application.ErrEmailNotVerified)
+			} else {
+				require.NoError(t, err)
+			}
+			_, err = f.users.Get(self, f.user.ID)
+			require.NoError(t, err)
+
+			require.NoError(t, f.verifications.Send(f.ctx, f.user))
+			_, err = f.verifications.Verify(f.ctx, tokenFromLink(t, f.mailer.last(t)))
+			require.NoError(t, err)
+			_, err = f.users.Update(self, f.user.ID, application.UpdateInput{Name: strPtr("Jane")})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEmailVerificationPublishesUpdate(t *testing.T) {
+	f := newAccountFixture(t)
+	publisher := &recordingPublisher{}
+	verifications := application.NewEmailVerificationService(f.repo, memory.NewOneTimeTokenRepository(), f.mailer, time.Hour, "https://app.example.com/verify",
+		application.WithVerificationClock(f.clock.Now), application.WithVerificationEventPublisher(publisher))
+
+	require.NoError(t, verifications.Send(f.ctx, f.user))
+	_, err := verifications.Verify(f.ctx, tokenFromLink(t, f.mailer.last(t)))
+	require.NoError(t, err)
+
+	require.Len(t, publisher.events, 1)
+	require.Equal(t, domain.UserUpdated, publisher.events[0].Type)
+	require.True(t, publisher.events[0].User.Sanitize().EmailVerified)
+}
+
+func TestEmailChangeRequiresVerification(t *testing.T) {
+	f := newAccountFixture(t)
+	_, err := f.repo.MarkVerified(f.ctx, f.user.ID, f.clock.Now())
+	require.NoError(t, err)
+
+	updated, err := f.users.Update(asUser(f.ctx, f.user.ID), f.user.ID, application.UpdateInput{Email: strPtr("jane@elsewhere.example")})
+	require.NoError(t, err)
+	require.False(t, updated.Verified())
+	stored, err := f.repo.GetByID(f.ctx, f.user.ID)
+	require.NoError(t, err)
+	require.False(t, stored.Verified())
+
+	require.NoError(t, f.verifications.Send(f.ctx, updated))
+	msg := f.mailer.last(t)
+	require.Equal(t, "jane@elsewhere.example", msg.To)
+	verified, err := f.verifications.Verify(f.ctx, tokenFromLink(t, msg))
+	require.NoError(t, err)
+	require.True(t, verified.Verified())
+}
@@ -9,6 +9,48 @@ var (
 	ErrDuplicateEmail = errors.New("email already in use")
 	// ErrInvalidCredentials indicates login failed.
 	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrTooManyAttempts indicates logins are being slowed down after repeated failures.
+	ErrTooManyAttempts = errors.New("too many failed login attempts")
+	// ErrAccountLocked indicates the account is temporarily locked after repeated failures.
+	ErrAccountLocked = errors.New("account temporarily locked")
+	// ErrCurrentPasswordMismatch indicates a password change gave the wrong current password.
+	ErrCurrentPasswordMismatch = errors.New("current password is incorrect")
+	// ErrPasswordMismatch indicates a password does not match its stored hash.
+	ErrPasswordMismatch = errors.New("password does not match")
+	// ErrForbidden indicates the caller is not allowed to perform the operation.
+	ErrForbidden = errors.New("forbidden")
 	// ErrNoFieldsToUpdate indicates update payload missing fields.
 	ErrNoFieldsToUpdate = errors.New("no fields to update")
+	// ErrVersionMismatch indicates a conditional update found the user changed since the expected version.
+	ErrVersionMismatch = errors.New("user was modified since it was read")
+	// ErrInvalidRefreshToken indicates the refresh token is unknown, expired, or revoked.
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused indicates an already rotated refresh token was presented again.
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+	// ErrInvalidToken indicates an access token failed verification.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenRevoked indicates an access token was revoked before it expired.
+	ErrTokenRevoked = errors.New("token has been revoked")
+	// ErrInvalidResetToken indicates a password reset token is unknown, expired, or already used.
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+	// ErrInvalidVerificationToken indicates an email verification token is unknown, expired, or already used.
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	// ErrEmailNotVerified indicates the account must confirm its email address first.
+	ErrEmailNotVerified = errors.New("email address not verified")
+	// ErrInvalidMFACode indicates a TOTP or recovery code was wrong or already used.
+	ErrInvalidMFACode = errors.New("invalid two-factor code")
+	// ErrInvalidMFAChallenge indicates an MFA challenge token is unknown, expired, or already used.
+	ErrInvalidMFAChallenge = errors.New("invalid or expired mfa challenge")
+	// ErrMFAAlreadyEnabled indicates two-factor authentication is already active.
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication already enabled")
+	// ErrMFANotEnrolled indicates there is no pending or active two-factor enrollment.
+	ErrMFANotEnrolled = errors.New("two-factor authentication not enrolled")
+	// ErrInvalidResumeToken indicates a watch cannot resume from the given token because it is unknown or too old.
+	ErrInvalidResumeToken = errors.New("invalid or expired resume token")
+	// ErrEventsUnavailable indicates the service was not configured with a source of user events.
+	ErrEventsUnavailable = errors.New("user events are not available")
+	// ErrInvalidCursor indicates a page cursor is malformed or was not issued by this service.
+	ErrInvalidCursor = errors.New("invalid page cursor")
+	// ErrInvalidPageSize indicates a negative page size was requested.
+	ErrInvalidPageSize = errors.New("page size must not be negative")
 )
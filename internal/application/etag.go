@@ -0,0 +1,63 @@
+package application
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"backend-challenge/internal/domain"
+)
+
+// ETag identifies the version of a user. Clients send it back with an update
+// so that it only applies if nobody changed the user in between. It is a
+// quoted string, as HTTP entity tags are, and otherwise opaque.
+func ETag(user domain.User) string {
+	return strconv.Quote(strconv.FormatInt(user.Version, 10))
+}
+
+// ProjectedETag identifies the version of a user as seen through fields, so
+// a response restricted to some fields never shares a tag with the full
+// representation. The fields follow the version, sorted, as in "3;email.name".
+// It names the same version as ETag and can be sent back with an update in
+// its place.
+func ProjectedETag(user domain.User, fields []domain.UserField) string {
+	if len(fields) == 0 {
+		return ETag(user)
+	}
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names = append(names, string(field))
+	}
+	sort.Strings(names)
+	names = slices.Compact(names)
+	return strconv.Quote(strconv.FormatInt(user.Version, 10) + ";" + strings.Join(names, "."))
+}
+
+// ETagMatches reports whether tag, full or projected, names the user's
+// current version.
+func ETagMatches(user domain.User, tag string) bool {
+	version, ok := parseETag(tag)
+	return ok && version == user.Version
+}
+
+// parseETag returns the version an ETag names. ok is false for tags this
+// service did not issue, which never match a user.
+func parseETag(tag string) (version int64, ok bool) {
+	if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		return 0, false
+	}
+	raw, projection, projected := strings.Cut(tag[1:len(tag)-1], ";")
+	if projected {
+		for _, name := range strings.Split(projection, ".") {
+			if _, err := domain.ParseUserField(name); err != nil {
+				return 0, false
+			}
+		}
+	}
+	version, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || version < 0 {
+		return 0, false
+	}
+	return version, true
+}
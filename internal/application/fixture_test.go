@@ -0,0 +1,125 @@
+package application_test
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+	jwtinfra "backend-challenge/internal/infrastructure/jwt"
+	"backend-challenge/internal/infrastructure/memory"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testClock is a clock the test moves forward by hand, so token lifetimes and
+// millisecond revocation cutoffs can be crossed without sleeping.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newTestClock() *testClock {
+	return &testClock{now: time.Now().UTC().Truncate(time.Millisecond)}
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+type recordingMailer struct {
+	mu   sync.Mutex
+	sent []application.Message
+	err  error
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg application.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func (m *recordingMailer) last(t *testing.T) application.Message {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	require.NotEmpty(t, m.sent)
+	return m.sent[len(m.sent)-1]
+}
+
+var linkPattern = regexp.MustCompile(`https?://\S+`)
+
+func tokenFromLink(t *testing.T, msg application.Message) string {
+	t.Helper()
+	link, err := url.Parse(linkPattern.FindString(msg.Body))
+	require.NoError(t, err)
+	token := link.Query().Get("token")
+	require.NotEmpty(t, token)
+	return token
+}
+
+// accountFixture wires the account flows around one in-memory store, mailer,
+// and clock, with Jane already registered.
+type accountFixture struct {
+	ctx           context.Context
+	clock         *testClock
+	repo          *memory.UserRepository
+	mailer        *recordingMailer
+	users         *application.UserService
+	sessions      *application.SessionService
+	resets        *application.PasswordResetService
+	verifications *application.EmailVerificationService
+	mfa           *application.MFAService
+	user          domain.User
+}
+
+func newAccountFixture(t *testing.T, opts ...application.UserServiceOption) accountFixture {
+	t.Helper()
+	ctx := context.Background()
+	clock := newTestClock()
+	repo := memory.NewUserRepository()
+	tokens := memory.NewOneTimeTokenRepository()
+	mailer := &recordingMailer{}
+
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer", jwtinfra.WithClock(clock.Now))
+	sessions := application.NewSessionService(manager, memory.NewRefreshTokenRepository(), memory.NewTokenRevocationStore(), time.Hour,
+		application.WithPasswordChangeCheck(repo), application.WithSessionClock(clock.Now))
+	users := application.NewUserService(repo, append([]application.UserServiceOption{application.WithClock(clock.Now), application.WithSessions(sessions)}, opts...)...)
+	resets := application.NewPasswordResetService(repo, nil, tokens, sessions, mailer, time.Hour, "https://app.example.com/reset?lang=en",
+		application.WithResetClock(clock.Now))
+	verifications := application.NewEmailVerificationService(repo, tokens, mailer, time.Hour, "https://app.example.com/verify",
+		application.WithVerificationClock(clock.Now))
+	mfa := application.NewMFAService(repo, tokens, "Backend", time.Minute, application.WithMFAClock(clock.Now))
+
+	user, err := users.Register(ctx, application.RegisterInput{Name: "Jane", Email: "jane@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	return accountFixture{
+		ctx:           ctx,
+		clock:         clock,
+		repo:          repo,
+		mailer:        mailer,
+		users:         users,
+		sessions:      sessions,
+		resets:        resets,
+		verifications: verifications,
+		mfa:           mfa,
+		user:          user,
+	}
+}
@@ -0,0 +1,34 @@
+package application
+
+import (
+	"context"
+	"time"
+)
+
+// LoginAttempts summarises the recent failed logins for one throttling key.
+type LoginAttempts struct {
+	// Failures counts consecutive failures within the tracking window.
+	Failures    int
+	LastFailure time.Time
+	// PreviousFailure is when the failure before LastFailure happened, so an
+	// attempt counted before its outcome is known can be judged by the record
+	// as it stood beforehand.
+	PreviousFailure time.Time
+}
+
+// LoginAttemptStore tracks failed logins per key, such as an email address
+// or a client IP.
+type LoginAttemptStore interface {
+	// RecordFailure counts a failure at the given time in a single atomic step
+	// and returns the updated record. A record whose last failure is older
+	// than window starts over.
+	RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (LoginAttempts, error)
+	// Release takes back a failure that RecordFailure counted and returned as
+	// recorded. When nothing has been counted since, the record goes back to
+	// how it was; otherwise only the count drops.
+	Release(ctx context.Context, key string, recorded LoginAttempts) error
+	// Get returns the record for key, or the zero value if there is none.
+	Get(ctx context.Context, key string) (LoginAttempts, error)
+	// Reset forgets the failures for key.
+	Reset(ctx context.Context, key string) error
+}
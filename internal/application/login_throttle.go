@@ -0,0 +1,225 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// LoginThrottlePolicy controls how failed logins slow down further attempts.
+type LoginThrottlePolicy struct {
+	// FreeAttempts is how many failures an account may have before backoff
+	// starts; IPFreeAttempts is the same for a client address, which is
+	// usually shared by more people.
+	FreeAttempts   int
+	IPFreeAttempts int
+	// BaseDelay is the wait after the first failure past the free attempts.
+	// It doubles with every further failure, up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockoutThreshold consecutive failures lock the account for
+	// LockoutDuration. Zero disables lockout.
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	// Window is how long failures are remembered after the most recent one.
+	Window time.Duration
+}
+
+// ThrottleError reports that a login was refused without checking the
+// password, and when it is worth trying again. It wraps ErrTooManyAttempts or
+// ErrAccountLocked.
+type ThrottleError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *ThrottleError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ThrottleError) Unwrap() error {
+	return e.Err
+}
+
+type clientIPKey struct{}
+
+// WithClientIP records the address a request came from, so login attempts
+// can be throttled per client as well as per account. Transports must only
+// pass an address a client cannot choose, such as the connection's peer or a
+// header set by a trusted proxy.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+func clientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// LoginThrottle applies exponential backoff to failed logins per email and per
+// client IP, and locks accounts after repeated consecutive failures.
+type LoginThrottle struct {
+	store  LoginAttemptStore
+	policy LoginThrottlePolicy
+	now    func() time.Time
+}
+
+// LoginThrottleOption customises a LoginThrottle.
+type LoginThrottleOption func(*LoginThrottle)
+
+// WithThrottleClock sets the clock failures are stamped and backoff is
+// measured with. It defaults to time.Now.
+func WithThrottleClock(now func() time.Time) LoginThrottleOption {
+	return func(t *LoginThrottle) {
+		t.now = now
+	}
+}
+
+// NewLoginThrottle builds a throttle backed by store.
+func NewLoginThrottle(store LoginAttemptStore, policy LoginThrottlePolicy, opts ...LoginThrottleOption) *LoginThrottle {
+	t := &LoginThrottle{store: store, policy: policy, now: time.Now}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// LoginReservation is a login attempt already counted as a failure against
+// its account and client. Attempts that turn out not to be failures must be
+// finished with Succeed or Release.
+type LoginReservation struct {
+	throttle *LoginThrottle
+	email    string
+	ip       string
+	account  LoginAttempts
+	client   LoginAttempts
+}
+
+// Reserve counts a login attempt as a failure before the password is checked,
+// so concurrent guesses cannot all pass on the same count, and judges it by
+// the records as they stood beforehand. It returns a *ThrottleError, without
+// keeping the attempt counted, when the account or client must wait.
+func (t *LoginThrottle) Reserve(ctx context.Context, email, ip string) (LoginReservation, error) {
+	now := t.now()
+	r := LoginReservation{throttle: t, email: email, ip: ip}
+
+	account, err := t.store.RecordFailure(ctx, accountKey(email), now, t.policy.Window)
+	if err != nil {
+		return LoginReservation{}, err
+	}
+	r.account = account
+	if ip != "" {
+		client, err := t.store.RecordFailure(ctx, ipKey(ip), now, t.policy.Window)
+		if err != nil {
+			return LoginReservation{}, errors.Join(err, t.store.Release(ctx, accountKey(email), account))
+		}
+		r.client = client
+	}
+
+	if err := t.check(r, now); err != nil {
+		if releaseErr := r.Release(ctx); releaseErr != nil {
+			return LoginReservation{}, releaseErr
+		}
+		return LoginReservation{}, err
+	}
+	return r, nil
+}
+
+// Succeed clears the account's failures. The client's attempt is taken back
+// but its earlier failures are kept, so one valid account cannot be used to
+// reset the counter while guessing others.
+func (r LoginReservation) Succeed(ctx context.Context) error {
+	if err := r.throttle.store.Reset(ctx, accountKey(r.email)); err != nil {
+		return err
+	}
+	if r.ip == "" {
+		return nil
+	}
+	return r.throttle.store.Release(ctx, ipKey(r.ip), r.client)
+}
+
+// Hold keeps the attempt counted against the account until the login's second
+// factor is checked, and takes the client's attempt back. The account is only
+// cleared by Reset once the second factor is accepted, so each wrong code
+// costs as much as a wrong password.
+func (r LoginReservation) Hold(ctx context.Context) error {
+	if r.ip == "" {
+		return nil
+	}
+	return r.throttle.store.Release(ctx, ipKey(r.ip), r.client)
+}
+
+// Release takes the attempt back from both the account and the client, for
+// logins that neither failed nor succeeded.
+func (r LoginReservation) Release(ctx context.Context) error {
+	if err := r.throttle.store.Release(ctx, accountKey(r.email), r.account); err != nil {
+		return err
+	}
+	if r.ip == "" {
+		return nil
+	}
+	return r.throttle.store.Release(ctx, ipKey(r.ip), r.client)
+}
+
+// Reset clears the account's failures once a login held for its second factor
+// has completed.
+func (t *LoginThrottle) Reset(ctx context.Context, email string) error {
+	return t.store.Reset(ctx, accountKey(email))
+}
+
+// check returns a *ThrottleError when the reserved attempt came too soon
+// after the failures recorded before it.
+func (t *LoginThrottle) check(r LoginReservation, now time.Time) error {
+	account := before(r.account)
+	if until := t.lockedUntil(account); now.Before(until) {
+		return &ThrottleError{Err: ErrAccountLocked, RetryAfter: until.Sub(now)}
+	}
+	retry := t.blockedUntil(account, t.policy.FreeAttempts).Sub(now)
+
+	if r.ip != "" {
+		if wait := t.blockedUntil(before(r.client), t.policy.IPFreeAttempts).Sub(now); wait > retry {
+			retry = wait
+		}
+	}
+
+	if retry > 0 {
+		return &ThrottleError{Err: ErrTooManyAttempts, RetryAfter: retry}
+	}
+	return nil
+}
+
+// before returns the record as it was before its most recent failure.
+func before(attempts LoginAttempts) LoginAttempts {
+	return LoginAttempts{Failures: attempts.Failures - 1, LastFailure: attempts.PreviousFailure}
+}
+
+func (t *LoginThrottle) lockedUntil(attempts LoginAttempts) time.Time {
+	if t.policy.LockoutThreshold <= 0 || attempts.Failures < t.policy.LockoutThreshold {
+		return time.Time{}
+	}
+	return attempts.LastFailure.Add(t.policy.LockoutDuration)
+}
+
+func (t *LoginThrottle) blockedUntil(attempts LoginAttempts, free int) time.Time {
+	excess := attempts.Failures - free
+	if excess <= 0 {
+		return time.Time{}
+	}
+
+	delay := t.policy.BaseDelay
+	for i := 1; i < excess && delay < t.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > t.policy.MaxDelay {
+		delay = t.policy.MaxDelay
+	}
+	return attempts.LastFailure.Add(delay)
+}
+
+func accountKey(email string) string {
+	return "email:" + email
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
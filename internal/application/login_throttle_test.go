@@ -0,0 +1,228 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+	"backend-challenge/internal/infrastructure/memory"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newThrottledService(t *testing.T, policy application.LoginThrottlePolicy) (*application.UserService, context.Context) {
+	t.Helper()
+	repo := memory.NewUserRepository()
+	throttle := application.NewLoginThrottle(memory.NewLoginAttemptStore(), policy)
+	service := application.NewUserService(repo, application.WithLoginThrottle(throttle))
+	ctx := context.Background()
+
+	_, err := service.Register(ctx, application.RegisterInput{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	return service, ctx
+}
+
+func requireThrottled(t *testing.T, err error, sentinel error, atLeast time.Duration) {
+	t.Helper()
+	require.ErrorIs(t, err, sentinel)
+	var throttled *application.ThrottleError
+	require.True(t, errors.As(err, &throttled))
+	require.Greater(t, throttled.RetryAfter, atLeast-time.Second)
+	require.LessOrEqual(t, throttled.RetryAfter, atLeast)
+}
+
+func TestLoginThrottleBacksOffPerAccount(t *testing.T) {
+	service, ctx := newThrottledService(t, application.LoginThrottlePolicy{
+		FreeAttempts:   2,
+		IPFreeAttempts: 100,
+		BaseDelay:      time.Minute,
+		MaxDelay:       time.Hour,
+		Window:         time.Hour,
+	})
+
+	for i := 0; i < 3; i++ {
+		_, err := service.Authenticate(ctx, "alice@example.com", "wrong-password")
+		require.ErrorIs(t, err, application.ErrInvalidCredentials)
+	}
+
+	_, err := service.Authenticate(ctx, "alice@example.com", "password123")
+	requireThrottled(t, err, application.ErrTooManyAttempts, time.Minute)
+
+	_, err = service.Authenticate(ctx, "bob@example.com", "password123")
+	require.ErrorIs(t, err, application.ErrInvalidCredentials, "other accounts are unaffected")
+}
+
+func TestLoginThrottleBackoffDoubles(t *testing.T) {
+	clock := newTestClock()
+	throttle := application.NewLoginThrottle(memory.NewLoginAttemptStore(), application.LoginThrottlePolicy{
+		FreeAttempts:   1,
+		IPFreeAttempts: 100,
+		BaseDelay:      time.Minute,
+		MaxDelay:       3 * time.Minute,
+		Window:         time.Hour,
+	}, application.WithThrottleClock(clock.Now))
+	ctx := context.Background()
+
+	for _, want := range []time.Duration{0, time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+		_, err := throttle.Reserve(ctx, "alice@example.com", "")
+		require.NoError(t, err)
+		next, err := throttle.Reserve(ctx, "alice@example.com", "")
+		if want == 0 {
+			require.NoError(t, err)
+			require.NoError(t, next.Release(ctx))
+		} else {
+			requireThrottled(t, err, application.ErrTooManyAttempts, want)
+		}
+		clock.Advance(3 * time.Minute)
+	}
+}
+
+func TestLoginThrottleConcurrentGuesses(t *testing.T) {
+	service, ctx := newThrottledService(t, application.LoginThrottlePolicy{
+		FreeAttempts:   2,
+		IPFreeAttempts: 100,
+		BaseDelay:      time.Minute,
+		MaxDelay:       time.Hour,
+		Window:         time.Hour,
+	})
+
+	const guesses = 20
+	results := make(chan error, guesses)
+	var wg sync.WaitGroup
+	for i := 0; i < guesses; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.Authenticate(ctx, "alice@example.com", "wrong-password")
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	checked := 0
+	for err := range results {
+		if errors.Is(err, application.ErrInvalidCredentials) {
+			checked++
+			continue
+		}
+		require.ErrorIs(t, err, application.ErrTooManyAttempts)
+	}
+	require.Equal(t, 3, checked, "only the free attempts and the one after them reach the password check")
+}
+
+func TestLoginThrottleRefusalIsNotCounted(t *testing.T) {
+	clock := newTestClock()
+	throttle := application.NewLoginThrottle(memory.NewLoginAttemptStore(), application.LoginThrottlePolicy{
+		FreeAttempts:   100,
+		IPFreeAttempts: 1,
+		BaseDelay:      time.Minute,
+		MaxDelay:       time.Hour,
+		Window:         time.Hour,
+	}, application.WithThrottleClock(clock.Now))
+	ctx := context.Background()
+
+	_, err := throttle.Reserve(ctx, "alice@example.com", "203.0.113.7")
+	require.NoError(t, err)
+	_, err = throttle.Reserve(ctx, "alice@example.com", "203.0.113.7")
+	require.NoError(t, err)
+	clock.Advance(30 * time.Second)
+	_, err = throttle.Reserve(ctx, "bob@example.com", "203.0.113.7")
+	requireThrottled(t, err, application.ErrTooManyAttempts, 30*time.Second)
+
+	clock.Advance(30 * time.Second)
+	reservation, err := throttle.Reserve(ctx, "bob@example.com", "203.0.113.7")
+	require.NoError(t, err, "the refused attempt did not extend the client's wait")
+	require.NoError(t, reservation.Succeed(ctx))
+}
+
+func TestLoginThrottleLocksAccount(t *testing.T) {
+	service, ctx := newThrottledService(t, application.LoginThrottlePolicy{
+		FreeAttempts:     100,
+		IPFreeAttempts:   100,
+		LockoutThreshold: 3,
+		LockoutDuration:  15 * time.Minute,
+		Window:           time.Hour,
+	})
+
+	for i := 0; i < 3; i++ {
+		_, err := service.Authenticate(ctx, "alice@example.com", "wrong-password")
+		require.ErrorIs(t, err, application.ErrInvalidCredentials)
+	}
+
+	_, err := service.Authenticate(ctx, "alice@example.com", "password123")
+	requireThrottled(t, err, application.ErrAccountLocked, 15*time.Minute)
+}
+
+func TestLoginThrottlePerClientIP(t *testing.T) {
+	service, ctx := newThrottledService(t, application.LoginThrottlePolicy{
+		FreeAttempts:   100,
+		IPFreeAttempts: 2,
+		BaseDelay:      time.Minute,
+		MaxDelay:       time.Hour,
+		Window:         time.Hour,
+	})
+	attacker := application.WithClientIP(ctx, "203.0.113.7")
+
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		_, err := service.Authenticate(attacker, email, "password123")
+		require.ErrorIs(t, err, application.ErrInvalidCredentials)
+	}
+
+	_, err := service.Authenticate(attacker, "alice@example.com", "password123")
+	requireThrottled(t, err, application.ErrTooManyAttempts, time.Minute)
+
+	_, err = service.Authenticate(application.WithClientIP(ctx, "198.51.100.1"), "alice@example.com", "password123")
+	require.NoError(t, err, "other clients are unaffected")
+}
+
+func TestLoginThrottleSuccessResetsAccount(t *testing.T) {
+	service, ctx := newThrottledService(t, application.LoginThrottlePolicy{
+		FreeAttempts:     100,
+		IPFreeAttempts:   100,
+		LockoutThreshold: 3,
+		LockoutDuration:  time.Minute,
+		Window:           time.Hour,
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err := service.Authenticate(ctx, "alice@example.com", "wrong-password")
+		require.ErrorIs(t, err, application.ErrInvalidCredentials)
+	}
+	_, err := service.Authenticate(ctx, "alice@example.com", "password123")
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err := service.Authenticate(ctx, "alice@example.com", "wrong-password")
+		require.ErrorIs(t, err, application.ErrInvalidCredentials)
+	}
+	_, err = service.Authenticate(ctx, "alice@example.com", "password123")
+	require.NoError(t, err, "failures before a successful login do not count towards lockout")
+}
+
+func TestLoginThrottleChangePassword(t *testing.T) {
+	throttle := application.NewLoginThrottle(memory.NewLoginAttemptStore(), application.LoginThrottlePolicy{
+		FreeAttempts:   2,
+		IPFreeAttempts: 100,
+		BaseDelay:      time.Minute,
+		MaxDelay:       time.Hour,
+		Window:         time.Hour,
+	})
+	f := newAccountFixture(t, application.WithLoginThrottle(throttle))
+	self := asUser(f.ctx, f.user.ID)
+
+	for i := 0; i < 2; i++ {
+		require.ErrorIs(t, f.users.ChangePassword(self, f.user.ID, "wrong-password", "newpassword"), application.ErrCurrentPasswordMismatch)
+	}
+	// The right current password is taken back, not counted.
+	require.ErrorIs(t, f.users.ChangePassword(self, f.user.ID, "password123", "short"), domain.ErrInvalidPassword)
+	require.ErrorIs(t, f.users.ChangePassword(self, f.user.ID, "wrong-password", "newpassword"), application.ErrCurrentPasswordMismatch)
+
+	requireThrottled(t, f.users.ChangePassword(self, f.user.ID, "password123", "newpassword"), application.ErrTooManyAttempts, time.Minute)
+	_, err := f.users.Authenticate(f.ctx, "jane@example.com", "password123")
+	requireThrottled(t, err, application.ErrTooManyAttempts, time.Minute)
+}
@@ -0,0 +1,15 @@
+package application
+
+import "context"
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional email.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
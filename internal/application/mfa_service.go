@@ -0,0 +1,339 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"backend-challenge/internal/domain"
+)
+
+const (
+	mfaSecretBytes    = 20
+	recoveryCodeCount = 10
+	recoveryCodeBytes = 5
+	// totpSkew is how many time steps either side of now a code is accepted
+	// for, to tolerate clock drift on the user's device.
+	totpSkew = 1
+)
+
+// MFAEnrollment is returned when a user starts TOTP enrollment.
+type MFAEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// MFAChallenge is handed out after a correct password when the account has
+// two-factor authentication enabled.
+type MFAChallenge struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// MFAService manages TOTP enrollment, recovery codes, and second-factor login.
+type MFAService struct {
+	users        UserRepository
+	tokens       OneTimeTokenRepository
+	issuer       string
+	challengeTTL time.Duration
+	now          func() time.Time
+	publisher    UserEventPublisher
+	throttle     *LoginThrottle
+}
+
+// MFAOption customises an MFAService.
+type MFAOption func(*MFAService)
+
+// WithMFAClock sets the clock TOTP codes and login challenges are checked
+// against. It defaults to time.Now.
+func WithMFAClock(now func() time.Time) MFAOption {
+	return func(s *MFAService) {
+		s.now = now
+	}
+}
+
+// WithMFAEventPublisher publishes an update whenever a user turns two-factor
+// authentication on or off. A nil publisher keeps the default, which discards
+// events.
+func WithMFAEventPublisher(publisher UserEventPublisher) MFAOption {
+	return func(s *MFAService) {
+		if publisher != nil {
+			s.publisher = publisher
+		}
+	}
+}
+
+// WithMFALoginThrottle clears the account's failed logins once Complete
+// accepts a code. It must be the throttle given to the UserService, which
+// keeps a correct password counted as a failure until the second factor is
+// checked.
+func WithMFALoginThrottle(throttle *LoginThrottle) MFAOption {
+	return func(s *MFAService) {
+		s.throttle = throttle
+	}
+}
+
+// NewMFAService constructs an MFA service. issuer labels the account in
+// authenticator apps; challengeTTL bounds how long a password-verified login
+// may wait for its second factor.
+func NewMFAService(users UserRepository, tokens OneTimeTokenRepository, issuer string, challengeTTL time.Duration, opts ...MFAOption) *MFAService {
+	s := &MFAService{
+		users:        users,
+		tokens:       tokens,
+		issuer:       issuer,
+		challengeTTL: challengeTTL,
+		now:          time.Now,
+		publisher:    noopPublisher{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Enroll generates a new TOTP secret for the user. It does not take effect
+// until Confirm is called with a code from it, and replaces any enrollment
+// that was never confirmed.
+func (s *MFAService) Enroll(ctx context.Context, userID string) (MFAEnrollment, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return MFAEnrollment{}, err
+	}
+	if user.MFA.Enabled() {
+		return MFAEnrollment{}, ErrMFAAlreadyEnabled
+	}
+
+	raw := make([]byte, mfaSecretBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return MFAEnrollment{}, err
+	}
+	secret := domain.TOTPEncoding.EncodeToString(raw)
+
+	if err := s.users.UpdateMFA(ctx, user.ID, domain.MFA{Secret: secret}); err != nil {
+		return MFAEnrollment{}, err
+	}
+	return MFAEnrollment{Secret: secret, URI: s.otpauthURI(user.Email, secret)}, nil
+}
+
+// Confirm activates a pending enrollment once the user proves their
+// authenticator works. It returns the recovery codes, which are only stored
+// hashed and cannot be shown again.
+func (s *MFAService) Confirm(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFA.Enabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if !user.MFA.Pending() {
+		return nil, ErrMFANotEnrolled
+	}
+
+	step, ok := matchTOTP(user.MFA.Secret, code, s.now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	err = s.users.UpdateMFA(ctx, user.ID, domain.MFA{
+		Secret:        user.MFA.Secret,
+		EnabledAt:     &now,
+		RecoveryCodes: hashes,
+		LastStep:      step,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publishUpdate(ctx, user.ID)
+	return codes, nil
+}
+
+// Disable turns two-factor authentication off. It takes a current TOTP code
+// or a recovery code so a stolen session alone cannot remove the second factor.
+func (s *MFAService) Disable(ctx context.Context, userID, code string) error {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.MFA.Enabled() {
+		return ErrMFANotEnrolled
+	}
+	if err := s.verifyCode(ctx, user, code); err != nil {
+		return err
+	}
+	if err := s.users.UpdateMFA(ctx, user.ID, domain.MFA{}); err != nil {
+		return err
+	}
+	s.publishUpdate(ctx, user.ID)
+	return nil
+}
+
+// publishUpdate reports the user as updated after its MFA state changed. The
+// change is already stored, so a failed lookup only skips the event.
+func (s *MFAService) publishUpdate(ctx context.Context, userID string) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return
+	}
+	s.publisher.Publish(ctx, domain.NewUserEvent(domain.UserUpdated, user))
+}
+
+// Challenge starts the second step of a login for a user whose password has
+// already been checked.
+func (s *MFAService) Challenge(ctx context.Context, user domain.User) (MFAChallenge, error) {
+	raw, err := randomToken()
+	if err != nil {
+		return MFAChallenge{}, err
+	}
+
+	now := s.now().UTC()
+	record := domain.OneTimeToken{
+		UserID:    user.ID,
+		Purpose:   domain.PurposeMFAChallenge,
+		TokenHash: hashToken(raw),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.challengeTTL),
+	}
+	if _, err := s.tokens.Create(ctx, record); err != nil {
+		return MFAChallenge{}, err
+	}
+	return MFAChallenge{Token: raw, ExpiresAt: record.ExpiresAt}, nil
+}
+
+// Complete redeems a challenge with a TOTP or recovery code and returns the
+// user to sign in. A challenge can be presented once; after a wrong code the
+// login has to start again from the password step, where the login throttle
+// counts the failure.
+func (s *MFAService) Complete(ctx context.Context, challenge, code string) (domain.User, error) {
+	if challenge == "" {
+		return domain.User{}, ErrInvalidMFAChallenge
+	}
+
+	redeemed, err := s.tokens.Consume(ctx, domain.PurposeMFAChallenge, hashToken(challenge), s.now().UTC())
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return domain.User{}, ErrInvalidMFAChallenge
+		}
+		return domain.User{}, err
+	}
+
+	user, err := s.users.GetByID(ctx, redeemed.UserID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return domain.User{}, ErrInvalidMFAChallenge
+		}
+		return domain.User{}, err
+	}
+	if !user.MFA.Enabled() {
+		return domain.User{}, ErrInvalidMFAChallenge
+	}
+	if err := s.verifyCode(ctx, user, code); err != nil {
+		return domain.User{}, err
+	}
+	if s.throttle != nil {
+		if err := s.throttle.Reset(ctx, user.Email); err != nil {
+			return domain.User{}, err
+		}
+	}
+	return user, nil
+}
+
+// verifyCode accepts a six-digit TOTP code or one of the user's recovery
+// codes, and marks whichever was used so it cannot be replayed.
+func (s *MFAService) verifyCode(ctx context.Context, user domain.User, code string) error {
+	code = strings.TrimSpace(code)
+
+	var err error
+	if isTOTPCode(code) {
+		step, ok := matchTOTP(user.MFA.Secret, code, s.now())
+		if !ok || step <= user.MFA.LastStep {
+			return ErrInvalidMFACode
+		}
+		err = s.users.UseTOTPStep(ctx, user.ID, step)
+	} else {
+		err = s.users.UseRecoveryCode(ctx, user.ID, hashToken(normalizeRecoveryCode(code)))
+	}
+
+	if errors.Is(err, ErrNotFound) {
+		return ErrInvalidMFACode
+	}
+	return err
+}
+
+func (s *MFAService) otpauthURI(account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", s.issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", "6")
+	query.Set("period", "30")
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + s.issuer + ":" + account,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// matchTOTP reports whether code is valid for the secret around now, and for
+// which time step.
+func matchTOTP(secret, code string, now time.Time) (int64, bool) {
+	current := domain.TOTPStep(now)
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		want, err := domain.TOTPCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != domain.TOTPDigits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRecoveryCodes returns recovery codes formatted for display alongside the
+// hashes to store.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		encoded := domain.TOTPEncoding.EncodeToString(raw)
+		codes = append(codes, encoded[:4]+"-"+encoded[4:])
+		hashes = append(hashes, hashToken(encoded))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
@@ -0,0 +1,189 @@
+package application_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+	"backend-challenge/internal/infrastructure/memory"
+
+	"github.com/stretchr/testify/require"
+)
+
+func totpAt(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+	code, err := domain.TOTPCode(secret, domain.TOTPStep(at))
+	require.NoError(t, err)
+	return code
+}
+
+// enable enrolls and confirms MFA, using the code from the previous time step
+// so tests can still present the current one.
+func (f accountFixture) enable(t *testing.T) (string, []string) {
+	t.Helper()
+	enrollment, err := f.mfa.Enroll(f.ctx, f.user.ID)
+	require.NoError(t, err)
+	codes, err := f.mfa.Confirm(f.ctx, f.user.ID, totpAt(t, enrollment.Secret, f.clock.Now().Add(-domain.TOTPPeriod)))
+	require.NoError(t, err)
+	return enrollment.Secret, codes
+}
+
+func TestMFAEnrollAndConfirm(t *testing.T) {
+	f := newAccountFixture(t)
+
+	_, err := f.mfa.Confirm(f.ctx, f.user.ID, "123456")
+	require.ErrorIs(t, err, application.ErrMFANotEnrolled)
+
+	enrollment, err := f.mfa.Enroll(f.ctx, f.user.ID)
+	require.NoError(t, err)
+	uri, err := url.Parse(enrollment.URI)
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", uri.Scheme)
+	require.Equal(t, "totp", uri.Host)
+	require.Equal(t, "/Backend:jane@example.com", uri.Path)
+	require.Equal(t, enrollment.Secret, uri.Query().Get("secret"))
+	require.Equal(t, "Backend", uri.Query().Get("issuer"))
+
+	stored, err := f.repo.GetByID(f.ctx, f.user.ID)
+	require.NoError(t, err)
+	require.True(t, stored.MFA.Pending())
+	require.False(t, stored.MFA.Enabled())
+
+	_, err = f.mfa.Confirm(f.ctx, f.user.ID, "000000x")
+	require.ErrorIs(t, err, application.ErrInvalidMFACode)
+
+	codes, err := f.mfa.Confirm(f.ctx, f.user.ID, totpAt(t, enrollment.Secret, f.clock.Now()))
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+
+	stored, err = f.repo.GetByID(f.ctx, f.user.ID)
+	require.NoError(t, err)
+	require.True(t, stored.MFA.Enabled())
+	require.Len(t, stored.MFA.RecoveryCodes, 10)
+	require.NotContains(t, stored.MFA.RecoveryCodes, codes[0])
+
+	_, err = f.mfa.Enroll(f.ctx, f.user.ID)
+	require.ErrorIs(t, err, application.ErrMFAAlreadyEnabled)
+}
+
+func TestMFALoginWithTOTP(t *testing.T) {
+	f := newAccountFixture(t)
+	secret, _ := f.enable(t)
+
+	challenge, err := f.mfa.Challenge(f.ctx, f.user)
+	require.NoError(t, err)
+	code := totpAt(t, secret, f.clock.Now())
+
+	user, err := f.mfa.Complete(f.ctx, challenge.Token, code)
+	require.NoError(t, err)
+	require.Equal(t, f.user.ID, user.ID)
+
+	_, err = f.mfa.Complete(f.ctx, challenge.Token, code)
+	require.ErrorIs(t, err, application.ErrInvalidMFAChallenge)
+
+	second, err := f.mfa.Challenge(f.ctx, f.user)
+	require.NoError(t, err)
+	_, err = f.mfa.Complete(f.ctx, second.Token, code)
+	require.ErrorIs(t, err, application.ErrInvalidMFACode, "a code must not be accepted twice")
+}
+
+func TestMFALoginWithRecoveryCode(t *testing.T) {
+	f := newAccountFixture(t)
+	_, codes := f.enable(t)
+
+	challenge, err := f.mfa.Challenge(f.ctx, f.user)
+	require.NoError(t, err)
+	_, err = f.mfa.Complete(f.ctx, challenge.Token, " "+codes[3]+" ")
+	require.NoError(t, err)
+
+	challenge, err = f.mfa.Challenge(f.ctx, f.user)
+	require.NoError(t, err)
+	_, err = f.mfa.Complete(f.ctx, challenge.Token, codes[3])
+	require.ErrorIs(t, err, application.ErrInvalidMFACode)
+
+	stored, err := f.repo.GetByID(f.ctx, f.user.ID)
+	require.NoError(t, err)
+	require.Len(t, stored.MFA.RecoveryCodes, 9)
+}
+
+func TestMFAChallengeExpires(t *testing.T) {
+	f := newAccountFixture(t)
+	secret, _ := f.enable(t)
+
+	challenge, err := f.mfa.Challenge(f.ctx, f.user)
+	require.NoError(t, err)
+	f.clock.Advance(time.Minute + time.Millisecond)
+
+	_, err = f.mfa.Complete(f.ctx, challenge.Token, totpAt(t, secret, f.clock.Now()))
+	require.ErrorIs(t, err, application.ErrInvalidMFAChallenge)
+}
+
+func TestMFADisable(t *testing.T) {
+	f := newAccountFixture(t)
+	secret, _ := f.enable(t)
+
+	require.ErrorIs(t, f.mfa.Disable(f.ctx, f.user.ID, "not-a-code"), application.ErrInvalidMFACode)
+	require.NoError(t, f.mfa.Disable(f.ctx, f.user.ID, totpAt(t, secret, f.clock.Now())))
+
+	stored, err := f.repo.GetByID(f.ctx, f.user.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.MFA{}, stored.MFA)
+	require.ErrorIs(t, f.mfa.Disable(f.ctx, f.user.ID, "123456"), application.ErrMFANotEnrolled)
+}
+
+func TestMFAPublishesUpdates(t *testing.T) {
+	f := newAccountFixture(t)
+	publisher := &recordingPublisher{}
+	f.mfa = application.NewMFAService(f.repo, memory.NewOneTimeTokenRepository(), "Backend", time.Minute,
+		application.WithMFAClock(f.clock.Now), application.WithMFAEventPublisher(publisher))
+
+	secret, _ := f.enable(t)
+	require.Len(t, publisher.events, 1)
+	require.Equal(t, domain.UserUpdated, publisher.events[0].Type)
+	require.True(t, publisher.events[0].User.Sanitize().MFAEnabled)
+
+	require.NoError(t, f.mfa.Disable(f.ctx, f.user.ID, totpAt(t, secret, f.clock.Now())))
+	require.Len(t, publisher.events, 2)
+	require.False(t, publisher.events[1].User.Sanitize().MFAEnabled)
+}
+
+func TestMFAWrongCodesAreThrottled(t *testing.T) {
+	clock := newTestClock()
+	throttle := application.NewLoginThrottle(memory.NewLoginAttemptStore(), application.LoginThrottlePolicy{
+		FreeAttempts:   2,
+		IPFreeAttempts: 100,
+		BaseDelay:      time.Minute,
+		MaxDelay:       time.Hour,
+		Window:         time.Hour,
+	}, application.WithThrottleClock(clock.Now))
+	f := newAccountFixture(t, application.WithLoginThrottle(throttle))
+	f.clock = clock
+	f.mfa = application.NewMFAService(f.repo, memory.NewOneTimeTokenRepository(), "Backend", time.Minute,
+		application.WithMFAClock(clock.Now), application.WithMFALoginThrottle(throttle))
+	secret, _ := f.enable(t)
+
+	login := func(code string) error {
+		user, err := f.users.Authenticate(f.ctx, "jane@example.com", "password123")
+		if err != nil {
+			return err
+		}
+		challenge, err := f.mfa.Challenge(f.ctx, user)
+		require.NoError(t, err)
+		_, err = f.mfa.Complete(f.ctx, challenge.Token, code)
+		return err
+	}
+
+	// The right password does not clear the failures a wrong code leaves.
+	for i := 0; i < 3; i++ {
+		require.ErrorIs(t, login("not-a-code"), application.ErrInvalidMFACode)
+	}
+	requireThrottled(t, login("not-a-code"), application.ErrTooManyAttempts, time.Minute)
+
+	clock.Advance(time.Minute)
+	require.NoError(t, login(totpAt(t, secret, clock.Now())))
+	for i := 0; i < 2; i++ {
+		require.ErrorIs(t, login("not-a-code"), application.ErrInvalidMFACode)
+	}
+}
@@ -0,0 +1,20 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"backend-challenge/internal/domain"
+)
+
+// OneTimeTokenRepository defines persistence operations for single-use tokens.
+type OneTimeTokenRepository interface {
+	Create(ctx context.Context, token domain.OneTimeToken) (domain.OneTimeToken, error)
+	// Consume atomically marks the unused, unexpired token with the given
+	// purpose and hash as used and returns it. It must fail with ErrNotFound
+	// when no such token exists, including when it was already consumed.
+	Consume(ctx context.Context, purpose domain.TokenPurpose, hash string, at time.Time) (domain.OneTimeToken, error)
+	DeleteByUser(ctx context.Context, userID string, purpose domain.TokenPurpose) error
+	// DeleteUser removes every token issued to the user, whatever its purpose.
+	DeleteUser(ctx context.Context, userID string) error
+}
@@ -0,0 +1,56 @@
+package application
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	generateFromPassword   = bcrypt.GenerateFromPassword
+	compareHashAndPassword = bcrypt.CompareHashAndPassword
+)
+
+// PasswordHasher hashes and verifies passwords. Hashes must be
+// self-describing, so that hashes written with an older algorithm or
+// parameters keep verifying after the configuration changes.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Verify fails with ErrPasswordMismatch when password does not match hash.
+	// On success it reports whether hash uses an outdated algorithm or
+	// parameters and should be replaced with a fresh Hash of the password.
+	Verify(hash, password string) (rehash bool, err error)
+}
+
+// defaultPasswordHasher is used by services that are not given a hasher.
+var defaultPasswordHasher PasswordHasher = BcryptHasher{Cost: bcrypt.DefaultCost}
+
+// BcryptHasher hashes with bcrypt at Cost. It is the default for services that
+// are not given a hasher, and the bcrypt scheme of the password package.
+type BcryptHasher struct {
+	Cost int
+}
+
+// Hash returns a bcrypt hash in the usual $2a$ modular crypt format.
+func (h BcryptHasher) Hash(password string) (string, error) {
+	hashed, err := generateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// Verify checks password and asks for a rehash when hash used another cost.
+func (h BcryptHasher) Verify(hash, password string) (bool, error) {
+	if err := compareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, ErrPasswordMismatch
+		}
+		return false, err
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false, err
+	}
+	return cost != h.Cost, nil
+}
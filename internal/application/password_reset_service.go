@@ -0,0 +1,167 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"backend-challenge/internal/domain"
+)
+
+// PasswordResetService issues and redeems password reset tokens.
+type PasswordResetService struct {
+	users    UserRepository
+	hasher   PasswordHasher
+	tokens   OneTimeTokenRepository
+	sessions *SessionService
+	mailer   Mailer
+	ttl      time.Duration
+	resetURL string
+	policy   domain.PasswordPolicy
+	now      func() time.Time
+}
+
+// PasswordResetOption customises a PasswordResetService.
+type PasswordResetOption func(*PasswordResetService)
+
+// WithResetPasswordPolicy sets the requirements for the new password. Without
+// it, domain.DefaultPasswordPolicy applies.
+func WithResetPasswordPolicy(policy domain.PasswordPolicy) PasswordResetOption {
+	return func(s *PasswordResetService) {
+		s.policy = policy
+	}
+}
+
+// WithResetClock sets the clock reset tokens are issued and checked against.
+// It defaults to time.Now.
+func WithResetClock(now func() time.Time) PasswordResetOption {
+	return func(s *PasswordResetService) {
+		s.now = now
+	}
+}
+
+// NewPasswordResetService constructs a password reset service. When resetURL
+// is set, emails link to it with the token in the "token" query parameter;
+// otherwise the raw token is sent. A nil hasher means bcrypt at the default
+// cost, matching NewUserService.
+func NewPasswordResetService(users UserRepository, hasher PasswordHasher, tokens OneTimeTokenRepository, sessions *SessionService, mailer Mailer, ttl time.Duration, resetURL string, opts ...PasswordResetOption) *PasswordResetService {
+	if hasher == nil {
+		hasher = defaultPasswordHasher
+	}
+	s := &PasswordResetService{
+		users:    users,
+		hasher:   hasher,
+		tokens:   tokens,
+		sessions: sessions,
+		mailer:   mailer,
+		ttl:      ttl,
+		resetURL: resetURL,
+		policy:   domain.DefaultPasswordPolicy,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Forgot emails a reset token to the account registered under email. Unknown
+// addresses succeed silently so callers cannot probe which accounts exist.
+// Issuing a token invalidates any token sent earlier.
+func (s *PasswordResetService) Forgot(ctx context.Context, email string) error {
+	email = strings.TrimSpace(strings.ToLower(email))
+	if err := domain.ValidateEmail(email); err != nil {
+		return err
+	}
+
+	user, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if err := s.tokens.DeleteByUser(ctx, user.ID, domain.PurposePasswordReset); err != nil {
+		return err
+	}
+
+	raw, err := randomToken()
+	if err != nil {
+		return err
+	}
+
+	now := s.now().UTC()
+	record := domain.OneTimeToken{
+		UserID:    user.ID,
+		Purpose:   domain.PurposePasswordReset,
+		TokenHash: hashToken(raw),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if _, err := s.tokens.Create(ctx, record); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    s.resetBody(raw),
+	})
+}
+
+// Reset redeems a reset token, sets the new password, and revokes every
+// session the user had open.
+func (s *PasswordResetService) Reset(ctx context.Context, token, password string) error {
+	if token == "" {
+		return ErrInvalidResetToken
+	}
+	// Validate before consuming so a weak password does not burn the token.
+	if err := s.policy.Validate(password); err != nil {
+		return err
+	}
+
+	redeemed, err := s.tokens.Consume(ctx, domain.PurposePasswordReset, hashToken(token), s.now().UTC())
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	hashed, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+	if err := s.users.UpdatePassword(ctx, redeemed.UserID, hashed, storedTime(s.now)); err != nil {
+		return err
+	}
+	if err := s.tokens.DeleteByUser(ctx, redeemed.UserID, domain.PurposePasswordReset); err != nil {
+		return err
+	}
+	return s.sessions.LogoutAll(ctx, redeemed.UserID)
+}
+
+func (s *PasswordResetService) resetBody(token string) string {
+	footer := fmt.Sprintf("This token expires in %s. If you did not ask for a reset, ignore this email.\n", s.ttl)
+	if s.resetURL == "" {
+		return "Use this token to reset your password:\n\n" + token + "\n\n" + footer
+	}
+	return "Follow this link to reset your password:\n\n" + withToken(s.resetURL, token) + "\n\n" + footer
+}
+
+// withToken appends the token as a query parameter, keeping any parameters
+// already present on the link.
+func withToken(link, token string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return link + "?token=" + url.QueryEscape(token)
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,90 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordResetFlow(t *testing.T) {
+	f := newAccountFixture(t)
+
+	pair, err := f.sessions.Issue(f.ctx, f.user)
+	require.NoError(t, err)
+	f.clock.Advance(time.Second)
+
+	require.NoError(t, f.resets.Forgot(f.ctx, " Jane@Example.com "))
+	msg := f.mailer.last(t)
+	require.Equal(t, "jane@example.com", msg.To)
+	require.Contains(t, msg.Body, "lang=en")
+	token := tokenFromLink(t, msg)
+
+	require.NoError(t, f.resets.Reset(f.ctx, token, "newpassword"))
+
+	_, err = f.users.Authenticate(f.ctx, "jane@example.com", "password123")
+	require.ErrorIs(t, err, application.ErrInvalidCredentials)
+	_, err = f.users.Authenticate(f.ctx, "jane@example.com", "newpassword")
+	require.NoError(t, err)
+
+	_, err = f.sessions.Verify(f.ctx, pair.AccessToken)
+	require.ErrorIs(t, err, application.ErrTokenRevoked)
+	_, err = f.sessions.Refresh(f.ctx, pair.RefreshToken)
+	require.ErrorIs(t, err, application.ErrInvalidRefreshToken)
+
+	err = f.resets.Reset(f.ctx, token, "anotherpassword")
+	require.ErrorIs(t, err, application.ErrInvalidResetToken)
+}
+
+func TestPasswordResetUnknownEmailIsSilent(t *testing.T) {
+	f := newAccountFixture(t)
+
+	require.NoError(t, f.resets.Forgot(f.ctx, "nobody@example.com"))
+	require.Empty(t, f.mailer.sent)
+
+	require.ErrorIs(t, f.resets.Forgot(f.ctx, "not-an-email"), domain.ErrInvalidEmail)
+}
+
+func TestPasswordResetExpiredToken(t *testing.T) {
+	f := newAccountFixture(t)
+
+	require.NoError(t, f.resets.Forgot(f.ctx, "jane@example.com"))
+	token := tokenFromLink(t, f.mailer.last(t))
+	f.clock.Advance(time.Hour + time.Millisecond)
+
+	err := f.resets.Reset(f.ctx, token, "newpassword")
+	require.ErrorIs(t, err, application.ErrInvalidResetToken)
+}
+
+func TestPasswordResetNewRequestInvalidatesOldToken(t *testing.T) {
+	f := newAccountFixture(t)
+
+	require.NoError(t, f.resets.Forgot(f.ctx, "jane@example.com"))
+	first := tokenFromLink(t, f.mailer.last(t))
+	require.NoError(t, f.resets.Forgot(f.ctx, "jane@example.com"))
+	second := tokenFromLink(t, f.mailer.last(t))
+
+	require.ErrorIs(t, f.resets.Reset(f.ctx, first, "newpassword"), application.ErrInvalidResetToken)
+	require.NoError(t, f.resets.Reset(f.ctx, second, "newpassword"))
+}
+
+func TestPasswordResetWeakPasswordKeepsToken(t *testing.T) {
+	f := newAccountFixture(t)
+
+	require.NoError(t, f.resets.Forgot(f.ctx, "jane@example.com"))
+	token := tokenFromLink(t, f.mailer.last(t))
+
+	require.ErrorIs(t, f.resets.Reset(f.ctx, token, "short"), domain.ErrInvalidPassword)
+	require.NoError(t, f.resets.Reset(f.ctx, token, "newpassword"))
+}
+
+func TestPasswordResetMailerFailure(t *testing.T) {
+	f := newAccountFixture(t)
+	f.mailer.err = errors.New("smtp down")
+
+	require.ErrorContains(t, f.resets.Forgot(f.ctx, "jane@example.com"), "smtp down")
+}
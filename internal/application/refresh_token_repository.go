@@ -0,0 +1,21 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"backend-challenge/internal/domain"
+)
+
+// RefreshTokenRepository defines persistence operations for refresh tokens.
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token domain.RefreshToken) (domain.RefreshToken, error)
+	GetByHash(ctx context.Context, hash string) (domain.RefreshToken, error)
+	// MarkRotated flags the token as used. It must fail with
+	// ErrRefreshTokenReused if the token was already rotated.
+	MarkRotated(ctx context.Context, id string, at time.Time) error
+	RevokeFamily(ctx context.Context, familyID string, at time.Time) error
+	RevokeUser(ctx context.Context, userID string, at time.Time) error
+	// DeleteUser removes every token issued to the user, revoked or not.
+	DeleteUser(ctx context.Context, userID string) error
+}
@@ -0,0 +1,280 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"backend-challenge/internal/domain"
+)
+
+const refreshTokenBytes = 32
+
+// TokenManager mints and verifies signed access tokens.
+type TokenManager interface {
+	GenerateToken(userID string, roles []domain.Role) (string, error)
+	VerifyToken(token string) (AccessClaims, error)
+}
+
+// AccessClaims are the verified claims of an access token.
+type AccessClaims struct {
+	UserID    string
+	Roles     []domain.Role
+	TokenID   string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// TokenPair bundles a short-lived access token with its refresh token.
+type TokenPair struct {
+	AccessToken      string
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// SessionService issues, rotates, verifies, and revokes tokens.
+type SessionService struct {
+	tokens      TokenManager
+	refresh     RefreshTokenRepository
+	revocations TokenRevocationStore
+	refreshTTL  time.Duration
+	users       UserRepository
+	now         func() time.Time
+}
+
+// SessionServiceOption customises a SessionService.
+type SessionServiceOption func(*SessionService)
+
+// WithPasswordChangeCheck makes the service look the token's user up and
+// reject access and refresh tokens issued before their last password change,
+// or belonging to a user that no longer exists. Refreshed access tokens then
+// also pick up the user's current roles; without it they carry none.
+func WithPasswordChangeCheck(users UserRepository) SessionServiceOption {
+	return func(s *SessionService) {
+		s.users = users
+	}
+}
+
+// WithSessionClock sets the clock refresh tokens and revocation cutoffs are
+// stamped with. It defaults to time.Now and should match the TokenManager's.
+func WithSessionClock(now func() time.Time) SessionServiceOption {
+	return func(s *SessionService) {
+		s.now = now
+	}
+}
+
+// NewSessionService constructs a session service.
+func NewSessionService(tokens TokenManager, refresh RefreshTokenRepository, revocations TokenRevocationStore, refreshTTL time.Duration, opts ...SessionServiceOption) *SessionService {
+	s := &SessionService{
+		tokens:      tokens,
+		refresh:     refresh,
+		revocations: revocations,
+		refreshTTL:  refreshTTL,
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Issue starts a new session for the user and returns its first token pair.
+// The access token carries the user's roles.
+func (s *SessionService) Issue(ctx context.Context, user domain.User) (TokenPair, error) {
+	familyID, err := randomToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return s.issue(ctx, user.ID, user.Roles, familyID)
+}
+
+// Refresh exchanges a refresh token for a new pair. The presented token is
+// rotated; presenting it again revokes every token in its family.
+func (s *SessionService) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
+	if refreshToken == "" {
+		return TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	stored, err := s.refresh.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return TokenPair{}, ErrInvalidRefreshToken
+		}
+		return TokenPair{}, err
+	}
+
+	now := s.now().UTC()
+	if stored.RevokedAt != nil || stored.Expired(now) {
+		return TokenPair{}, ErrInvalidRefreshToken
+	}
+	if stored.RotatedAt != nil {
+		return TokenPair{}, s.revokeReused(ctx, stored.FamilyID, now)
+	}
+	user, err := s.checkPasswordChange(ctx, stored.UserID, stored.CreatedAt)
+	if err != nil {
+		if errors.Is(err, ErrTokenRevoked) {
+			return TokenPair{}, ErrInvalidRefreshToken
+		}
+		return TokenPair{}, err
+	}
+
+	if err := s.refresh.MarkRotated(ctx, stored.ID, now); err != nil {
+		if errors.Is(err, ErrRefreshTokenReused) {
+			return TokenPair{}, s.revokeReused(ctx, stored.FamilyID, now)
+		}
+		return TokenPair{}, err
+	}
+
+	return s.issue(ctx, stored.UserID, user.Roles, stored.FamilyID)
+}
+
+// Verify checks an access token's signature, lifetime, and revocation state.
+func (s *SessionService) Verify(ctx context.Context, token string) (AccessClaims, error) {
+	claims, err := s.tokens.VerifyToken(token)
+	if err != nil {
+		return AccessClaims{}, ErrInvalidToken
+	}
+
+	if claims.TokenID != "" {
+		revoked, err := s.revocations.IsTokenRevoked(ctx, claims.TokenID)
+		if err != nil {
+			return AccessClaims{}, err
+		}
+		if revoked {
+			return AccessClaims{}, ErrTokenRevoked
+		}
+	}
+
+	cutoff, err := s.revocations.UserTokensRevokedBefore(ctx, claims.UserID)
+	if err != nil {
+		return AccessClaims{}, err
+	}
+	if !cutoff.IsZero() && claims.IssuedAt.Before(cutoff) {
+		return AccessClaims{}, ErrTokenRevoked
+	}
+
+	if _, err := s.checkPasswordChange(ctx, claims.UserID, claims.IssuedAt); err != nil {
+		return AccessClaims{}, err
+	}
+
+	return claims, nil
+}
+
+// checkPasswordChange fails with ErrTokenRevoked when a token issued at
+// issuedAt predates the user's last password change. Issue times carry
+// millisecond precision and may round down by one, so a token minted in the
+// same millisecond as the change is still accepted. It returns the user, or
+// the zero value when the service was built without a user repository.
+func (s *SessionService) checkPasswordChange(ctx context.Context, userID string, issuedAt time.Time) (domain.User, error) {
+	if s.users == nil {
+		return domain.User{}, nil
+	}
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return domain.User{}, ErrTokenRevoked
+		}
+		return domain.User{}, err
+	}
+	if user.PasswordChangedAt != nil && issuedAt.Add(time.Millisecond).Before(*user.PasswordChangedAt) {
+		return domain.User{}, ErrTokenRevoked
+	}
+	return user, nil
+}
+
+// Logout revokes the presented access token and, when given, the refresh
+// token family it belongs to.
+func (s *SessionService) Logout(ctx context.Context, claims AccessClaims, refreshToken string) error {
+	if claims.TokenID == "" {
+		return ErrInvalidToken
+	}
+	if err := s.revocations.RevokeToken(ctx, claims.TokenID, claims.ExpiresAt); err != nil {
+		return err
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+	stored, err := s.refresh.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if stored.UserID != claims.UserID {
+		return nil
+	}
+	return s.refresh.RevokeFamily(ctx, stored.FamilyID, s.now().UTC())
+}
+
+// LogoutAll revokes every access and refresh token issued to the user so far.
+// The cutoff is stored at millisecond precision, like the iat claim, so a
+// session started in the same millisecond is not revoked.
+func (s *SessionService) LogoutAll(ctx context.Context, userID string) error {
+	now := storedTime(s.now)
+	if err := s.revocations.RevokeUserTokens(ctx, userID, now); err != nil {
+		return err
+	}
+	return s.refresh.RevokeUser(ctx, userID, now)
+}
+
+// Forget deletes every refresh token issued to the user, for accounts that
+// are removed for good. Access tokens are left to expire.
+func (s *SessionService) Forget(ctx context.Context, userID string) error {
+	return s.refresh.DeleteUser(ctx, userID)
+}
+
+func (s *SessionService) issue(ctx context.Context, userID string, roles []domain.Role, familyID string) (TokenPair, error) {
+	access, err := s.tokens.GenerateToken(userID, roles)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	raw, err := randomToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	now := s.now().UTC()
+	record := domain.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(raw),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.refreshTTL),
+	}
+	if _, err := s.refresh.Create(ctx, record); err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		AccessToken:      access,
+		RefreshToken:     raw,
+		RefreshExpiresAt: record.ExpiresAt,
+	}, nil
+}
+
+func (s *SessionService) revokeReused(ctx context.Context, familyID string, now time.Time) error {
+	if err := s.refresh.RevokeFamily(ctx, familyID, now); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
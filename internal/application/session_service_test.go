@@ -0,0 +1,230 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+	jwtinfra "backend-challenge/internal/infrastructure/jwt"
+	"backend-challenge/internal/infrastructure/memory"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newSessionService(ttl time.Duration) (*application.SessionService, context.Context) {
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+	return application.NewSessionService(manager, memory.NewRefreshTokenRepository(), memory.NewTokenRevocationStore(), ttl), context.Background()
+}
+
+func TestSessionIssueAndRefresh(t *testing.T) {
+	sessions, ctx := newSessionService(time.Hour)
+
+	pair, err := sessions.Issue(ctx, domain.User{ID: "user-1"})
+	require.NoError(t, err)
+	require.NotEmpty(t, pair.AccessToken)
+	require.NotEmpty(t, pair.RefreshToken)
+
+	rotated, err := sessions.Refresh(ctx, pair.RefreshToken)
+	require.NoError(t, err)
+	require.NotEmpty(t, rotated.AccessToken)
+	require.NotEqual(t, pair.RefreshToken, rotated.RefreshToken)
+
+	_, err = sessions.Refresh(ctx, rotated.RefreshToken)
+	require.NoError(t, err)
+}
+
+func TestSessionRefreshReuseRevokesFamily(t *testing.T) {
+	sessions, ctx := newSessionService(time.Hour)
+
+	first, err := sessions.Issue(ctx, domain.User{ID: "user-1"})
+	require.NoError(t, err)
+	second, err := sessions.Refresh(ctx, first.RefreshToken)
+	require.NoError(t, err)
+
+	_, err = sessions.Refresh(ctx, first.RefreshToken)
+	require.ErrorIs(t, err, application.ErrRefreshTokenReused)
+
+	_, err = sessions.Refresh(ctx, second.RefreshToken)
+	require.ErrorIs(t, err, application.ErrInvalidRefreshToken)
+}
+
+func TestSessionRefreshReuseLeavesOtherFamilies(t *testing.T) {
+	sessions, ctx := newSessionService(time.Hour)
+
+	stolen, err := sessions.Issue(ctx, domain.User{ID: "user-1"})
+	require.NoError(t, err)
+	other, err := sessions.Issue(ctx, domain.User{ID: "user-1"})
+	require.NoError(t, err)
+
+	_, err = sessions.Refresh(ctx, stolen.RefreshToken)
+	require.NoError(t, err)
+	_, err = sessions.Refresh(ctx, stolen.RefreshToken)
+	require.ErrorIs(t, err, application.ErrRefreshTokenReused)
+
+	_, err = sessions.Refresh(ctx, other.RefreshToken)
+	require.NoError(t, err)
+}
+
+func TestSessionRefreshInvalid(t *testing.T) {
+	sessions, ctx := newSessionService(time.Hour)
+
+	_, err := sessions.Refresh(ctx, "")
+	require.ErrorIs(t, err, application.ErrInvalidRefreshToken)
+
+	_, err = sessions.Refresh(ctx, "unknown")
+	require.ErrorIs(t, err, application.ErrInvalidRefreshToken)
+
+	expired, ctx := newSessionService(-time.Second)
+	pair, err := expired.Issue(ctx, domain.User{ID: "user-1"})
+	require.NoError(t, err)
+
+	_, err = expired.Refresh(ctx, pair.RefreshToken)
+	require.ErrorIs(t, err, application.ErrInvalidRefreshToken)
+}
+
+func TestSessionVerify(t *testing.T) {
+	sessions, ctx := newSessionService(time.Hour)
+
+	pair, err := sessions.Issue(ctx, domain.User{ID: "user-1"})
+	require.NoError(t, err)
+
+	claims, err := sessions.Verify(ctx, pair.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, "user-1", claims.UserID)
+	require.NotEmpty(t, claims.TokenID)
+
+	_, err = sessions.Verify(ctx, "bad.token")
+	require.ErrorIs(t, err, application.ErrInvalidToken)
+}
+
+func TestSessionLogoutRevokesTokenAndFamily(t *testing.T) {
+	sessions, ctx := newSessionService(time.Hour)
+
+	pair, err := sessions.Issue(ctx, domain.User{ID: "user-1"})
+	require.NoError(t, err)
+	other, err := sessions.Issue(ctx, domain.User{ID: "user-1"})
+	require.NoError(t, err)
+
+	claims, err := sessions.Verify(ctx, pair.AccessToken)
+	require.NoError(t, err)
+	require.NoError(t, sessions.Logout(ctx, claims, pair.RefreshToken))
+
+	_, err = sessions.Verify(ctx, pair.AccessToken)
+	require.ErrorIs(t, err, application.ErrTokenRevoked)
+	_, err = sessions.Refresh(ctx, pair.RefreshToken)
+	require.ErrorIs(t, err, application.ErrInvalidRefreshToken)
+
+	_, err = sessions.Verify(ctx, other.AccessToken)
+	require.NoError(t, err)
+	_, err = sessions.Refresh(ctx, other.RefreshToken)
+	require.NoError(t, err)
+}
+
+func TestSessionLogoutAll(t *testing.T) {
+	clock := newTestClock()
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer", jwtinfra.WithClock(clock.Now))
+	sessions := application.NewSessionService(manager, memory.NewRefreshTokenRepository(), memory.NewTokenRevocationStore(), time.Hour, application.WithSessionClock(clock.Now))
+	ctx := context.Background()
+
+	first, err := sessions.Issue(ctx, domain.User{ID: "user-1"})
+	require.NoError(t, err)
+	second, err := sessions.Issue(ctx, domain.User{ID: "user-1"})
+	require.NoError(t, err)
+	bystander, err := sessions.Issue(ctx, domain.User{ID: "user-2"})
+	require.NoError(t, err)
+
+	clock.Advance(time.Second)
+	require.NoError(t, sessions.LogoutAll(ctx, "user-1"))
+
+	for _, pair := range []application.TokenPair{first, second} {
+		_, err = sessions.Verify(ctx, pair.AccessToken)
+		require.ErrorIs(t, err, application.ErrTokenRevoked)
+		_, err = sessions.Refresh(ctx, pair.RefreshToken)
+		require.ErrorIs(t, err, application.ErrInvalidRefreshToken)
+	}
+
+	_, err = sessions.Verify(ctx, bystander.AccessToken)
+	require.NoError(t, err)
+
+	clock.Advance(time.Second)
+	fresh, err := sessions.Issue(ctx, domain.User{ID: "user-1"})
+	require.NoError(t, err)
+	_, err = sessions.Verify(ctx, fresh.AccessToken)
+	require.NoError(t, err)
+}
+
+func TestSessionLogoutAllKeepsTokensIssuedInTheSameMillisecond(t *testing.T) {
+	clock := newTestClock()
+	clock.Advance(750 * time.Microsecond)
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer", jwtinfra.WithClock(clock.Now))
+	sessions := application.NewSessionService(manager, memory.NewRefreshTokenRepository(), memory.NewTokenRevocationStore(), time.Hour, application.WithSessionClock(clock.Now))
+	ctx := context.Background()
+
+	require.NoError(t, sessions.LogoutAll(ctx, "user-1"))
+	fresh, err := sessions.Issue(ctx, domain.User{ID: "user-1"})
+	require.NoError(t, err)
+	_, err = sessions.Verify(ctx, fresh.AccessToken)
+	require.NoError(t, err)
+}
+
+func TestSessionRejectsTokensIssuedBeforePasswordChange(t *testing.T) {
+	f := newAccountFixture(t)
+	ctx, users, sessions, user := f.ctx, f.users, f.sessions, f.user
+
+	before, err := sessions.Issue(ctx, user)
+	require.NoError(t, err)
+	_, err = sessions.Verify(ctx, before.AccessToken)
+	require.NoError(t, err)
+
+	f.clock.Advance(time.Second)
+	self := application.WithActor(ctx, application.Actor{UserID: user.ID})
+	require.ErrorIs(t, users.ChangePassword(self, user.ID, "wrongpassword", "newpassword"), application.ErrCurrentPasswordMismatch)
+	require.ErrorIs(t, users.ChangePassword(self, user.ID, "password123", "short"), domain.ErrInvalidPassword)
+	require.NoError(t, users.ChangePassword(self, user.ID, "password123", "newpassword"))
+
+	_, err = sessions.Verify(ctx, before.AccessToken)
+	require.ErrorIs(t, err, application.ErrTokenRevoked)
+	_, err = sessions.Refresh(ctx, before.RefreshToken)
+	require.ErrorIs(t, err, application.ErrInvalidRefreshToken)
+
+	after, err := sessions.Issue(ctx, user)
+	require.NoError(t, err)
+	_, err = sessions.Verify(ctx, after.AccessToken)
+	require.NoError(t, err)
+	_, err = sessions.Refresh(ctx, after.RefreshToken)
+	require.NoError(t, err)
+
+	_, err = users.Authenticate(ctx, "jane@example.com", "newpassword")
+	require.NoError(t, err)
+
+	require.NoError(t, users.Delete(self, user.ID))
+	_, err = sessions.Verify(ctx, after.AccessToken)
+	require.ErrorIs(t, err, application.ErrTokenRevoked)
+}
+
+func TestSessionRefreshPicksUpCurrentRoles(t *testing.T) {
+	repo := memory.NewUserRepository()
+	users := application.NewUserService(repo)
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+	sessions := application.NewSessionService(manager, memory.NewRefreshTokenRepository(), memory.NewTokenRevocationStore(), time.Hour, application.WithPasswordChangeCheck(repo))
+	ctx := context.Background()
+
+	user, err := users.Register(ctx, application.RegisterInput{Name: "Jane", Email: "jane@example.com", Password: "password123"})
+	require.NoError(t, err)
+	pair, err := sessions.Issue(ctx, user)
+	require.NoError(t, err)
+	claims, err := sessions.Verify(ctx, pair.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, []domain.Role{domain.RoleUser}, claims.Roles)
+
+	_, err = repo.MarkVerified(ctx, user.ID, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, users.PromoteAdmins(ctx, []string{"jane@example.com"}))
+	refreshed, err := sessions.Refresh(ctx, pair.RefreshToken)
+	require.NoError(t, err)
+	claims, err = sessions.Verify(ctx, refreshed.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, []domain.Role{domain.RoleUser, domain.RoleAdmin}, claims.Roles)
+}
@@ -0,0 +1,18 @@
+package application
+
+import (
+	"context"
+	"time"
+)
+
+// TokenRevocationStore records access tokens that must be rejected before
+// their natural expiry.
+type TokenRevocationStore interface {
+	// RevokeToken blocks a single token until expiresAt.
+	RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+	// RevokeUserTokens blocks every token issued to the user before the cutoff.
+	RevokeUserTokens(ctx context.Context, userID string, before time.Time) error
+	// UserTokensRevokedBefore returns the user's cutoff, or the zero time if none.
+	UserTokensRevokedBefore(ctx context.Context, userID string) (time.Time, error)
+}
@@ -0,0 +1,33 @@
+package application
+
+import (
+	"context"
+
+	"backend-challenge/internal/domain"
+)
+
+// UserChange is a user event together with the token that resumes a watch
+// right after it.
+type UserChange struct {
+	Event       domain.UserEvent
+	ResumeToken string
+}
+
+// UserEventPublisher is told about user changes once they have been stored.
+type UserEventPublisher interface {
+	Publish(ctx context.Context, event domain.UserEvent)
+}
+
+// UserEventSource delivers user changes to watchers.
+type UserEventSource interface {
+	// Watch calls fn with every change after resumeToken, or from now on when
+	// the token is empty, until ctx is done or fn returns an error. It fails
+	// with ErrInvalidResumeToken when the token is unknown or too old to
+	// resume from.
+	Watch(ctx context.Context, resumeToken string, fn func(UserChange) error) error
+}
+
+// noopPublisher is used by services that are not given a publisher.
+type noopPublisher struct{}
+
+func (noopPublisher) Publish(context.Context, domain.UserEvent) {}
@@ -0,0 +1,88 @@
+package application
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"backend-challenge/internal/domain"
+)
+
+const (
+	// DefaultPageSize is the page size used when a PageRequest has no Limit.
+	DefaultPageSize = 50
+	// MaxPageSize caps the page size; larger limits are reduced to it.
+	MaxPageSize = 100
+)
+
+// PageRequest asks for one page of a list. Cursor is the NextCursor of the
+// previous page, or empty for the first page.
+type PageRequest struct {
+	Limit  int
+	Cursor string
+}
+
+// ListInput selects which users List returns and in what order. A zero Sort
+// lists the newest users first. Fields, when set, restricts the users to the
+// fields the caller will read; see domain.UserListQuery.
+type ListInput struct {
+	Filter domain.UserFilter
+	Sort   domain.UserSort
+	Page   PageRequest
+	Fields []domain.UserField
+}
+
+// UserListPage is one page of users. NextCursor is empty on the last page.
+type UserListPage struct {
+	Users      []domain.User
+	NextCursor string
+}
+
+// userCursor is the JSON form of a domain.UserCursor. It records the sort it
+// was issued for, since a position means nothing in another order. Clients
+// treat the encoded cursor as opaque.
+type userCursor struct {
+	Sort      string    `json:"s"`
+	CreatedAt time.Time `json:"t"`
+	Key       string    `json:"k,omitempty"`
+	ID        string    `json:"id"`
+}
+
+func encodeUserCursor(sort domain.UserSort, c domain.UserCursor) string {
+	raw, _ := json.Marshal(userCursor{Sort: sort.String(), CreatedAt: c.CreatedAt, Key: c.Key, ID: c.ID})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeUserCursor(sort domain.UserSort, s string) (*domain.UserCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c userCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" || c.Sort != sort.String() {
+		return nil, ErrInvalidCursor
+	}
+	return &domain.UserCursor{CreatedAt: c.CreatedAt, Key: c.Key, ID: c.ID}, nil
+}
+
+// userListQuery validates input and turns it into a repository query.
+func userListQuery(input ListInput) (domain.UserListQuery, error) {
+	query := domain.UserListQuery{Filter: input.Filter, Sort: input.Sort, Limit: input.Page.Limit, Fields: input.Fields}
+	query.Sort = query.SortOrDefault()
+	switch {
+	case input.Page.Limit < 0:
+		return domain.UserListQuery{}, ErrInvalidPageSize
+	case input.Page.Limit == 0:
+		query.Limit = DefaultPageSize
+	case input.Page.Limit > MaxPageSize:
+		query.Limit = MaxPageSize
+	}
+	if input.Page.Cursor != "" {
+		after, err := decodeUserCursor(query.Sort, input.Page.Cursor)
+		if err != nil {
+			return domain.UserListQuery{}, err
+		}
+		query.After = after
+	}
+	return query, nil
+}
@@ -2,17 +2,56 @@ package application
 
 import (
 	"context"
+	"time"
 
 	"backend-challenge/internal/domain"
 )
 
-// UserRepository defines persistence operations for users.
+// UserRepository defines persistence operations for users. Deleted users are
+// kept until they are purged; apart from List with IncludeDeleted, Restore,
+// and Purge, every method treats them as if they did not exist. Emails only
+// need to be unique among users that are not deleted.
+//
+// Create stores users at version 1. Update, UpdateRoles, UpdateMFA, Delete,
+// Restore, and MarkVerified on an unverified user each increase the version
+// by one.
 type UserRepository interface {
 	Create(ctx context.Context, user domain.User) (domain.User, error)
 	GetByEmail(ctx context.Context, email string) (domain.User, error)
 	GetByID(ctx context.Context, id string) (domain.User, error)
-	List(ctx context.Context) ([]domain.User, error)
+	// List returns one page of users, newest first with ties broken by
+	// descending ID. A Limit of zero or less returns every user after the
+	// cursor. It must fail with ErrInvalidCursor when the cursor cannot be
+	// used by the store.
+	List(ctx context.Context, query domain.UserListQuery) (domain.UserPage, error)
+	// Update applies the update in one atomic step. Changing the email clears
+	// VerifiedAt. When IfVersion is set, it must fail with ErrVersionMismatch
+	// if the user exists at another version.
 	Update(ctx context.Context, id string, update domain.UpdateUser) (domain.User, error)
-	Delete(ctx context.Context, id string) error
+	UpdatePassword(ctx context.Context, id, passwordHash string, changedAt time.Time) error
+	// ReplacePasswordHash swaps oldHash for newHash without recording a
+	// password change. It must fail with ErrNotFound when the stored hash is no
+	// longer oldHash.
+	ReplacePasswordHash(ctx context.Context, id, oldHash, newHash string) error
+	UpdateRoles(ctx context.Context, id string, roles []domain.Role) error
+	MarkVerified(ctx context.Context, id string, at time.Time) (domain.User, error)
+	UpdateMFA(ctx context.Context, id string, mfa domain.MFA) error
+	// UseTOTPStep atomically records step as the user's last accepted TOTP
+	// step. It must fail with ErrNotFound when step is not newer than the one
+	// stored, so each code is accepted at most once.
+	UseTOTPStep(ctx context.Context, id string, step int64) error
+	// UseRecoveryCode atomically removes the recovery code hash. It must fail
+	// with ErrNotFound when the user has no such code.
+	UseRecoveryCode(ctx context.Context, id, codeHash string) error
+	// Delete marks the user deleted at the given time. It must fail with
+	// ErrNotFound when the user does not exist or is already deleted.
+	Delete(ctx context.Context, id string, at time.Time) error
+	// Restore undoes Delete. It must fail with ErrNotFound when the user is
+	// not deleted, and with ErrDuplicateEmail when another user has taken the
+	// email since.
+	Restore(ctx context.Context, id string) (domain.User, error)
+	// Purge permanently removes users deleted before the given time and
+	// returns their IDs.
+	Purge(ctx context.Context, deletedBefore time.Time) ([]string, error)
 	Count(ctx context.Context) (int64, error)
 }
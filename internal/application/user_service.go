@@ -7,23 +7,135 @@ import (
 	"time"
 
 	"backend-challenge/internal/domain"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
-var (
-	generateFromPassword   = bcrypt.GenerateFromPassword
-	compareHashAndPassword = bcrypt.CompareHashAndPassword
+// VerificationMode controls what accounts with an unconfirmed email may do.
+type VerificationMode string
+
+const (
+	// VerificationOptional places no restrictions on unverified accounts.
+	VerificationOptional VerificationMode = "optional"
+	// VerificationRequired refuses to sign in unverified accounts.
+	VerificationRequired VerificationMode = "required"
+	// VerificationLimited signs unverified accounts in but limits them to a
+	// reduced set of operations.
+	VerificationLimited VerificationMode = "limited"
 )
 
 // UserService coordinates user use-cases.
 type UserService struct {
-	repo UserRepository
+	repo         UserRepository
+	hasher       PasswordHasher
+	throttle     *LoginThrottle
+	policy       Policy
+	verification VerificationMode
+	passwords    domain.PasswordPolicy
+	publisher    UserEventPublisher
+	events       UserEventSource
+	sessions     *SessionService
+	oneTime      OneTimeTokenRepository
+	now          func() time.Time
+}
+
+// UserServiceOption customises a UserService.
+type UserServiceOption func(*UserService)
+
+// WithVerificationMode sets how accounts with an unconfirmed email are treated.
+func WithVerificationMode(mode VerificationMode) UserServiceOption {
+	return func(s *UserService) {
+		s.verification = mode
+	}
+}
+
+// WithPasswordHasher sets how passwords are hashed. Without it, bcrypt at the
+// default cost is used.
+func WithPasswordHasher(hasher PasswordHasher) UserServiceOption {
+	return func(s *UserService) {
+		s.hasher = hasher
+	}
+}
+
+// WithPasswordPolicy sets the requirements for new passwords. Without it,
+// domain.DefaultPasswordPolicy applies.
+func WithPasswordPolicy(policy domain.PasswordPolicy) UserServiceOption {
+	return func(s *UserService) {
+		s.passwords = policy
+	}
+}
+
+// WithLoginThrottle slows down and locks out repeated failed logins. Without
+// it, Authenticate allows unlimited attempts.
+func WithLoginThrottle(throttle *LoginThrottle) UserServiceOption {
+	return func(s *UserService) {
+		s.throttle = throttle
+	}
+}
+
+// WithPolicy replaces the default RolePolicy used to authorize actors.
+func WithPolicy(policy Policy) UserServiceOption {
+	return func(s *UserService) {
+		s.policy = policy
+	}
+}
+
+// WithEventPublisher publishes an event whenever a user is created, updated,
+// or deleted. A nil publisher keeps the default, which discards events.
+func WithEventPublisher(publisher UserEventPublisher) UserServiceOption {
+	return func(s *UserService) {
+		if publisher != nil {
+			s.publisher = publisher
+		}
+	}
+}
+
+// WithEventSource sets where Watch reads user changes from. Without it, Watch
+// fails with ErrEventsUnavailable.
+func WithEventSource(source UserEventSource) UserServiceOption {
+	return func(s *UserService) {
+		s.events = source
+	}
+}
+
+// WithSessions lets Delete revoke every session the user had open, so that
+// restoring the account does not bring them back. Without it, deleting leaves
+// sessions to the TokenManager's own checks.
+func WithSessions(sessions *SessionService) UserServiceOption {
+	return func(s *UserService) {
+		s.sessions = sessions
+	}
+}
+
+// WithOneTimeTokens lets PurgeDeleted remove the reset, verification, and MFA
+// tokens of purged users. Without it, they are left to expire.
+func WithOneTimeTokens(tokens OneTimeTokenRepository) UserServiceOption {
+	return func(s *UserService) {
+		s.oneTime = tokens
+	}
+}
+
+// WithClock sets the clock used for creation, password change, and deletion
+// times. It defaults to time.Now.
+func WithClock(now func() time.Time) UserServiceOption {
+	return func(s *UserService) {
+		s.now = now
+	}
 }
 
 // NewUserService constructs a service with the provided repository.
-func NewUserService(repo UserRepository) *UserService {
-	return &UserService{repo: repo}
+func NewUserService(repo UserRepository, opts ...UserServiceOption) *UserService {
+	s := &UserService{
+		repo:         repo,
+		hasher:       defaultPasswordHasher,
+		verification: VerificationOptional,
+		policy:       RolePolicy{},
+		passwords:    domain.DefaultPasswordPolicy,
+		publisher:    noopPublisher{},
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // RegisterInput captures new user fields.
@@ -33,10 +145,12 @@ type RegisterInput struct {
 	Password string
 }
 
-// UpdateInput wraps fields allowed to change.
+// UpdateInput wraps fields allowed to change. IfMatch, when set, is an ETag
+// the user must still have for the update to apply.
 type UpdateInput struct {
-	Name  *string
-	Email *string
+	Name    *string
+	Email   *string
+	IfMatch string
 }
 
 // Register creates a new user with hashed password.
@@ -44,7 +158,7 @@ func (s *UserService) Register(ctx context.Context, input RegisterInput) (domain
 	name := strings.TrimSpace(input.Name)
 	email := strings.TrimSpace(strings.ToLower(input.Email))
 
-	if err := domain.ValidateNewUser(name, email, input.Password); err != nil {
+	if err := domain.ValidateNewUser(name, email, input.Password, s.passwords); err != nil {
 		return domain.User{}, err
 	}
 
@@ -55,16 +169,17 @@ func (s *UserService) Register(ctx context.Context, input RegisterInput) (domain
 		return domain.User{}, err
 	}
 
-	hashed, err := generateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	hashed, err := s.hasher.Hash(input.Password)
 	if err != nil {
 		return domain.User{}, err
 	}
 
-	now := time.Now().UTC()
+	now := s.now().UTC()
 	user := domain.User{
 		Name:      name,
 		Email:     email,
-		Password:  string(hashed),
+		Password:  hashed,
+		Roles:     []domain.Role{domain.RoleUser},
 		CreatedAt: now,
 	}
 
@@ -75,64 +190,124 @@ func (s *UserService) Register(ctx context.Context, input RegisterInput) (domain
 		}
 		return domain.User{}, err
 	}
+	s.publisher.Publish(ctx, domain.NewUserEvent(domain.UserCreated, created))
 	return created, nil
 }
 
-// Authenticate verifies credentials and returns the user.
+// Authenticate verifies credentials and returns the user. A password stored
+// with an outdated algorithm or parameters is re-hashed on the way through.
+// With a login throttle configured, repeated failures for the email or for the
+// client IP recorded by WithClientIP are refused with a *ThrottleError.
 func (s *UserService) Authenticate(ctx context.Context, email, password string) (domain.User, error) {
 	email = strings.TrimSpace(strings.ToLower(email))
 	if err := domain.ValidateCredentials(email, password); err != nil {
 		return domain.User{}, ErrInvalidCredentials
 	}
 
-	user, err := s.repo.GetByEmail(ctx, email)
+	var reservation LoginReservation
+	if s.throttle != nil {
+		var err error
+		if reservation, err = s.throttle.Reserve(ctx, email, clientIPFromContext(ctx)); err != nil {
+			return domain.User{}, err
+		}
+	}
+
+	user, err := s.verifyPassword(ctx, email, password)
 	if err != nil {
-		if errors.Is(err, ErrNotFound) {
-			return domain.User{}, ErrInvalidCredentials
+		// Wrong credentials stay counted; anything else was not a guess.
+		if s.throttle != nil && !errors.Is(err, ErrInvalidCredentials) {
+			if releaseErr := reservation.Release(ctx); releaseErr != nil {
+				return domain.User{}, errors.Join(err, releaseErr)
+			}
 		}
 		return domain.User{}, err
 	}
+	if s.throttle != nil {
+		// With a second factor to come, the login has not succeeded yet;
+		// MFAService.Complete clears the account once the code is accepted.
+		settle := reservation.Succeed
+		if user.MFA.Enabled() {
+			settle = reservation.Hold
+		}
+		if err := settle(ctx); err != nil {
+			return domain.User{}, err
+		}
+	}
 
-	if err := compareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return domain.User{}, ErrInvalidCredentials
+	if !s.CanSignIn(user) {
+		return domain.User{}, ErrEmailNotVerified
 	}
 
 	return user, nil
 }
 
-// Get retrieves a user by ID.
+// CanSignIn reports whether the user may be issued tokens under the
+// configured verification mode.
+func (s *UserService) CanSignIn(user domain.User) bool {
+	return s.verification != VerificationRequired || user.Verified()
+}
+
+// Get retrieves a user by ID. Everyone but admins may only read their own
+// account. Like the other use cases below, it authorizes the actor recorded in
+// ctx with WithActor and fails with a *ForbiddenError.
 func (s *UserService) Get(ctx context.Context, id string) (domain.User, error) {
+	if err := s.authorize(ctx, ActionReadUser, id); err != nil {
+		return domain.User{}, err
+	}
 	return s.repo.GetByID(ctx, id)
 }
 
-// List returns all users.
-func (s *UserService) List(ctx context.Context) ([]domain.User, error) {
-	return s.repo.List(ctx)
+// List returns one page of the users matching input's filter, in its order.
+// Only admins may list.
+func (s *UserService) List(ctx context.Context, input ListInput) (UserListPage, error) {
+	if err := s.authorize(ctx, ActionListUsers, ""); err != nil {
+		return UserListPage{}, err
+	}
+	query, err := userListQuery(input)
+	if err != nil {
+		return UserListPage{}, err
+	}
+	result, err := s.repo.List(ctx, query)
+	if err != nil {
+		return UserListPage{}, err
+	}
+	out := UserListPage{Users: result.Users}
+	if result.Next != nil {
+		out.NextCursor = encodeUserCursor(query.Sort, *result.Next)
+	}
+	return out, nil
 }
 
-// Update modifies allowed user fields.
+// Update modifies allowed user fields. A new email address has to be
+// confirmed again, so changing it leaves the user unverified.
 func (s *UserService) Update(ctx context.Context, id string, input UpdateInput) (domain.User, error) {
-	update := domain.UpdateUser{}
+	if err := s.authorize(ctx, ActionUpdateUser, id); err != nil {
+		return domain.User{}, err
+	}
 
+	update := domain.UpdateUser{}
 	if input.Name != nil {
 		name := strings.TrimSpace(*input.Name)
-		if err := domain.ValidateName(name); err != nil {
-			return domain.User{}, err
-		}
 		update.Name = &name
 	}
-
 	if input.Email != nil {
 		email := strings.TrimSpace(strings.ToLower(*input.Email))
-		if err := domain.ValidateEmail(email); err != nil {
-			return domain.User{}, err
-		}
 		update.Email = &email
 	}
+	if err := domain.ValidateUpdate(update); err != nil {
+		return domain.User{}, err
+	}
 
 	if update.Name == nil && update.Email == nil {
 		return domain.User{}, ErrNoFieldsToUpdate
 	}
+	if input.IfMatch != "" {
+		version, ok := parseETag(input.IfMatch)
+		if !ok {
+			return domain.User{}, ErrVersionMismatch
+		}
+		update.IfVersion = &version
+	}
 
 	updated, err := s.repo.Update(ctx, id, update)
 	if err != nil {
@@ -141,15 +316,231 @@ func (s *UserService) Update(ctx context.Context, id string, input UpdateInput)
 		}
 		return domain.User{}, err
 	}
+	s.publisher.Publish(ctx, domain.NewUserEvent(domain.UserUpdated, updated))
 	return updated, nil
 }
 
-// Delete removes a user by ID.
+// ChangePassword replaces the user's password after checking the current one.
+// Only the account owner may do so. Access tokens issued before the change
+// stop being accepted. With a login throttle configured, a wrong current
+// password counts as a failed login for the account, so a stolen session
+// cannot be used to guess it.
+func (s *UserService) ChangePassword(ctx context.Context, id, currentPassword, newPassword string) error {
+	if err := s.authorize(ctx, ActionChangePassword, id); err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var reservation LoginReservation
+	if s.throttle != nil {
+		if reservation, err = s.throttle.Reserve(ctx, user.Email, ""); err != nil {
+			return err
+		}
+	}
+	if _, err := s.hasher.Verify(user.Password, currentPassword); err != nil {
+		return ErrCurrentPasswordMismatch
+	}
+	// The right password is not a login, so it takes the attempt back rather
+	// than clearing the account's failures.
+	if s.throttle != nil {
+		if err := reservation.Release(ctx); err != nil {
+			return err
+		}
+	}
+	if err := s.passwords.Validate(newPassword); err != nil {
+		return err
+	}
+
+	hashed, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+	return s.repo.UpdatePassword(ctx, id, hashed, storedTime(s.now))
+}
+
+// Delete deletes a user by ID. The account disappears from every read but is
+// kept, and can be restored, until PurgeDeleted removes it. Its sessions are
+// revoked for good, before the deletion: a failure then leaves the user in
+// place to delete again, rather than deleted with sessions still open.
 func (s *UserService) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+	if err := s.authorize(ctx, ActionDeleteUser, id); err != nil {
+		return err
+	}
+	if s.sessions != nil {
+		if err := s.sessions.LogoutAll(ctx, id); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.Delete(ctx, id, storedTime(s.now)); err != nil {
+		return err
+	}
+	s.publisher.Publish(ctx, domain.NewUserEvent(domain.UserDeleted, domain.User{ID: id}))
+	return nil
+}
+
+// Restore brings back a deleted user that has not been purged yet. It fails
+// with ErrDuplicateEmail when another account has taken the email since. Only
+// admins may restore. Watchers see the restored user as created again.
+func (s *UserService) Restore(ctx context.Context, id string) (domain.User, error) {
+	if err := s.authorize(ctx, ActionRestoreUser, id); err != nil {
+		return domain.User{}, err
+	}
+	user, err := s.repo.Restore(ctx, id)
+	if err != nil {
+		return domain.User{}, err
+	}
+	s.publisher.Publish(ctx, domain.NewUserEvent(domain.UserCreated, user))
+	return user, nil
+}
+
+// PurgeDeleted permanently removes users deleted more than retention ago,
+// along with their refresh and one-time tokens, and returns how many users it
+// removed. It is meant for a background job.
+func (s *UserService) PurgeDeleted(ctx context.Context, retention time.Duration) (int64, error) {
+	ids, err := s.repo.Purge(ctx, storedTime(s.now).Add(-retention))
+	purged := int64(len(ids))
+	if err != nil {
+		return purged, err
+	}
+	for _, id := range ids {
+		if s.sessions != nil {
+			if err := s.sessions.Forget(ctx, id); err != nil {
+				return purged, err
+			}
+		}
+		if s.oneTime != nil {
+			if err := s.oneTime.DeleteUser(ctx, id); err != nil {
+				return purged, err
+			}
+		}
+	}
+	return purged, nil
+}
+
+// Watch calls fn with every user created, updated, or deleted after
+// resumeToken, or from now on when it is empty, until ctx is done or fn
+// fails. Each change carries the token to resume after it. Only admins may
+// watch.
+func (s *UserService) Watch(ctx context.Context, resumeToken string, fn func(UserChange) error) error {
+	if err := s.authorize(ctx, ActionWatchUsers, ""); err != nil {
+		return err
+	}
+	if s.events == nil {
+		return ErrEventsUnavailable
+	}
+	return s.events.Watch(ctx, resumeToken, fn)
+}
+
+// PromoteAdmins grants the admin role to the accounts with the given emails.
+// It is meant for bootstrapping the first admins at startup; emails without
+// an account, or whose account has not confirmed its current email, are
+// skipped so that registering or switching to an unclaimed address cannot
+// yield admin. Changing the email clears verification, so Verified always
+// refers to the address being matched. It only ever adds
+// the role: dropping an email from the list does not demote that account.
+func (s *UserService) PromoteAdmins(ctx context.Context, emails []string) error {
+	for _, email := range emails {
+		user, err := s.repo.GetByEmail(ctx, strings.TrimSpace(strings.ToLower(email)))
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return err
+		}
+		if user.HasRole(domain.RoleAdmin) || !user.Verified() {
+			continue
+		}
+		roles := append([]domain.Role{}, user.Roles...)
+		user.Roles = append(roles, domain.RoleAdmin)
+		if err := s.repo.UpdateRoles(ctx, user.ID, user.Roles); err != nil {
+			return err
+		}
+		s.publisher.Publish(ctx, domain.NewUserEvent(domain.UserUpdated, user))
+	}
+	return nil
 }
 
 // Count returns total user count.
 func (s *UserService) Count(ctx context.Context) (int64, error) {
 	return s.repo.Count(ctx)
 }
+
+// authorize checks the actor recorded in ctx against the policy. Calls without
+// an actor are refused. Listing, updating, deleting, and restoring users also
+// require the actor's email to be confirmed unless verification is optional.
+func (s *UserService) authorize(ctx context.Context, action Action, targetID string) error {
+	actor, _ := ActorFromContext(ctx)
+	if err := s.policy.Authorize(actor, action, targetID); err != nil {
+		return err
+	}
+	switch action {
+	case ActionListUsers, ActionUpdateUser, ActionDeleteUser, ActionRestoreUser:
+		return s.requireVerified(ctx, actor.UserID, action)
+	}
+	return nil
+}
+
+// requireVerified fails with ErrEmailNotVerified when the verification mode
+// restricts unverified accounts and the actor has not confirmed their email.
+func (s *UserService) requireVerified(ctx context.Context, actorID string, action Action) error {
+	if s.verification == VerificationOptional {
+		return nil
+	}
+	actor, err := s.repo.GetByID(ctx, actorID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return &ForbiddenError{Action: action}
+		}
+		return err
+	}
+	if !actor.Verified() {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// storedTime returns the clock's current time truncated to the millisecond
+// precision every repository can store, for timestamps that are compared
+// later.
+func storedTime(now func() time.Time) time.Time {
+	return now().UTC().Truncate(time.Millisecond)
+}
+
+func (s *UserService) verifyPassword(ctx context.Context, email, password string) (domain.User, error) {
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return domain.User{}, ErrInvalidCredentials
+		}
+		return domain.User{}, err
+	}
+
+	rehash, err := s.hasher.Verify(user.Password, password)
+	if err != nil {
+		return domain.User{}, ErrInvalidCredentials
+	}
+	if rehash {
+		user = s.upgradeHash(ctx, user, password)
+	}
+	return user, nil
+}
+
+// upgradeHash replaces the user's password hash with one from the current
+// hasher. It is best effort: the password was already verified, so a failure
+// here must not fail the login, and the swap is skipped if the password
+// changed in the meantime.
+func (s *UserService) upgradeHash(ctx context.Context, user domain.User, password string) domain.User {
+	hashed, err := s.hasher.Hash(password)
+	if err != nil {
+		return user
+	}
+	if err := s.repo.ReplacePasswordHash(ctx, user.ID, user.Password, hashed); err != nil {
+		return user
+	}
+	user.Password = hashed
+	return user
+}
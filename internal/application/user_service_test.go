@@ -3,15 +3,29 @@ package application_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"backend-challenge/internal/application"
 	"backend-challenge/internal/domain"
+	jwtinfra "backend-challenge/internal/infrastructure/jwt"
 	"backend-challenge/internal/infrastructure/memory"
 
 	"github.com/stretchr/testify/require"
 )
 
+// asAdmin runs a use case on behalf of an admin.
+func asAdmin(ctx context.Context) context.Context {
+	return application.WithActor(ctx, application.Actor{UserID: "admin", Roles: []domain.Role{domain.RoleUser, domain.RoleAdmin}})
+}
+
+// asUser runs a use case on behalf of the regular user with the given ID.
+func asUser(ctx context.Context, id string) context.Context {
+	return application.WithActor(ctx, application.Actor{UserID: id, Roles: []domain.Role{domain.RoleUser}})
+}
+
 func newService() (*application.UserService, context.Context) {
 	repo := memory.NewUserRepository()
 	return application.NewUserService(repo), context.Background()
@@ -91,17 +105,178 @@ func TestUpdateAndDelete(t *testing.T) {
 	require.NoError(t, err)
 
 	newName := "Alice Smith"
-	updated, err := service.Update(ctx, user.ID, application.UpdateInput{Name: &newName})
+	updated, err := service.Update(asUser(ctx, user.ID), user.ID, application.UpdateInput{Name: &newName})
 	require.NoError(t, err)
 	require.Equal(t, newName, updated.Name)
 
-	err = service.Delete(ctx, user.ID)
+	err = service.Delete(asUser(ctx, user.ID), user.ID)
 	require.NoError(t, err)
 
-	_, err = service.Get(ctx, user.ID)
+	_, err = service.Get(asAdmin(ctx), user.ID)
 	require.ErrorIs(t, err, application.ErrNotFound)
 }
 
+func TestDeleteIsSoftUntilPurged(t *testing.T) {
+	service, ctx := newService()
+
+	user, err := service.Register(ctx, application.RegisterInput{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	require.NoError(t, service.Delete(asUser(ctx, user.ID), user.ID))
+
+	_, err = service.Authenticate(ctx, "alice@example.com", "password123")
+	require.ErrorIs(t, err, application.ErrInvalidCredentials)
+	count, err := service.Count(ctx)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	_, err = service.Restore(asUser(ctx, user.ID), user.ID)
+	require.ErrorIs(t, err, application.ErrForbidden)
+	restored, err := service.Restore(asAdmin(ctx), user.ID)
+	require.NoError(t, err)
+	require.Nil(t, restored.DeletedAt)
+	_, err = service.Authenticate(ctx, "alice@example.com", "password123")
+	require.NoError(t, err)
+
+	require.NoError(t, service.Delete(asAdmin(ctx), user.ID))
+	purged, err := service.PurgeDeleted(ctx, time.Hour)
+	require.NoError(t, err)
+	require.Zero(t, purged)
+	purged, err = service.PurgeDeleted(ctx, -time.Second)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, purged)
+	_, err = service.Restore(asAdmin(ctx), user.ID)
+	require.ErrorIs(t, err, application.ErrNotFound)
+}
+
+func TestDeleteRevokesSessions(t *testing.T) {
+	f := newAccountFixture(t)
+
+	pair, err := f.sessions.Issue(f.ctx, f.user)
+	require.NoError(t, err)
+	f.clock.Advance(time.Second)
+	require.NoError(t, f.users.Delete(asUser(f.ctx, f.user.ID), f.user.ID))
+	_, err = f.users.Restore(asAdmin(f.ctx), f.user.ID)
+	require.NoError(t, err)
+
+	_, err = f.sessions.Verify(f.ctx, pair.AccessToken)
+	require.ErrorIs(t, err, application.ErrTokenRevoked)
+	_, err = f.sessions.Refresh(f.ctx, pair.RefreshToken)
+	require.ErrorIs(t, err, application.ErrInvalidRefreshToken)
+
+	f.clock.Advance(time.Second)
+	fresh, err := f.sessions.Issue(f.ctx, f.user)
+	require.NoError(t, err)
+	_, err = f.sessions.Verify(f.ctx, fresh.AccessToken)
+	require.NoError(t, err)
+}
+
+// failingUserRevocations fails to revoke a user's tokens.
+type failingUserRevocations struct {
+	application.TokenRevocationStore
+}
+
+func (failingUserRevocations) RevokeUserTokens(context.Context, string, time.Time) error {
+	return errors.New("revocation store unavailable")
+}
+
+func TestDeleteKeepsUserWhenRevokingSessionsFails(t *testing.T) {
+	publisher := &recordingPublisher{}
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+	sessions := application.NewSessionService(manager, memory.NewRefreshTokenRepository(), failingUserRevocations{memory.NewTokenRevocationStore()}, time.Hour)
+	service := application.NewUserService(memory.NewUserRepository(), application.WithSessions(sessions), application.WithEventPublisher(publisher))
+	ctx := context.Background()
+
+	user, err := service.Register(ctx, application.RegisterInput{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	require.Error(t, service.Delete(asUser(ctx, user.ID), user.ID))
+
+	_, err = service.Get(asAdmin(ctx), user.ID)
+	require.NoError(t, err)
+	for _, event := range publisher.events {
+		require.NotEqual(t, domain.UserDeleted, event.Type)
+	}
+}
+
+// userTokenDeletions records whose refresh and one-time tokens were deleted.
+type userTokenDeletions struct {
+	refresh []string
+	oneTime []string
+}
+
+type recordedRefreshTokens struct {
+	application.RefreshTokenRepository
+	deletions *userTokenDeletions
+}
+
+func (r recordedRefreshTokens) DeleteUser(ctx context.Context, userID string) error {
+	r.deletions.refresh = append(r.deletions.refresh, userID)
+	return r.RefreshTokenRepository.DeleteUser(ctx, userID)
+}
+
+type recordedOneTimeTokens struct {
+	application.OneTimeTokenRepository
+	deletions *userTokenDeletions
+}
+
+func (r recordedOneTimeTokens) DeleteUser(ctx context.Context, userID string) error {
+	r.deletions.oneTime = append(r.deletions.oneTime, userID)
+	return r.OneTimeTokenRepository.DeleteUser(ctx, userID)
+}
+
+func TestPurgeDeletedRemovesTokens(t *testing.T) {
+	deletions := &userTokenDeletions{}
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+	sessions := application.NewSessionService(manager, recordedRefreshTokens{memory.NewRefreshTokenRepository(), deletions}, memory.NewTokenRevocationStore(), time.Hour)
+	service := application.NewUserService(memory.NewUserRepository(),
+		application.WithSessions(sessions),
+		application.WithOneTimeTokens(recordedOneTimeTokens{memory.NewOneTimeTokenRepository(), deletions}))
+	ctx := context.Background()
+
+	_, err := service.Register(ctx, application.RegisterInput{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	purged, err := service.Register(ctx, application.RegisterInput{Name: "Bob", Email: "bob@example.com", Password: "password123"})
+	require.NoError(t, err)
+	require.NoError(t, service.Delete(asUser(ctx, purged.ID), purged.ID))
+
+	count, err := service.PurgeDeleted(ctx, -time.Second)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, count)
+	require.Equal(t, []string{purged.ID}, deletions.refresh)
+	require.Equal(t, []string{purged.ID}, deletions.oneTime)
+}
+
+func TestUpdateIfMatch(t *testing.T) {
+	service, ctx := newService()
+
+	user, err := service.Register(ctx, application.RegisterInput{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	read, err := service.Get(asAdmin(ctx), user.ID)
+	require.NoError(t, err)
+	etag := application.ETag(read)
+	require.Equal(t, `"1"`, etag)
+
+	updated, err := service.Update(asAdmin(ctx), user.ID, application.UpdateInput{Name: strPtr("Alicia"), IfMatch: etag})
+	require.NoError(t, err)
+	require.Equal(t, `"2"`, application.ETag(updated))
+
+	_, err = service.Update(asUser(ctx, user.ID), user.ID, application.UpdateInput{Name: strPtr("Ally"), IfMatch: etag})
+	require.ErrorIs(t, err, application.ErrVersionMismatch)
+	for _, tag := range []string{"2", `W/"2"`, `"two"`, `"-1"`, `"2;password"`, `"2;"`} {
+		_, err = service.Update(asUser(ctx, user.ID), user.ID, application.UpdateInput{Name: strPtr("Ally"), IfMatch: tag})
+		require.ErrorIs(t, err, application.ErrVersionMismatch, tag)
+	}
+
+	current, err := service.Get(asAdmin(ctx), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Alicia", current.Name)
+
+	projected := application.ProjectedETag(current, []domain.UserField{domain.FieldName, domain.FieldEmail, domain.FieldName})
+	require.Equal(t, `"2;email.name"`, projected)
+	require.True(t, application.ETagMatches(current, projected))
+	_, err = service.Update(asUser(ctx, user.ID), user.ID, application.UpdateInput{Name: strPtr("Ally"), IfMatch: projected})
+	require.NoError(t, err)
+}
+
 func TestUpdateDuplicateEmail(t *testing.T) {
 	service, ctx := newService()
 
@@ -118,7 +293,7 @@ func TestUpdateDuplicateEmail(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	_, err = service.Update(ctx, u1.ID, application.UpdateInput{Email: strPtr("beta@example.com")})
+	_, err = service.Update(asUser(ctx, u1.ID), u1.ID, application.UpdateInput{Email: strPtr("beta@example.com")})
 	require.ErrorIs(t, err, application.ErrDuplicateEmail)
 }
 
@@ -171,6 +346,66 @@ func TestRegisterHashFailure(t *testing.T) {
 	require.Error(t, err)
 }
 
+// versionedHasher writes "v2:" hashes and still accepts "v1:" ones, asking for
+// those to be upgraded.
+type versionedHasher struct{}
+
+func (versionedHasher) Hash(password string) (string, error) {
+	return "v2:" + password, nil
+}
+
+func (versionedHasher) Verify(hash, password string) (bool, error) {
+	switch hash {
+	case "v2:" + password:
+		return false, nil
+	case "v1:" + password:
+		return true, nil
+	}
+	return false, application.ErrPasswordMismatch
+}
+
+func TestAuthenticateUpgradesOutdatedHash(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewUserRepository()
+	legacy, err := repo.Create(ctx, domain.User{Name: "Legacy", Email: "legacy@example.com", Password: "v1:password123"})
+	require.NoError(t, err)
+	service := application.NewUserService(repo, application.WithPasswordHasher(versionedHasher{}))
+
+	user, err := service.Authenticate(ctx, "legacy@example.com", "password123")
+	require.NoError(t, err)
+	require.Equal(t, "v2:password123", user.Password)
+
+	stored, err := repo.GetByID(ctx, legacy.ID)
+	require.NoError(t, err)
+	require.Equal(t, "v2:password123", stored.Password)
+
+	_, err = service.Authenticate(ctx, "legacy@example.com", "wrong-password")
+	require.ErrorIs(t, err, application.ErrInvalidCredentials)
+}
+
+func TestAuthenticateUpgradeFailureDoesNotFailLogin(t *testing.T) {
+	ctx := context.Background()
+	var replaced bool
+	repo := &stubRepo{
+		getByEmail: func(context.Context, string) (domain.User, error) {
+			return domain.User{ID: "1", Email: "user@example.com", Password: "v1:password123"}, nil
+		},
+		rehashFn: func(_ context.Context, id, oldHash, newHash string) error {
+			replaced = true
+			require.Equal(t, "1", id)
+			require.Equal(t, "v1:password123", oldHash)
+			require.Equal(t, "v2:password123", newHash)
+			return application.ErrNotFound
+		},
+	}
+	service := application.NewUserService(repo, application.WithPasswordHasher(versionedHasher{}))
+
+	user, err := service.Authenticate(ctx, "user@example.com", "password123")
+	require.NoError(t, err)
+	require.True(t, replaced)
+	require.Equal(t, "v1:password123", user.Password, "a lost race keeps the stored hash")
+}
+
 func TestAuthenticateRepositoryErrors(t *testing.T) {
 	ctx := context.Background()
 	unexpectedErr := errors.New("repo error")
@@ -200,8 +435,8 @@ func TestAuthenticateInvalidInputsAndNotFound(t *testing.T) {
 func TestListAndCount(t *testing.T) {
 	ctx := context.Background()
 	repo := &stubRepo{
-		listFn: func(context.Context) ([]domain.User, error) {
-			return []domain.User{{ID: "1"}}, nil
+		listFn: func(context.Context, domain.UserListQuery) (domain.UserPage, error) {
+			return domain.UserPage{Users: []domain.User{{ID: "1"}}}, nil
 		},
 		countFn: func(context.Context) (int64, error) {
 			return 42, nil
@@ -209,21 +444,87 @@ func TestListAndCount(t *testing.T) {
 	}
 	service := application.NewUserService(repo)
 
-	users, err := service.List(ctx)
+	page, err := service.List(asAdmin(ctx), application.ListInput{})
 	require.NoError(t, err)
-	require.Len(t, users, 1)
+	require.Len(t, page.Users, 1)
 
 	count, err := service.Count(ctx)
 	require.NoError(t, err)
 	require.Equal(t, int64(42), count)
 }
 
+func TestListPages(t *testing.T) {
+	service, ctx := newService()
+	for i := 0; i < 3; i++ {
+		_, err := service.Register(ctx, application.RegisterInput{Name: "User", Email: fmt.Sprintf("user%d@example.com", i), Password: "password123"})
+		require.NoError(t, err)
+	}
+	admin := asAdmin(ctx)
+
+	first, err := service.List(admin, application.ListInput{Page: application.PageRequest{Limit: 2}})
+	require.NoError(t, err)
+	require.Len(t, first.Users, 2)
+	require.NotEmpty(t, first.NextCursor)
+
+	second, err := service.List(admin, application.ListInput{Page: application.PageRequest{Limit: 2, Cursor: first.NextCursor}})
+	require.NoError(t, err)
+	require.Len(t, second.Users, 1)
+	require.Empty(t, second.NextCursor)
+	require.NotContains(t, []string{first.Users[0].ID, first.Users[1].ID}, second.Users[0].ID)
+
+	_, err = service.List(admin, application.ListInput{Page: application.PageRequest{Cursor: "not-a-cursor"}})
+	require.ErrorIs(t, err, application.ErrInvalidCursor)
+	_, err = service.List(admin, application.ListInput{Page: application.PageRequest{Limit: -1}})
+	require.ErrorIs(t, err, application.ErrInvalidPageSize)
+}
+
+func TestListCursorIsTiedToItsSort(t *testing.T) {
+	service, ctx := newService()
+	for _, name := range []string{"Carol", "Alice", "Bob"} {
+		_, err := service.Register(ctx, application.RegisterInput{Name: name, Email: strings.ToLower(name) + "@example.com", Password: "password123"})
+		require.NoError(t, err)
+	}
+	admin := asAdmin(ctx)
+	byName := domain.UserSort{Field: domain.SortByName}
+
+	first, err := service.List(admin, application.ListInput{Sort: byName, Page: application.PageRequest{Limit: 2}})
+	require.NoError(t, err)
+	require.Equal(t, "Alice", first.Users[0].Name)
+	require.Equal(t, "Bob", first.Users[1].Name)
+
+	rest, err := service.List(admin, application.ListInput{Sort: byName, Page: application.PageRequest{Limit: 2, Cursor: first.NextCursor}})
+	require.NoError(t, err)
+	require.Len(t, rest.Users, 1)
+	require.Equal(t, "Carol", rest.Users[0].Name)
+
+	_, err = service.List(admin, application.ListInput{Page: application.PageRequest{Cursor: first.NextCursor}})
+	require.ErrorIs(t, err, application.ErrInvalidCursor, "a cursor only continues the sort it came from")
+}
+
+func TestListClampsPageSize(t *testing.T) {
+	var limits []int
+	repo := &stubRepo{
+		listFn: func(_ context.Context, query domain.UserListQuery) (domain.UserPage, error) {
+			limits = append(limits, query.Limit)
+			return domain.UserPage{}, nil
+		},
+	}
+	service := application.NewUserService(repo)
+	ctx := asAdmin(context.Background())
+
+	for _, limit := range []int{0, 10, 1000} {
+		_, err := service.List(ctx, application.ListInput{Page: application.PageRequest{Limit: limit}})
+		require.NoError(t, err)
+	}
+	require.Equal(t, []int{application.DefaultPageSize, 10, application.MaxPageSize}, limits)
+}
+
 func TestUpdateNoFields(t *testing.T) {
 	ctx := context.Background()
 	repo := &stubRepo{}
 	service := application.NewUserService(repo)
 
-	_, err := service.Update(ctx, "id", application.UpdateInput{})
+	_, err := service.Update(asUser(ctx, "id"), "id", application.UpdateInput{})
 	require.ErrorIs(t, err, application.ErrNoFieldsToUpdate)
 }
 
@@ -237,7 +538,7 @@ func TestDeleteError(t *testing.T) {
 	}
 	service := application.NewUserService(repo)
 
-	err := service.Delete(ctx, "id")
+	err := service.Delete(asUser(ctx, "id"), "id")
 	require.ErrorIs(t, err, errDelete)
 }
 
@@ -246,10 +547,10 @@ func TestUpdateValidationAndRepositoryErrors(t *testing.T) {
 	repo := &stubRepo{}
 	service := application.NewUserService(repo)
 
-	_, err := service.Update(ctx, "id", application.UpdateInput{Name: strPtr("   ")})
+	_, err := service.Update(asUser(ctx, "id"), "id", application.UpdateInput{Name: strPtr("   ")})
 	require.ErrorIs(t, err, domain.ErrInvalidName)
 
-	_, err = service.Update(ctx, "id", application.UpdateInput{Email: strPtr("invalid")})
+	_, err = service.Update(asUser(ctx, "id"), "id", application.UpdateInput{Email: strPtr("invalid")})
 	require.ErrorIs(t, err, domain.ErrInvalidEmail)
 
 	repo.updateFn = func(context.Context, string, domain.UpdateUser) (domain.User, error) {
@@ -257,7 +558,7 @@ func TestUpdateValidationAndRepositoryErrors(t *testing.T) {
 	}
 
 	name := "New"
-	_, err = service.Update(ctx, "id", application.UpdateInput{Name: &name})
+	_, err = service.Update(asUser(ctx, "id"), "id", application.UpdateInput{Name: &name})
 	require.ErrorContains(t, err, "update failed")
 }
 
@@ -269,9 +570,13 @@ type stubRepo struct {
 	createFn   func(context.Context, domain.User) (domain.User, error)
 	getByEmail func(context.Context, string) (domain.User, error)
 	getByID    func(context.Context, string) (domain.User, error)
-	listFn     func(context.Context) ([]domain.User, error)
+	listFn     func(context.Context, domain.UserListQuery) (domain.UserPage, error)
 	updateFn   func(context.Context, string, domain.UpdateUser) (domain.User, error)
+	passwordFn func(context.Context, string, string, time.Time) error
+	rehashFn   func(context.Context, string, string, string) error
+	verifyFn   func(context.Context, string, time.Time) (domain.User, error)
 	deleteFn   func(context.Context, string) error
+	restoreFn  func(context.Context, string) (domain.User, error)
 	countFn    func(context.Context) (int64, error)
 }
 
@@ -296,11 +601,11 @@ func (s *stubRepo) GetByID(ctx context.Context, id string) (domain.User, error)
 	return domain.User{}, application.ErrNotFound
 }
 
-func (s *stubRepo) List(ctx context.Context) ([]domain.User, error) {
+func (s *stubRepo) List(ctx context.Context, query domain.UserListQuery) (domain.UserPage, error) {
 	if s.listFn != nil {
-		return s.listFn(ctx)
+		return s.listFn(ctx, query)
 	}
-	return nil, nil
+	return domain.UserPage{}, nil
 }
 
 func (s *stubRepo) Update(ctx context.Context, id string, update domain.UpdateUser) (domain.User, error) {
@@ -310,16 +615,155 @@ func (s *stubRepo) Update(ctx context.Context, id string, update domain.UpdateUs
 	return domain.User{}, nil
 }
 
-func (s *stubRepo) Delete(ctx context.Context, id string) error {
+func (s *stubRepo) UpdatePassword(ctx context.Context, id, passwordHash string, changedAt time.Time) error {
+	if s.passwordFn != nil {
+		return s.passwordFn(ctx, id, passwordHash, changedAt)
+	}
+	return nil
+}
+
+func (s *stubRepo) UpdateRoles(ctx context.Context, id string, roles []domain.Role) error {
+	return nil
+}
+
+func (s *stubRepo) ReplacePasswordHash(ctx context.Context, id, oldHash, newHash string) error {
+	if s.rehashFn != nil {
+		return s.rehashFn(ctx, id, oldHash, newHash)
+	}
+	return nil
+}
+
+func (s *stubRepo) MarkVerified(ctx context.Context, id string, at time.Time) (domain.User, error) {
+	if s.verifyFn != nil {
+		return s.verifyFn(ctx, id, at)
+	}
+	return domain.User{ID: id, VerifiedAt: &at}, nil
+}
+
+func (s *stubRepo) UpdateMFA(ctx context.Context, id string, mfa domain.MFA) error {
+	return nil
+}
+
+func (s *stubRepo) UseTOTPStep(ctx context.Context, id string, step int64) error {
+	return nil
+}
+
+func (s *stubRepo) UseRecoveryCode(ctx context.Context, id, codeHash string) error {
+	return application.ErrNotFound
+}
+
+func (s *stubRepo) Delete(ctx context.Context, id string, at time.Time) error {
 	if s.deleteFn != nil {
 		return s.deleteFn(ctx, id)
 	}
 	return nil
 }
 
+func (s *stubRepo) Restore(ctx context.Context, id string) (domain.User, error) {
+	if s.restoreFn != nil {
+		return s.restoreFn(ctx, id)
+	}
+	return domain.User{}, application.ErrNotFound
+}
+
+func (s *stubRepo) Purge(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	return nil, nil
+}
+
 func (s *stubRepo) Count(ctx context.Context) (int64, error) {
 	if s.countFn != nil {
 		return s.countFn(ctx)
 	}
 	return 0, nil
 }
+
+type recordingPublisher struct {
+	events []domain.UserEvent
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event domain.UserEvent) {
+	p.events = append(p.events, event)
+}
+
+func TestUserChangesArePublished(t *testing.T) {
+	publisher := &recordingPublisher{}
+	service := application.NewUserService(memory.NewUserRepository(), application.WithEventPublisher(publisher))
+	ctx := context.Background()
+
+	user, err := service.Register(ctx, application.RegisterInput{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	_, err = service.Update(asUser(ctx, user.ID), user.ID, application.UpdateInput{Name: strPtr("Alice Smith")})
+	require.NoError(t, err)
+	_, err = service.Update(asUser(ctx, user.ID), user.ID, application.UpdateInput{})
+	require.ErrorIs(t, err, application.ErrNoFieldsToUpdate)
+	require.NoError(t, service.Delete(asUser(ctx, user.ID), user.ID))
+	_, err = service.Restore(asAdmin(ctx), user.ID)
+	require.NoError(t, err)
+
+	require.Len(t, publisher.events, 4)
+	require.Equal(t, domain.UserCreated, publisher.events[0].Type)
+	require.Equal(t, "Alice", publisher.events[0].User.Name)
+	require.Equal(t, domain.UserUpdated, publisher.events[1].Type)
+	require.Equal(t, "Alice Smith", publisher.events[1].User.Name)
+	require.Equal(t, domain.UserDeleted, publisher.events[2].Type)
+	require.Equal(t, user.ID, publisher.events[2].UserID)
+	require.Empty(t, publisher.events[2].User.Email)
+	require.Equal(t, domain.UserCreated, publisher.events[3].Type)
+	require.Equal(t, "Alice Smith", publisher.events[3].User.Name)
+}
+
+func TestWatchRequiresAdminAndSource(t *testing.T) {
+	service, ctx := newService()
+	noop := func(application.UserChange) error { return nil }
+
+	require.ErrorIs(t, service.Watch(asUser(ctx, "user-1"), "", noop), application.ErrForbidden)
+	require.ErrorIs(t, service.Watch(asAdmin(ctx), "", noop), application.ErrEventsUnavailable)
+
+	broker := memory.NewUserEventBroker(10)
+	service = application.NewUserService(memory.NewUserRepository(), application.WithEventPublisher(broker), application.WithEventSource(broker))
+	watchCtx, cancel := context.WithTimeout(asAdmin(ctx), 5*time.Second)
+	defer cancel()
+
+	done := errors.New("done")
+	changes := make(chan application.UserChange, 1)
+	result := make(chan error, 1)
+	go func() {
+		result <- service.Watch(watchCtx, "", func(change application.UserChange) error {
+			changes <- change
+			return done
+		})
+	}()
+	// Give the watcher a moment to subscribe before registering.
+	time.Sleep(10 * time.Millisecond)
+	_, err := service.Register(ctx, application.RegisterInput{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, <-result, done)
+	change := <-changes
+	require.Equal(t, domain.UserCreated, change.Event.Type)
+	require.NotEmpty(t, change.ResumeToken)
+}
+
+func TestRegisterAndUpdateReportEveryInvalidField(t *testing.T) {
+	policy := domain.PasswordPolicy{MinLength: 12, RequireDigit: true}
+	service := application.NewUserService(memory.NewUserRepository(), application.WithPasswordPolicy(policy))
+	ctx := context.Background()
+
+	_, err := service.Register(ctx, application.RegisterInput{Name: "Alice", Email: "nope", Password: "password"})
+	var invalid domain.ValidationErrors
+	require.ErrorAs(t, err, &invalid)
+	require.Len(t, invalid, 3)
+	require.Equal(t, domain.CodeInvalidFormat, invalid[0].Code)
+	require.Equal(t, domain.CodeTooShort, invalid[1].Code)
+	require.Equal(t, domain.CodeMissingDigit, invalid[2].Code)
+	require.ErrorIs(t, err, domain.ErrInvalidEmail)
+
+	user, err := service.Register(ctx, application.RegisterInput{Name: "Alice", Email: "alice@example.com", Password: "password1234"})
+	require.NoError(t, err)
+
+	_, err = service.Update(asUser(ctx, user.ID), user.ID, application.UpdateInput{Name: strPtr(" "), Email: strPtr("nope")})
+	require.ErrorAs(t, err, &invalid)
+	require.Len(t, invalid, 2)
+	require.ErrorIs(t, err, domain.ErrInvalidName)
+	require.ErrorIs(t, err, domain.ErrInvalidEmail)
+}
@@ -4,38 +4,184 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Config holds application configuration values.
 type Config struct {
-	Port           string
-	GRPCPort       string
-	MongoURI       string
-	MongoDatabase  string
-	JWTSecret      string
-	JWTIssuer      string
-	JWTExpiry      time.Duration
-	BackgroundTick time.Duration
-	Environment    string
+	Port                 string
+	GRPCPort             string
+	MongoURI             string
+	MongoDatabase        string
+	JWTSecret            string
+	JWTKeyFile           string
+	JWTKeyID             string
+	JWTKeyringFile       string
+	JWTKeyring           JWTKeyringConfig
+	JWTIssuer            string
+	JWTExpiry            time.Duration
+	RefreshTTL           time.Duration
+	ResetTTL             time.Duration
+	ResetURL             string
+	Mailer               string
+	MailFrom             string
+	MailLogFile          string
+	SMTPHost             string
+	SMTPPort             int
+	SMTPUsername         string
+	SMTPPassword         string
+	EmailVerification    string
+	VerificationTTL      time.Duration
+	VerifyURL            string
+	UnverifiedAccountTTL time.Duration
+	MFAIssuer            string
+	MFAChallengeTTL      time.Duration
+	PasswordHash         string
+	BcryptCost           int
+	Argon2Memory         int
+	Argon2Iterations     int
+	Argon2Parallelism    int
+	HashConcurrency      int
+	PasswordMinLength    int
+	PasswordMaxLength    int
+	PasswordRequire      []string
+	LoginFreeAttempts    int
+	LoginIPFreeAttempts  int
+	LoginBackoffBase     time.Duration
+	LoginBackoffMax      time.Duration
+	LockoutThreshold     int
+	LockoutDuration      time.Duration
+	LoginAttemptWindow   time.Duration
+	AdminEmails          []string
+	UserEvents           string
+	UserEventHistory     int
+	DeletedUserRetention time.Duration
+	UserPurgeInterval    time.Duration
+	BackgroundTick       time.Duration
+	Environment          string
 }
 
 // Load reads configuration from environment variables.
 func Load() (Config, error) {
 	cfg := Config{
-		Port:           getEnv("PORT", "8080"),
-		GRPCPort:       getEnv("GRPC_PORT", "50051"),
-		MongoURI:       getEnv("MONGO_URI", "mongodb://localhost:27017"),
-		MongoDatabase:  getEnv("MONGO_DB", "user_service"),
-		JWTSecret:      os.Getenv("JWT_SECRET"),
-		JWTIssuer:      getEnv("JWT_ISSUER", "backend-challenge"),
-		JWTExpiry:      parseDuration(getEnv("JWT_EXPIRY", "24h"), 24*time.Hour),
-		BackgroundTick: parseDuration(getEnv("USER_COUNT_TICK", "10s"), 10*time.Second),
-		Environment:    getEnv("ENVIRONMENT", "development"),
+		Port:                 getEnv("PORT", "8080"),
+		GRPCPort:             getEnv("GRPC_PORT", "50051"),
+		MongoURI:             getEnv("MONGO_URI", "mongodb://localhost:27017"),
+		MongoDatabase:        getEnv("MONGO_DB", "user_service"),
+		JWTSecret:            os.Getenv("JWT_SECRET"),
+		JWTKeyFile:           os.Getenv("JWT_SIGNING_KEY_FILE"),
+		JWTKeyID:             os.Getenv("JWT_KEY_ID"),
+		JWTKeyringFile:       os.Getenv("JWT_KEYRING_FILE"),
+		JWTIssuer:            getEnv("JWT_ISSUER", "backend-challenge"),
+		JWTExpiry:            parseDuration(getEnv("JWT_EXPIRY", "15m"), 15*time.Minute),
+		RefreshTTL:           parseDuration(getEnv("REFRESH_TOKEN_TTL", "720h"), 720*time.Hour),
+		ResetTTL:             parseDuration(getEnv("PASSWORD_RESET_TTL", "1h"), time.Hour),
+		ResetURL:             os.Getenv("PASSWORD_RESET_URL"),
+		Mailer:               getEnv("MAILER", "log"),
+		MailFrom:             getEnv("MAIL_FROM", "no-reply@localhost"),
+		MailLogFile:          os.Getenv("MAIL_LOG_FILE"),
+		SMTPHost:             os.Getenv("SMTP_HOST"),
+		SMTPPort:             MustParseInt("SMTP_PORT", 587),
+		SMTPUsername:         os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:         os.Getenv("SMTP_PASSWORD"),
+		EmailVerification:    getEnv("EMAIL_VERIFICATION", "optional"),
+		VerificationTTL:      parseDuration(getEnv("EMAIL_VERIFICATION_TTL", "24h"), 24*time.Hour),
+		VerifyURL:            getEnv("EMAIL_VERIFY_URL", "http://localhost:8080/auth/verify"),
+		UnverifiedAccountTTL: parseDuration(getEnv("UNVERIFIED_ACCOUNT_TTL", "0"), 0),
+		MFAIssuer:            getEnv("MFA_ISSUER", "backend-challenge"),
+		MFAChallengeTTL:      parseDuration(getEnv("MFA_CHALLENGE_TTL", "5m"), 5*time.Minute),
+		PasswordHash:         getEnv("PASSWORD_HASH", "argon2id"),
+		BcryptCost:           MustParseInt("BCRYPT_COST", 10),
+		Argon2Memory:         MustParseInt("ARGON2_MEMORY", 64*1024),
+		Argon2Iterations:     MustParseInt("ARGON2_ITERATIONS", 3),
+		Argon2Parallelism:    MustParseInt("ARGON2_PARALLELISM", 2),
+		HashConcurrency:      MustParseInt("PASSWORD_HASH_CONCURRENCY", 0),
+		PasswordMinLength:    MustParseInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordMaxLength:    MustParseInt("PASSWORD_MAX_LENGTH", 0),
+		PasswordRequire:      splitList(os.Getenv("PASSWORD_REQUIRE")),
+		LoginFreeAttempts:    MustParseInt("LOGIN_FREE_ATTEMPTS", 3),
+		LoginIPFreeAttempts:  MustParseInt("LOGIN_IP_FREE_ATTEMPTS", 20),
+		LoginBackoffBase:     parseDuration(getEnv("LOGIN_BACKOFF_BASE", "1s"), time.Second),
+		LoginBackoffMax:      parseDuration(getEnv("LOGIN_BACKOFF_MAX", "15m"), 15*time.Minute),
+		LockoutThreshold:     MustParseInt("LOCKOUT_THRESHOLD", 10),
+		LockoutDuration:      parseDuration(getEnv("LOCKOUT_DURATION", "15m"), 15*time.Minute),
+		LoginAttemptWindow:   parseDuration(getEnv("LOGIN_ATTEMPT_WINDOW", "1h"), time.Hour),
+		AdminEmails:          splitList(os.Getenv("ADMIN_EMAILS")),
+		UserEvents:           getEnv("USER_EVENTS", "memory"),
+		UserEventHistory:     MustParseInt("USER_EVENT_HISTORY", 1000),
+		DeletedUserRetention: parseDuration(getEnv("DELETED_USER_RETENTION", "720h"), 30*24*time.Hour),
+		UserPurgeInterval:    parseDuration(getEnv("USER_PURGE_INTERVAL", "1h"), time.Hour),
+		BackgroundTick:       parseDuration(getEnv("USER_COUNT_TICK", "10s"), 10*time.Second),
+		Environment:          getEnv("ENVIRONMENT", "development"),
 	}
 
-	if cfg.JWTSecret == "" {
-		return Config{}, fmt.Errorf("JWT_SECRET must be provided")
+	switch {
+	case cfg.JWTKeyringFile != "":
+		ring, err := LoadKeyring(cfg.JWTKeyringFile)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.JWTKeyring = ring
+	case cfg.JWTKeyFile != "":
+		cfg.JWTKeyring = singleKeyring(cfg.JWTKeyID, cfg.JWTKeyFile, "")
+	case cfg.JWTSecret != "":
+		cfg.JWTKeyring = singleKeyring(cfg.JWTKeyID, "", cfg.JWTSecret)
+	default:
+		return Config{}, fmt.Errorf("JWT_SECRET, JWT_SIGNING_KEY_FILE, or JWT_KEYRING_FILE must be provided")
+	}
+
+	switch cfg.Mailer {
+	case "log":
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return Config{}, fmt.Errorf("SMTP_HOST must be provided when MAILER=smtp")
+		}
+	default:
+		return Config{}, fmt.Errorf("unsupported MAILER %q", cfg.Mailer)
+	}
+
+	switch cfg.EmailVerification {
+	case "optional", "required", "limited":
+	default:
+		return Config{}, fmt.Errorf("unsupported EMAIL_VERIFICATION %q", cfg.EmailVerification)
+	}
+
+	switch cfg.PasswordHash {
+	case "bcrypt", "argon2id":
+	default:
+		return Config{}, fmt.Errorf("unsupported PASSWORD_HASH %q", cfg.PasswordHash)
+	}
+	if cfg.BcryptCost < 4 || cfg.BcryptCost > 31 {
+		return Config{}, fmt.Errorf("BCRYPT_COST must be between 4 and 31")
+	}
+	if cfg.Argon2Memory < 8*cfg.Argon2Parallelism || cfg.Argon2Iterations < 1 || cfg.Argon2Parallelism < 1 || cfg.Argon2Parallelism > 255 {
+		return Config{}, fmt.Errorf("ARGON2_MEMORY, ARGON2_ITERATIONS, and ARGON2_PARALLELISM must be positive, with at least 8 KiB of memory per lane")
+	}
+	if cfg.PasswordMinLength < 1 || (cfg.PasswordMaxLength != 0 && cfg.PasswordMaxLength < cfg.PasswordMinLength) {
+		return Config{}, fmt.Errorf("PASSWORD_MIN_LENGTH must be positive and PASSWORD_MAX_LENGTH, when set, at least as large")
+	}
+	for _, rule := range cfg.PasswordRequire {
+		switch rule {
+		case "upper", "lower", "digit", "symbol":
+		default:
+			return Config{}, fmt.Errorf("unsupported PASSWORD_REQUIRE rule %q", rule)
+		}
+	}
+	if cfg.LoginAttemptWindow < cfg.LoginBackoffMax || cfg.LoginAttemptWindow < cfg.LockoutDuration {
+		return Config{}, fmt.Errorf("LOGIN_ATTEMPT_WINDOW must be at least LOGIN_BACKOFF_MAX and LOCKOUT_DURATION")
+	}
+
+	switch cfg.UserEvents {
+	case "memory", "mongo":
+	default:
+		return Config{}, fmt.Errorf("unsupported USER_EVENTS %q", cfg.UserEvents)
+	}
+	if cfg.UserEventHistory < 0 {
+		return Config{}, fmt.Errorf("USER_EVENT_HISTORY must not be negative")
+	}
+	if cfg.DeletedUserRetention < 0 || cfg.UserPurgeInterval <= 0 {
+		return Config{}, fmt.Errorf("DELETED_USER_RETENTION must not be negative and USER_PURGE_INTERVAL must be positive")
 	}
 
 	return cfg, nil
@@ -48,6 +194,17 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// splitList parses a comma-separated list, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func parseDuration(value string, fallback time.Duration) time.Duration {
 	d, err := time.ParseDuration(value)
 	if err != nil {
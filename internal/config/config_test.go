@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -14,6 +15,9 @@ func TestLoadSuccess(t *testing.T) {
 	t.Setenv("MONGO_DB", "db")
 	t.Setenv("JWT_ISSUER", "issuer")
 	t.Setenv("JWT_EXPIRY", "2h")
+	t.Setenv("REFRESH_TOKEN_TTL", "48h")
+	t.Setenv("PASSWORD_RESET_TTL", "30m")
+	t.Setenv("MFA_CHALLENGE_TTL", "2m")
 	t.Setenv("USER_COUNT_TICK", "30s")
 	t.Setenv("ENVIRONMENT", "test")
 
@@ -28,6 +32,18 @@ func TestLoadSuccess(t *testing.T) {
 	if cfg.JWTExpiry != 2*time.Hour {
 		t.Fatalf("expected expiry 2h got %v", cfg.JWTExpiry)
 	}
+	if cfg.RefreshTTL != 48*time.Hour {
+		t.Fatalf("expected refresh ttl 48h got %v", cfg.RefreshTTL)
+	}
+	if cfg.ResetTTL != 30*time.Minute || cfg.Mailer != "log" {
+		t.Fatalf("unexpected reset config %+v", cfg)
+	}
+	if cfg.MFAIssuer != "backend-challenge" || cfg.MFAChallengeTTL != 2*time.Minute {
+		t.Fatalf("unexpected mfa config %+v", cfg)
+	}
+	if cfg.JWTKeyring.Active != "" || len(cfg.JWTKeyring.Keys) != 1 || cfg.JWTKeyring.Keys[0].Secret != "secret" {
+		t.Fatalf("expected single-secret keyring got %+v", cfg.JWTKeyring)
+	}
 	if cfg.BackgroundTick != 30*time.Second {
 		t.Fatalf("expected tick 30s got %v", cfg.BackgroundTick)
 	}
@@ -40,6 +56,191 @@ func TestLoadMissingSecret(t *testing.T) {
 	}
 }
 
+func TestLoadMailerSettings(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("MAILER", "smtp")
+	os.Unsetenv("SMTP_HOST")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error when SMTP_HOST missing")
+	}
+
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	t.Setenv("SMTP_PORT", "2525")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if cfg.SMTPHost != "smtp.example.com" || cfg.SMTPPort != 2525 {
+		t.Fatalf("unexpected smtp config %+v", cfg)
+	}
+
+	t.Setenv("MAILER", "pigeon")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for unknown mailer")
+	}
+}
+
+func TestLoadEmailVerificationSettings(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if cfg.EmailVerification != "optional" || cfg.VerificationTTL != 24*time.Hour || cfg.UnverifiedAccountTTL != 0 {
+		t.Fatalf("unexpected verification defaults %+v", cfg)
+	}
+
+	t.Setenv("EMAIL_VERIFICATION", "limited")
+	t.Setenv("UNVERIFIED_ACCOUNT_TTL", "168h")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if cfg.EmailVerification != "limited" || cfg.UnverifiedAccountTTL != 168*time.Hour {
+		t.Fatalf("unexpected verification config %+v", cfg)
+	}
+
+	t.Setenv("EMAIL_VERIFICATION", "sometimes")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for unknown verification mode")
+	}
+}
+
+func TestLoadPasswordHashSettings(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if cfg.PasswordHash != "argon2id" || cfg.BcryptCost != 10 || cfg.Argon2Memory != 65536 || cfg.Argon2Iterations != 3 || cfg.Argon2Parallelism != 2 || cfg.HashConcurrency != 0 {
+		t.Fatalf("unexpected password hash defaults %+v", cfg)
+	}
+
+	t.Setenv("PASSWORD_HASH", "bcrypt")
+	t.Setenv("BCRYPT_COST", "12")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if cfg.PasswordHash != "bcrypt" || cfg.BcryptCost != 12 {
+		t.Fatalf("unexpected password hash config %+v", cfg)
+	}
+
+	t.Setenv("BCRYPT_COST", "3")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for bcrypt cost below minimum")
+	}
+	t.Setenv("BCRYPT_COST", "12")
+
+	t.Setenv("ARGON2_PARALLELISM", "0")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for zero argon2 parallelism")
+	}
+	t.Setenv("ARGON2_PARALLELISM", "2")
+
+	t.Setenv("PASSWORD_HASH", "md5")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for unknown password hash")
+	}
+}
+
+func TestLoadLoginThrottleSettings(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if cfg.LoginFreeAttempts != 3 || cfg.LoginIPFreeAttempts != 20 || cfg.LockoutThreshold != 10 {
+		t.Fatalf("unexpected throttle defaults %+v", cfg)
+	}
+	if cfg.LoginBackoffBase != time.Second || cfg.LoginBackoffMax != 15*time.Minute || cfg.LockoutDuration != 15*time.Minute || cfg.LoginAttemptWindow != time.Hour {
+		t.Fatalf("unexpected throttle durations %+v", cfg)
+	}
+
+	t.Setenv("LOCKOUT_DURATION", "2h")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error when the window is shorter than the lockout")
+	}
+}
+
+func TestLoadAdminEmails(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("ADMIN_EMAILS", " root@example.com, ,ops@example.com")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if len(cfg.AdminEmails) != 2 || cfg.AdminEmails[0] != "root@example.com" || cfg.AdminEmails[1] != "ops@example.com" {
+		t.Fatalf("unexpected admin emails %q", cfg.AdminEmails)
+	}
+}
+
+func TestLoadSigningKeyFileWithoutSecret(t *testing.T) {
+	os.Unsetenv("JWT_SECRET")
+	t.Setenv("JWT_SIGNING_KEY_FILE", "/etc/keys/jwt.pem")
+	t.Setenv("JWT_KEY_ID", "2024-01")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if cfg.JWTKeyFile != "/etc/keys/jwt.pem" || cfg.JWTKeyID != "2024-01" {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+}
+
+func TestLoadKeyringFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	data := `{
+		"active": "2024-06",
+		"keys": [
+			{"id": "2024-06", "file": "/keys/2024-06.pem"},
+			{"id": "2024-01", "secret": "old-secret", "retiresAt": "2024-07-01T00:00:00Z"}
+		]
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("write keyring: %v", err)
+	}
+	os.Unsetenv("JWT_SECRET")
+	t.Setenv("JWT_KEYRING_FILE", path)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if cfg.JWTKeyring.Active != "2024-06" || len(cfg.JWTKeyring.Keys) != 2 {
+		t.Fatalf("unexpected keyring %+v", cfg.JWTKeyring)
+	}
+	if retires := cfg.JWTKeyring.Keys[1].RetiresAt; retires == nil || !retires.Equal(time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected retirement %v", retires)
+	}
+}
+
+func TestKeyringValidate(t *testing.T) {
+	retired := time.Now()
+	tests := []struct {
+		name string
+		ring JWTKeyringConfig
+	}{
+		{name: "missing active", ring: JWTKeyringConfig{Active: "a", Keys: []JWTKeyConfig{{ID: "b", Secret: "s"}}}},
+		{name: "duplicate id", ring: JWTKeyringConfig{Active: "a", Keys: []JWTKeyConfig{{ID: "a", Secret: "s"}, {ID: "a", Secret: "t"}}}},
+		{name: "no material", ring: JWTKeyringConfig{Active: "a", Keys: []JWTKeyConfig{{ID: "a"}}}},
+		{name: "retiring active", ring: JWTKeyringConfig{Active: "a", Keys: []JWTKeyConfig{{ID: "a", Secret: "s", RetiresAt: &retired}}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.ring.Validate(); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
 func TestParseDurationFallback(t *testing.T) {
 	if d := parseDuration("bad", time.Minute); d != time.Minute {
 		t.Fatalf("expected fallback duration got %v", d)
@@ -59,3 +260,91 @@ func TestMustParseInt(t *testing.T) {
 		t.Fatal("expected parsed value")
 	}
 }
+
+func TestLoadUserEventSettings(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if cfg.UserEvents != "memory" || cfg.UserEventHistory != 1000 {
+		t.Fatalf("unexpected user event defaults %+v", cfg)
+	}
+
+	t.Setenv("USER_EVENTS", "mongo")
+	t.Setenv("USER_EVENT_HISTORY", "50")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if cfg.UserEvents != "mongo" || cfg.UserEventHistory != 50 {
+		t.Fatalf("unexpected user event config %+v", cfg)
+	}
+
+	t.Setenv("USER_EVENTS", "kafka")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for unknown user event source")
+	}
+}
+
+func TestLoadDeletedUserSettings(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if cfg.DeletedUserRetention != 30*24*time.Hour || cfg.UserPurgeInterval != time.Hour {
+		t.Fatalf("unexpected deleted user defaults %+v", cfg)
+	}
+
+	t.Setenv("DELETED_USER_RETENTION", "168h")
+	t.Setenv("USER_PURGE_INTERVAL", "10m")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if cfg.DeletedUserRetention != 7*24*time.Hour || cfg.UserPurgeInterval != 10*time.Minute {
+		t.Fatalf("unexpected deleted user config %+v", cfg)
+	}
+
+	t.Setenv("DELETED_USER_RETENTION", "-1h")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for a negative retention")
+	}
+}
+
+func TestLoadPasswordPolicySettings(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if cfg.PasswordMinLength != 8 || cfg.PasswordMaxLength != 0 || len(cfg.PasswordRequire) != 0 {
+		t.Fatalf("unexpected password policy defaults %+v", cfg)
+	}
+
+	t.Setenv("PASSWORD_MIN_LENGTH", "12")
+	t.Setenv("PASSWORD_MAX_LENGTH", "64")
+	t.Setenv("PASSWORD_REQUIRE", "upper, digit")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if cfg.PasswordMinLength != 12 || cfg.PasswordMaxLength != 64 || len(cfg.PasswordRequire) != 2 || cfg.PasswordRequire[1] != "digit" {
+		t.Fatalf("unexpected password policy config %+v", cfg)
+	}
+
+	t.Setenv("PASSWORD_MAX_LENGTH", "10")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for a max length below the min length")
+	}
+	t.Setenv("PASSWORD_MAX_LENGTH", "64")
+
+	t.Setenv("PASSWORD_REQUIRE", "emoji")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for an unknown password rule")
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// JWTKeyConfig describes one key of the JWT keyring. Exactly one of File
+// (a PEM private or public key) or Secret (an HS256 shared secret) is set.
+type JWTKeyConfig struct {
+	ID        string     `json:"id"`
+	File      string     `json:"file,omitempty"`
+	Secret    string     `json:"secret,omitempty"`
+	RetiresAt *time.Time `json:"retiresAt,omitempty"`
+}
+
+// JWTKeyringConfig lists the keys trusted for JWTs and which one signs.
+type JWTKeyringConfig struct {
+	Active string         `json:"active"`
+	Keys   []JWTKeyConfig `json:"keys"`
+}
+
+// LoadKeyring reads and validates a JSON keyring file.
+func LoadKeyring(path string) (JWTKeyringConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return JWTKeyringConfig{}, fmt.Errorf("read keyring: %w", err)
+	}
+
+	var ring JWTKeyringConfig
+	if err := json.Unmarshal(data, &ring); err != nil {
+		return JWTKeyringConfig{}, fmt.Errorf("parse keyring: %w", err)
+	}
+	if err := ring.Validate(); err != nil {
+		return JWTKeyringConfig{}, err
+	}
+	return ring, nil
+}
+
+// Validate checks that key ids are unique and the active key is present.
+func (r JWTKeyringConfig) Validate() error {
+	seen := make(map[string]bool, len(r.Keys))
+	activeFound := false
+	for _, key := range r.Keys {
+		if seen[key.ID] {
+			return fmt.Errorf("duplicate keyring id %q", key.ID)
+		}
+		seen[key.ID] = true
+
+		if (key.File == "") == (key.Secret == "") {
+			return fmt.Errorf("keyring key %q must set exactly one of file or secret", key.ID)
+		}
+		if key.ID == r.Active {
+			activeFound = true
+			if key.RetiresAt != nil {
+				return fmt.Errorf("active key %q cannot have a retirement date", key.ID)
+			}
+		}
+	}
+	if !activeFound {
+		return fmt.Errorf("active key %q not found in keyring", r.Active)
+	}
+	return nil
+}
+
+// singleKeyring describes the legacy single-key configuration as a keyring.
+func singleKeyring(id, file, secret string) JWTKeyringConfig {
+	return JWTKeyringConfig{
+		Active: id,
+		Keys:   []JWTKeyConfig{{ID: id, File: file, Secret: secret}},
+	}
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	// RFC 6238 appendix B SHA-1 vectors, truncated to six digits.
+	secret := TOTPEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tc := range tests {
+		got, err := TOTPCode(secret, TOTPStep(time.Unix(tc.unix, 0)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tc.want {
+			t.Fatalf("at %d expected %s got %s", tc.unix, tc.want, got)
+		}
+	}
+}
+
+func TestTOTPCodeInvalidSecret(t *testing.T) {
+	if _, err := TOTPCode("not base32!", 1); err != ErrInvalidTOTPSecret {
+		t.Fatalf("expected ErrInvalidTOTPSecret got %v", err)
+	}
+}
+
+func TestMFAState(t *testing.T) {
+	now := time.Now()
+	if (MFA{}).Enabled() || (MFA{}).Pending() {
+		t.Fatal("zero value should be neither enabled nor pending")
+	}
+	if !(MFA{Secret: "ABC"}).Pending() {
+		t.Fatal("unconfirmed secret should be pending")
+	}
+	enabled := MFA{Secret: "ABC", EnabledAt: &now}
+	if !enabled.Enabled() || enabled.Pending() {
+		t.Fatal("confirmed secret should be enabled")
+	}
+	if !(User{MFA: enabled}).Sanitize().MFAEnabled {
+		t.Fatal("expected public projection to report mfa")
+	}
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// TokenPurpose scopes a one-time token to the flow that issued it, so a token
+// minted for one flow can never be redeemed by another.
+type TokenPurpose string
+
+const (
+	// PurposePasswordReset tokens let a user choose a new password.
+	PurposePasswordReset TokenPurpose = "password_reset"
+	// PurposeEmailVerification tokens confirm ownership of an email address.
+	PurposeEmailVerification TokenPurpose = "email_verification"
+	// PurposeMFAChallenge tokens carry a password-verified login over to the
+	// second factor step.
+	PurposeMFAChallenge TokenPurpose = "mfa_challenge"
+)
+
+// OneTimeToken is a hashed, expiring credential that can be redeemed once.
+type OneTimeToken struct {
+	ID        string
+	UserID    string
+	Purpose   TokenPurpose
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// Expired reports whether the token is past its expiry at the given time.
+func (t OneTimeToken) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
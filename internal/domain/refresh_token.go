@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// RefreshToken is a persisted, opaque credential that can be exchanged for a
+// new access token. Tokens issued from the same login share a FamilyID so the
+// whole chain can be revoked when reuse is detected.
+type RefreshToken struct {
+	ID        string
+	UserID    string
+	FamilyID  string
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RotatedAt *time.Time
+	RevokedAt *time.Time
+}
+
+// Expired reports whether the token is past its expiry at the given time.
+func (t RefreshToken) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
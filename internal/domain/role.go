@@ -0,0 +1,21 @@
+package domain
+
+// Role grants a set of permissions to a user.
+type Role string
+
+const (
+	// RoleUser is given to every account and only allows acting on oneself.
+	RoleUser Role = "user"
+	// RoleAdmin may list, update, and delete any account.
+	RoleAdmin Role = "admin"
+)
+
+// HasRole reports whether roles contains role.
+func HasRole(roles []Role, role Role) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
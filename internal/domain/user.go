@@ -2,26 +2,42 @@ package domain
 
 import (
 	"errors"
-	"regexp"
 	"strings"
 	"time"
 )
 
 // User represents a persisted user account.
 type User struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Password  string    `json:"-"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Email      string     `json:"email"`
+	Password   string     `json:"-"`
+	Roles      []Role     `json:"roles"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	VerifiedAt *time.Time `json:"verifiedAt,omitempty"`
+	// PasswordChangedAt is when the password was last changed or reset.
+	// Access tokens issued before it are no longer accepted.
+	PasswordChangedAt *time.Time `json:"passwordChangedAt,omitempty"`
+	MFA               MFA        `json:"-"`
+	// DeletedAt is when the account was deleted. Deleted accounts are kept
+	// until they are purged and can be restored until then.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	// Version starts at 1 and grows with every change to what the user's
+	// public view shows. Updates can require it to be unchanged so that
+	// concurrent edits do not overwrite each other.
+	Version int64 `json:"version"`
 }
 
 // UserPublic is a safe projection used for API responses.
 type UserPublic struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Email         string     `json:"email"`
+	Roles         []Role     `json:"roles"`
+	EmailVerified bool       `json:"emailVerified"`
+	MFAEnabled    bool       `json:"mfaEnabled"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	DeletedAt     *time.Time `json:"deletedAt,omitempty"`
 }
 
 // Credentials holds login payload.
@@ -34,71 +50,58 @@ type Credentials struct {
 type UpdateUser struct {
 	Name  *string `json:"name,omitempty"`
 	Email *string `json:"email,omitempty"`
+	// IfVersion, when set, applies the update only while the user is still
+	// at that version.
+	IfVersion *int64 `json:"-"`
 }
 
 var (
 	// ErrInvalidName indicates the user name fails validation.
-	ErrInvalidName = errors.New("name must not be empty")
+	ErrInvalidName = errors.New("invalid name")
 	// ErrInvalidEmail indicates the email fails validation.
-	ErrInvalidEmail = errors.New("email must be valid")
+	ErrInvalidEmail = errors.New("invalid email")
 	// ErrInvalidPassword indicates the password fails validation.
-	ErrInvalidPassword = errors.New("password must be at least 8 characters")
+	ErrInvalidPassword = errors.New("invalid password")
 )
 
-var emailRegex = regexp.MustCompile(`^[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}$`)
-
-// ValidateName ensures name is not empty.
-func ValidateName(name string) error {
-	if strings.TrimSpace(name) == "" {
-		return ErrInvalidName
-	}
-	return nil
-}
-
-// ValidateEmail ensures email is in valid format.
-func ValidateEmail(email string) error {
+// ValidateCredentials ensures email/password look reasonable. Unlike the other
+// validators it returns the bare sentinels, since login failures never say
+// which field was wrong.
+func ValidateCredentials(email, password string) error {
 	if !emailRegex.MatchString(strings.TrimSpace(email)) {
 		return ErrInvalidEmail
 	}
-	return nil
-}
-
-// ValidatePassword enforces minimum requirements.
-func ValidatePassword(password string) error {
-	if len(password) < 8 {
+	if strings.TrimSpace(password) == "" {
 		return ErrInvalidPassword
 	}
 	return nil
 }
 
-// ValidateNewUser checks name, email, and password requirements.
-func ValidateNewUser(name, email, password string) error {
-	if err := ValidateName(name); err != nil {
-		return err
-	}
-	if err := ValidateEmail(email); err != nil {
-		return err
-	}
-	return ValidatePassword(password)
+// Verified reports whether the user has confirmed their email address.
+func (u User) Verified() bool {
+	return u.VerifiedAt != nil
 }
 
-// ValidateCredentials ensures email/password look reasonable.
-func ValidateCredentials(email, password string) error {
-	if err := ValidateEmail(email); err != nil {
-		return err
-	}
-	if strings.TrimSpace(password) == "" {
-		return ErrInvalidPassword
-	}
-	return nil
+// Deleted reports whether the account has been deleted but not yet purged.
+func (u User) Deleted() bool {
+	return u.DeletedAt != nil
+}
+
+// HasRole reports whether the user holds role.
+func (u User) HasRole(role Role) bool {
+	return HasRole(u.Roles, role)
 }
 
 // Sanitize converts a domain user to a public payload.
 func (u User) Sanitize() UserPublic {
 	return UserPublic{
-		ID:        u.ID,
-		Name:      u.Name,
-		Email:     u.Email,
-		CreatedAt: u.CreatedAt,
+		ID:            u.ID,
+		Name:          u.Name,
+		Email:         u.Email,
+		Roles:         u.Roles,
+		EmailVerified: u.Verified(),
+		MFAEnabled:    u.MFA.Enabled(),
+		CreatedAt:     u.CreatedAt,
+		DeletedAt:     u.DeletedAt,
 	}
 }
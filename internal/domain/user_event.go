@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// UserEventType names what happened to a user.
+type UserEventType string
+
+const (
+	UserCreated UserEventType = "created"
+	UserUpdated UserEventType = "updated"
+	UserDeleted UserEventType = "deleted"
+)
+
+// UserEvent records a change to a user. User holds the account as it is after
+// the change, and is empty for deletions.
+type UserEvent struct {
+	Type       UserEventType
+	UserID     string
+	User       User
+	OccurredAt time.Time
+}
+
+// NewUserEvent records a change to user that happened now.
+func NewUserEvent(eventType UserEventType, user User) UserEvent {
+	event := UserEvent{Type: eventType, UserID: user.ID, OccurredAt: time.Now().UTC()}
+	if eventType != UserDeleted {
+		event.User = user
+	}
+	return event
+}
@@ -0,0 +1,57 @@
+package domain
+
+import "errors"
+
+// UserField names a field of UserPublic by its JSON name.
+type UserField string
+
+const (
+	FieldID            UserField = "id"
+	FieldName          UserField = "name"
+	FieldEmail         UserField = "email"
+	FieldRoles         UserField = "roles"
+	FieldEmailVerified UserField = "emailVerified"
+	FieldMFAEnabled    UserField = "mfaEnabled"
+	FieldCreatedAt     UserField = "createdAt"
+	FieldDeletedAt     UserField = "deletedAt"
+)
+
+// ErrInvalidField indicates a field name that UserPublic does not have.
+var ErrInvalidField = errors.New("invalid user field")
+
+// ParseUserField checks that name is a field of UserPublic.
+func ParseUserField(name string) (UserField, error) {
+	switch field := UserField(name); field {
+	case FieldID, FieldName, FieldEmail, FieldRoles, FieldEmailVerified, FieldMFAEnabled, FieldCreatedAt, FieldDeletedAt:
+		return field, nil
+	default:
+		return "", ErrInvalidField
+	}
+}
+
+// Select returns only the given fields of u, keyed by their JSON names. The
+// result marshals like u restricted to those fields.
+func (u UserPublic) Select(fields []UserField) map[string]interface{} {
+	out := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		switch field {
+		case FieldID:
+			out[string(field)] = u.ID
+		case FieldName:
+			out[string(field)] = u.Name
+		case FieldEmail:
+			out[string(field)] = u.Email
+		case FieldRoles:
+			out[string(field)] = u.Roles
+		case FieldEmailVerified:
+			out[string(field)] = u.EmailVerified
+		case FieldMFAEnabled:
+			out[string(field)] = u.MFAEnabled
+		case FieldCreatedAt:
+			out[string(field)] = u.CreatedAt
+		case FieldDeletedAt:
+			out[string(field)] = u.DeletedAt
+		}
+	}
+	return out
+}
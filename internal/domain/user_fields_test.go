@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseUserField(t *testing.T) {
+	for _, name := range []string{"id", "name", "email", "roles", "emailVerified", "mfaEnabled", "createdAt", "deletedAt"} {
+		if field, err := ParseUserField(name); err != nil || string(field) != name {
+			t.Fatalf("expected %q to be a user field got %q, %v", name, field, err)
+		}
+	}
+	for _, name := range []string{"password", "Name", ""} {
+		if _, err := ParseUserField(name); err != ErrInvalidField {
+			t.Fatalf("expected ErrInvalidField for %q got %v", name, err)
+		}
+	}
+}
+
+func TestUserPublicSelect(t *testing.T) {
+	user := User{ID: "abc123", Name: "John", Email: "john@example.com", CreatedAt: time.Now().UTC()}.Sanitize()
+
+	raw, err := json.Marshal(user.Select([]UserField{FieldID, FieldName, FieldMFAEnabled}))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(raw) != `{"id":"abc123","mfaEnabled":false,"name":"John"}` {
+		t.Fatalf("expected only the selected fields got %s", raw)
+	}
+}
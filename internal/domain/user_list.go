@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// UserSortField names a field the user list can be sorted by.
+type UserSortField string
+
+const (
+	SortByCreatedAt UserSortField = "createdAt"
+	SortByName      UserSortField = "name"
+	SortByEmail     UserSortField = "email"
+)
+
+// ErrInvalidSort indicates a sort names a field the user list cannot be
+// sorted by.
+var ErrInvalidSort = errors.New("invalid sort field")
+
+// UserSort orders the user list. Users with equal sort keys are ordered by ID
+// in the same direction. Names and emails compare case-insensitively under
+// English collation rules, the way MongoDB compares them with locale "en" at
+// strength 2.
+type UserSort struct {
+	Field      UserSortField
+	Descending bool
+}
+
+// DefaultUserSort lists the newest users first.
+var DefaultUserSort = UserSort{Field: SortByCreatedAt, Descending: true}
+
+// ParseUserSort parses a sort such as "name" or "-createdAt", where a leading
+// "-" sorts descending.
+func ParseUserSort(s string) (UserSort, error) {
+	sort := UserSort{Field: UserSortField(strings.TrimPrefix(s, "-")), Descending: strings.HasPrefix(s, "-")}
+	switch sort.Field {
+	case SortByCreatedAt, SortByName, SortByEmail:
+		return sort, nil
+	default:
+		return UserSort{}, ErrInvalidSort
+	}
+}
+
+// String formats s the way ParseUserSort reads it.
+func (s UserSort) String() string {
+	if s.Descending {
+		return "-" + string(s.Field)
+	}
+	return string(s.Field)
+}
+
+// CursorOf returns the position of user in a list ordered by s.
+func (s UserSort) CursorOf(user User) UserCursor {
+	cursor := UserCursor{ID: user.ID}
+	switch s.Field {
+	case SortByName:
+		cursor.Key = user.Name
+	case SortByEmail:
+		cursor.Key = user.Email
+	default:
+		cursor.CreatedAt = user.CreatedAt
+	}
+	return cursor
+}
+
+// collators hold case-insensitive English collators. A collator keeps
+// buffers between calls, so each goroutine takes its own.
+var collators = sync.Pool{
+	New: func() any { return collate.New(language.English, collate.IgnoreCase) },
+}
+
+// compareKeys compares name or email sort keys, ignoring case but not accents.
+func compareKeys(a, b string) int {
+	c := collators.Get().(*collate.Collator)
+	defer collators.Put(c)
+	return c.CompareString(a, b)
+}
+
+// Precedes reports whether a comes before b in a list ordered by s.
+func (s UserSort) Precedes(a, b UserCursor) bool {
+	var cmp int
+	if s.Field == SortByName || s.Field == SortByEmail {
+		cmp = compareKeys(a.Key, b.Key)
+	} else {
+		cmp = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if cmp == 0 {
+		cmp = strings.Compare(a.ID, b.ID)
+	}
+	if s.Descending {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+// UserCursor is a position in the user list: the sort key and ID of a user.
+type UserCursor struct {
+	// CreatedAt is the sort key when sorting by creation time.
+	CreatedAt time.Time
+	// Key is the sort key when sorting by name or email.
+	Key string
+	ID  string
+}
+
+// UserFilter narrows the user list. Zero fields match every user that has
+// not been deleted.
+type UserFilter struct {
+	// Search matches users whose name or email starts with it, ignoring case.
+	Search string
+	// CreatedAfter and CreatedBefore bound the creation time, exclusive.
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// IncludeDeleted also matches deleted users, which are skipped otherwise.
+	IncludeDeleted bool
+}
+
+// Matches reports whether user passes the filter.
+func (f UserFilter) Matches(user User) bool {
+	if user.Deleted() && !f.IncludeDeleted {
+		return false
+	}
+	if f.Search != "" {
+		search := strings.ToLower(f.Search)
+		if !strings.HasPrefix(strings.ToLower(user.Name), search) && !strings.HasPrefix(strings.ToLower(user.Email), search) {
+			return false
+		}
+	}
+	if f.CreatedAfter != nil && !user.CreatedAt.After(*f.CreatedAfter) {
+		return false
+	}
+	if f.CreatedBefore != nil && !user.CreatedAt.Before(*f.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+// UserListQuery selects one page of the user list.
+type UserListQuery struct {
+	Filter UserFilter
+	// Sort orders the list. The zero value means DefaultUserSort.
+	Sort UserSort
+	// Limit is the most users to return.
+	Limit int
+	// After, when set, starts the page with the first user following it.
+	After *UserCursor
+	// Fields, when set, are the only fields the caller will read. Stores may
+	// leave the others zero, but always fill ID and the sort key.
+	Fields []UserField
+}
+
+// SortOrDefault returns the query's sort, or DefaultUserSort when none is set.
+func (q UserListQuery) SortOrDefault() UserSort {
+	if q.Sort.Field == "" {
+		return DefaultUserSort
+	}
+	return q.Sort
+}
+
+// UserPage is one page of the user list. Next is the position of the last
+// user on the page when more users follow, and nil on the last page.
+type UserPage struct {
+	Users []User
+	Next  *UserCursor
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseUserSort(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    UserSort
+		wantErr bool
+	}{
+		{input: "createdAt", want: UserSort{Field: SortByCreatedAt}},
+		{input: "-createdAt", want: UserSort{Field: SortByCreatedAt, Descending: true}},
+		{input: "name", want: UserSort{Field: SortByName}},
+		{input: "-email", want: UserSort{Field: SortByEmail, Descending: true}},
+		{input: "password", wantErr: true},
+		{input: "-", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := ParseUserSort(tc.input)
+			if tc.wantErr {
+				if err != ErrInvalidSort {
+					t.Fatalf("expected ErrInvalidSort got %v", err)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Fatalf("expected %+v got %+v, %v", tc.want, got, err)
+			}
+			if got.String() != tc.input {
+				t.Fatalf("expected String to round-trip %q got %q", tc.input, got.String())
+			}
+		})
+	}
+}
+
+func TestUserSortPrecedes(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	alice := User{ID: "a", Name: "alice", CreatedAt: at}
+	bob := User{ID: "b", Name: "Bob", CreatedAt: at.Add(time.Second)}
+	twin := User{ID: "c", Name: "ALICE", CreatedAt: at}
+	accented := User{ID: "d", Name: "Émile", CreatedAt: at}
+	plain := User{ID: "e", Name: "emile", CreatedAt: at}
+	zoe := User{ID: "f", Name: "zoe", CreatedAt: at}
+
+	tests := []struct {
+		sort        UserSort
+		first, then User
+	}{
+		{sort: DefaultUserSort, first: bob, then: alice},
+		{sort: DefaultUserSort, first: twin, then: alice},
+		{sort: UserSort{Field: SortByCreatedAt}, first: alice, then: twin},
+		{sort: UserSort{Field: SortByName}, first: alice, then: bob},
+		{sort: UserSort{Field: SortByName}, first: alice, then: twin},
+		{sort: UserSort{Field: SortByName, Descending: true}, first: twin, then: alice},
+		// Accents sort with their base letter, after the unaccented form.
+		{sort: UserSort{Field: SortByName}, first: accented, then: zoe},
+		{sort: UserSort{Field: SortByName}, first: plain, then: accented},
+	}
+
+	for _, tc := range tests {
+		a, b := tc.sort.CursorOf(tc.first), tc.sort.CursorOf(tc.then)
+		if !tc.sort.Precedes(a, b) || tc.sort.Precedes(b, a) {
+			t.Fatalf("sort %s: expected %s before %s", tc.sort, tc.first.ID, tc.then.ID)
+		}
+	}
+}
+
+func TestUserFilterMatches(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	user := User{Name: "Jane Doe", Email: "jane@example.com", CreatedAt: at}
+	before, after := at.Add(-time.Hour), at.Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		filter UserFilter
+		want   bool
+	}{
+		{name: "empty", want: true},
+		{name: "name prefix ignoring case", filter: UserFilter{Search: "JAN"}, want: true},
+		{name: "email prefix", filter: UserFilter{Search: "jane@ex"}, want: true},
+		{name: "not a prefix", filter: UserFilter{Search: "doe"}},
+		{name: "created in range", filter: UserFilter{CreatedAfter: &before, CreatedBefore: &after}, want: true},
+		{name: "bounds are exclusive", filter: UserFilter{CreatedAfter: &at}},
+		{name: "created too early", filter: UserFilter{CreatedAfter: &after}},
+		{name: "created too late", filter: UserFilter{CreatedBefore: &before}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.filter.Matches(user); got != tc.want {
+				t.Fatalf("expected %v got %v", tc.want, got)
+			}
+		})
+	}
+
+	user.DeletedAt = &after
+	if (UserFilter{}).Matches(user) {
+		t.Fatal("expected deleted users to be excluded by default")
+	}
+	if !(UserFilter{IncludeDeleted: true}).Matches(user) {
+		t.Fatal("expected deleted users to match when included")
+	}
+}
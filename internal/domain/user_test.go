@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -78,24 +79,31 @@ func TestValidatePassword(t *testing.T) {
 }
 
 func TestValidateNewUser(t *testing.T) {
-	if err := ValidateNewUser("Name", "user@example.com", "password123"); err != nil {
+	if err := ValidateNewUser("Name", "user@example.com", "password123", DefaultPasswordPolicy); err != nil {
 		t.Fatalf("expected nil error got %v", err)
 	}
 
-	err := ValidateNewUser("", "user@example.com", "password123")
-	if err != ErrInvalidName {
-		t.Fatalf("expected ErrInvalidName got %v", err)
+	err := ValidateNewUser("", "user@example.com", "password123", DefaultPasswordPolicy)
+	if !errors.Is(err, ErrInvalidName) || errors.Is(err, ErrInvalidEmail) {
+		t.Fatalf("expected only ErrInvalidName got %v", err)
 	}
 
-	err = ValidateNewUser("Name", "invalid", "password123")
-	if err != ErrInvalidEmail {
+	err = ValidateNewUser("Name", "invalid", "password123", DefaultPasswordPolicy)
+	if !errors.Is(err, ErrInvalidEmail) {
 		t.Fatalf("expected ErrInvalidEmail got %v", err)
 	}
 
-	err = ValidateNewUser("Name", "user@example.com", "short")
-	if err != ErrInvalidPassword {
+	err = ValidateNewUser("Name", "user@example.com", "short", DefaultPasswordPolicy)
+	if !errors.Is(err, ErrInvalidPassword) {
 		t.Fatalf("expected ErrInvalidPassword got %v", err)
 	}
+
+	err = ValidateNewUser(" ", "invalid", "short", DefaultPasswordPolicy)
+	for _, want := range []error{ErrInvalidName, ErrInvalidEmail, ErrInvalidPassword} {
+		if !errors.Is(err, want) {
+			t.Fatalf("expected every failure to be reported, missing %v in %v", want, err)
+		}
+	}
 }
 
 func TestValidateCredentials(t *testing.T) {
@@ -0,0 +1,200 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Length limits for user fields.
+const (
+	MaxNameLength  = 100
+	MaxEmailLength = 254
+)
+
+// Field error codes. They are stable and meant for clients to match on.
+const (
+	CodeRequired         = "required"
+	CodeInvalidFormat    = "invalid_format"
+	CodeTooShort         = "too_short"
+	CodeTooLong          = "too_long"
+	CodeMissingUppercase = "missing_uppercase"
+	CodeMissingLowercase = "missing_lowercase"
+	CodeMissingDigit     = "missing_digit"
+	CodeMissingSymbol    = "missing_symbol"
+)
+
+var emailRegex = regexp.MustCompile(`^[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}$`)
+
+// FieldError explains why one field is invalid. It unwraps to the field's
+// sentinel, such as ErrInvalidEmail.
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e FieldError) Error() string {
+	return e.Message
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors collects every failing field of an input. errors.Is
+// matches the sentinels of the fields it contains.
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	messages := make([]string, 0, len(v))
+	for _, fe := range v {
+		messages = append(messages, fe.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (v ValidationErrors) Unwrap() []error {
+	errs := make([]error, 0, len(v))
+	for _, fe := range v {
+		errs = append(errs, fe)
+	}
+	return errs
+}
+
+// orNil returns nil when nothing failed, so callers never see an empty,
+// non-nil error.
+func (v ValidationErrors) orNil() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+func (v *ValidationErrors) add(field, code, message string, err error) {
+	*v = append(*v, FieldError{Field: field, Code: code, Message: message, Err: err})
+}
+
+// PasswordPolicy sets the requirements for new passwords. A zero MaxLength
+// means no upper limit.
+type PasswordPolicy struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// DefaultPasswordPolicy only asks for eight characters.
+var DefaultPasswordPolicy = PasswordPolicy{MinLength: 8}
+
+// Validate checks password against the policy and reports every rule it
+// breaks.
+func (p PasswordPolicy) Validate(password string) error {
+	var errs ValidationErrors
+	p.check(&errs, password)
+	return errs.orNil()
+}
+
+func (p PasswordPolicy) check(errs *ValidationErrors, password string) {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		errs.add("password", CodeTooShort, fmt.Sprintf("password must be at least %d characters", p.MinLength), ErrInvalidPassword)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		errs.add("password", CodeTooLong, fmt.Sprintf("password must be at most %d characters", p.MaxLength), ErrInvalidPassword)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		errs.add("password", CodeMissingUppercase, "password must contain an uppercase letter", ErrInvalidPassword)
+	}
+	if p.RequireLower && !lower {
+		errs.add("password", CodeMissingLowercase, "password must contain a lowercase letter", ErrInvalidPassword)
+	}
+	if p.RequireDigit && !digit {
+		errs.add("password", CodeMissingDigit, "password must contain a digit", ErrInvalidPassword)
+	}
+	if p.RequireSymbol && !symbol {
+		errs.add("password", CodeMissingSymbol, "password must contain a symbol", ErrInvalidPassword)
+	}
+}
+
+// ValidateName ensures name is not empty or too long.
+func ValidateName(name string) error {
+	var errs ValidationErrors
+	checkName(&errs, name)
+	return errs.orNil()
+}
+
+// ValidateEmail ensures email is in valid format and not too long.
+func ValidateEmail(email string) error {
+	var errs ValidationErrors
+	checkEmail(&errs, email)
+	return errs.orNil()
+}
+
+// ValidatePassword checks password against DefaultPasswordPolicy.
+func ValidatePassword(password string) error {
+	return DefaultPasswordPolicy.Validate(password)
+}
+
+// ValidateNewUser checks name, email, and password requirements and reports
+// every failing field at once.
+func ValidateNewUser(name, email, password string, policy PasswordPolicy) error {
+	var errs ValidationErrors
+	checkName(&errs, name)
+	checkEmail(&errs, email)
+	policy.check(&errs, password)
+	return errs.orNil()
+}
+
+// ValidateUpdate checks the fields of an update that are set.
+func ValidateUpdate(update UpdateUser) error {
+	var errs ValidationErrors
+	if update.Name != nil {
+		checkName(&errs, *update.Name)
+	}
+	if update.Email != nil {
+		checkEmail(&errs, *update.Email)
+	}
+	return errs.orNil()
+}
+
+func checkName(errs *ValidationErrors, name string) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		errs.add("name", CodeRequired, "name must not be empty", ErrInvalidName)
+	case utf8.RuneCountInString(name) > MaxNameLength:
+		errs.add("name", CodeTooLong, fmt.Sprintf("name must be at most %d characters", MaxNameLength), ErrInvalidName)
+	}
+}
+
+func checkEmail(errs *ValidationErrors, email string) {
+	email = strings.TrimSpace(email)
+	switch {
+	case email == "":
+		errs.add("email", CodeRequired, "email must not be empty", ErrInvalidEmail)
+	case len(email) > MaxEmailLength:
+		errs.add("email", CodeTooLong, fmt.Sprintf("email must be at most %d characters", MaxEmailLength), ErrInvalidEmail)
+	case !emailRegex.MatchString(email):
+		errs.add("email", CodeInvalidFormat, "email must be valid", ErrInvalidEmail)
+	}
+}
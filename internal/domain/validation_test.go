@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func codes(t *testing.T, err error) []string {
+	t.Helper()
+	var invalid ValidationErrors
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected ValidationErrors got %T %v", err, err)
+	}
+	var got []string
+	for _, fe := range invalid {
+		got = append(got, fe.Field+":"+fe.Code)
+	}
+	return got
+}
+
+func TestValidateNewUserCollectsEveryField(t *testing.T) {
+	err := ValidateNewUser(strings.Repeat("a", MaxNameLength+1), "nope", "short", DefaultPasswordPolicy)
+	if got := strings.Join(codes(t, err), ","); got != "name:too_long,email:invalid_format,password:too_short" {
+		t.Fatalf("unexpected field errors %s", got)
+	}
+	if !errors.Is(err, ErrInvalidName) || !errors.Is(err, ErrInvalidEmail) || !errors.Is(err, ErrInvalidPassword) {
+		t.Fatalf("expected every sentinel to match %v", err)
+	}
+
+	longEmail := strings.Repeat("a", MaxEmailLength) + "@example.com"
+	if got := strings.Join(codes(t, ValidateNewUser("", longEmail, "long-enough", DefaultPasswordPolicy)), ","); got != "name:required,email:too_long" {
+		t.Fatalf("unexpected field errors %s", got)
+	}
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 10, MaxLength: 20, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true}
+
+	if err := policy.Validate("Correct-Horse-9"); err != nil {
+		t.Fatalf("expected nil error got %v", err)
+	}
+
+	tests := []struct {
+		password string
+		want     string
+	}{
+		{password: "Sh0rt!", want: "password:too_short"},
+		{password: "Way-Too-Long-Password-1", want: "password:too_long"},
+		{password: "lowercase-only", want: "password:missing_uppercase,password:missing_digit"},
+		{password: "NOLOWER-123", want: "password:missing_lowercase"},
+		{password: "NoSymbolHere1", want: "password:missing_symbol"},
+	}
+	for _, tc := range tests {
+		if got := strings.Join(codes(t, policy.Validate(tc.password)), ","); got != tc.want {
+			t.Fatalf("%s: expected %s got %s", tc.password, tc.want, got)
+		}
+	}
+}
+
+func TestValidateUpdateOnlyChecksSetFields(t *testing.T) {
+	if err := ValidateUpdate(UpdateUser{}); err != nil {
+		t.Fatalf("expected nil error got %v", err)
+	}
+	name, email := " ", "nope"
+	if got := strings.Join(codes(t, ValidateUpdate(UpdateUser{Name: &name, Email: &email})), ","); got != "name:required,email:invalid_format" {
+		t.Fatalf("unexpected field errors %s", got)
+	}
+}
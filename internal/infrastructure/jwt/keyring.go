@@ -0,0 +1,162 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// VerificationKey is a key that is trusted for verification only. Once
+// RetiresAt has passed, tokens signed with it are no longer accepted. A zero
+// RetiresAt keeps the key trusted until it is removed.
+type VerificationKey struct {
+	Key       SigningKey
+	RetiresAt time.Time
+	// demoted marks a key that was active until a promotion. Replace keeps
+	// it until it retires, whether or not it is still listed.
+	demoted bool
+}
+
+func (v VerificationKey) retired(now time.Time) bool {
+	return !v.RetiresAt.IsZero() && !now.Before(v.RetiresAt)
+}
+
+// Keyring holds one active signing key plus verify-only keys, so keys can be
+// rotated without invalidating tokens that are still in flight.
+type Keyring struct {
+	mu     sync.RWMutex
+	active SigningKey
+	verify map[string]VerificationKey
+}
+
+// NewKeyring builds a keyring around an active signing key.
+func NewKeyring(active SigningKey, verifyOnly ...VerificationKey) (*Keyring, error) {
+	if !active.CanSign() {
+		return nil, fmt.Errorf("active key %q cannot sign", active.ID)
+	}
+
+	ring := &Keyring{
+		active: active,
+		verify: make(map[string]VerificationKey, len(verifyOnly)),
+	}
+	for _, v := range verifyOnly {
+		if v.Key.ID == active.ID {
+			return nil, fmt.Errorf("key %q is both active and verify-only", v.Key.ID)
+		}
+		ring.verify[v.Key.ID] = v
+	}
+	return ring, nil
+}
+
+// Active returns the key used to sign new tokens.
+func (k *Keyring) Active() SigningKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.active
+}
+
+// Lookup returns the key with the given id if it is active or not yet retired
+// at now.
+func (k *Keyring) Lookup(kid string, now time.Time) (SigningKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if kid == k.active.ID {
+		return k.active, true
+	}
+	v, ok := k.verify[kid]
+	if !ok || v.retired(now) {
+		return SigningKey{}, false
+	}
+	return v.Key, true
+}
+
+// Keys returns every key trusted for verification at now, active first.
+func (k *Keyring) Keys(now time.Time) []SigningKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	ids := make([]string, 0, len(k.verify))
+	for id, v := range k.verify {
+		if !v.retired(now) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	keys := []SigningKey{k.active}
+	for _, id := range ids {
+		keys = append(keys, k.verify[id].Key)
+	}
+	return keys
+}
+
+// Promote makes key the active signing key. The previously active key stays
+// trusted for verification until previousRetiresAt.
+func (k *Keyring) Promote(key SigningKey, previousRetiresAt time.Time) error {
+	if !key.CanSign() {
+		return fmt.Errorf("key %q cannot sign", key.ID)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if key.ID == k.active.ID {
+		return errors.New("key is already active")
+	}
+	delete(k.verify, key.ID)
+	k.verify[k.active.ID] = VerificationKey{Key: k.active, RetiresAt: previousRetiresAt, demoted: true}
+	k.active = key
+	return nil
+}
+
+// AddVerificationKey trusts an additional key for verification, replacing any
+// verify-only key with the same id.
+func (k *Keyring) AddVerificationKey(v VerificationKey) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if v.Key.ID == k.active.ID {
+		return fmt.Errorf("key %q is already active", v.Key.ID)
+	}
+	k.verify[v.Key.ID] = v
+	return nil
+}
+
+// Replace makes active the signing key and trusts the verify keys besides
+// it, dropping any others. Keys demoted from active are the exception: the
+// currently active key, when it is replaced, and keys demoted earlier keep
+// verifying until they retire so the tokens they signed stay valid. The
+// current key retires at previousRetiresAt; keys already retired at now are
+// dropped.
+func (k *Keyring) Replace(active SigningKey, verify []VerificationKey, previousRetiresAt, now time.Time) error {
+	if !active.CanSign() {
+		return fmt.Errorf("key %q cannot sign", active.ID)
+	}
+	next := make(map[string]VerificationKey, len(verify)+1)
+	for _, v := range verify {
+		if v.Key.ID == active.ID {
+			return fmt.Errorf("key %q is both active and verify-only", v.Key.ID)
+		}
+		next[v.Key.ID] = v
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for id, v := range k.verify {
+		if _, listed := next[id]; !listed && v.demoted && !v.retired(now) && id != active.ID {
+			next[id] = v
+		}
+	}
+	if previous := k.active; previous.ID != active.ID {
+		if _, listed := next[previous.ID]; !listed {
+			next[previous.ID] = VerificationKey{Key: previous, RetiresAt: previousRetiresAt, demoted: true}
+		}
+	}
+	k.active = active
+	k.verify = next
+	return nil
+}
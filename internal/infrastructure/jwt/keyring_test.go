@@ -0,0 +1,179 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+)
+
+func newECKey(t *testing.T, id string) SigningKey {
+	t.Helper()
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	key, err := NewSigningKey(id, private)
+	if err != nil {
+		t.Fatalf("new signing key: %v", err)
+	}
+	return key
+}
+
+func TestManagerPromoteKeepsOldTokensValid(t *testing.T) {
+	oldKey := newECKey(t, "old")
+	ring, err := NewKeyring(oldKey)
+	if err != nil {
+		t.Fatalf("new keyring: %v", err)
+	}
+	manager := NewManagerWithKeyring(ring, time.Hour, "issuer")
+
+	oldToken, err := manager.GenerateToken("user", nil)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+
+	if err := manager.Promote(newECKey(t, "new")); err != nil {
+		t.Fatalf("promote: %v", err)
+	}
+	if ring.Active().ID != "new" {
+		t.Fatalf("expected new active key got %s", ring.Active().ID)
+	}
+
+	newToken, err := manager.GenerateToken("user", nil)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	for _, token := range []string{oldToken, newToken} {
+		if _, err := manager.ValidateToken(token); err != nil {
+			t.Fatalf("validate token: %v", err)
+		}
+	}
+	if keys := manager.JWKS().Keys; len(keys) != 2 || keys[0].KeyID != "new" {
+		t.Fatalf("expected active and retired keys in jwks got %+v", keys)
+	}
+}
+
+func TestManagerPromoteRetiresOnManagerClock(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+	ring, err := NewKeyring(newECKey(t, "old"))
+	if err != nil {
+		t.Fatalf("new keyring: %v", err)
+	}
+	manager := NewManagerWithKeyring(ring, time.Hour, "issuer", WithClock(clock))
+	oldToken, err := manager.GenerateToken("user", nil)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	if err := manager.Promote(newECKey(t, "new")); err != nil {
+		t.Fatalf("promote: %v", err)
+	}
+
+	now = now.Add(30 * time.Minute)
+	if _, err := manager.ValidateToken(oldToken); err != nil {
+		t.Fatalf("expected the demoted key to verify before it retires: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	if keys := manager.JWKS().Keys; len(keys) != 1 || keys[0].KeyID != "new" {
+		t.Fatalf("expected the demoted key to retire on the manager clock got %+v", keys)
+	}
+	if _, ok := ring.Lookup("old", now); ok {
+		t.Fatal("expected the retired key to be unknown")
+	}
+}
+
+func TestKeyringRetiredKeyRejected(t *testing.T) {
+	retiring := newECKey(t, "retiring")
+	signer := NewManagerWithKey(retiring, time.Hour, "issuer")
+	token, err := signer.GenerateToken("user", nil)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+
+	ring, err := NewKeyring(newECKey(t, "current"), VerificationKey{Key: retiring, RetiresAt: time.Now().Add(-time.Minute)})
+	if err != nil {
+		t.Fatalf("new keyring: %v", err)
+	}
+	manager := NewManagerWithKeyring(ring, time.Hour, "issuer")
+	if _, err := manager.ValidateToken(token); err == nil {
+		t.Fatal("expected token signed by retired key to be rejected")
+	}
+	if keys := manager.JWKS().Keys; len(keys) != 1 {
+		t.Fatalf("expected retired key to be unpublished got %+v", keys)
+	}
+
+	if err := ring.AddVerificationKey(VerificationKey{Key: retiring, RetiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("add verification key: %v", err)
+	}
+	if _, err := manager.ValidateToken(token); err != nil {
+		t.Fatalf("expected token to validate before retirement: %v", err)
+	}
+}
+
+func TestKeyringRequiresSigningKey(t *testing.T) {
+	key := newECKey(t, "k1")
+	verifyOnly, err := NewVerificationKey("k2", key.verifyKey)
+	if err != nil {
+		t.Fatalf("new verification key: %v", err)
+	}
+
+	if _, err := NewKeyring(verifyOnly); err == nil {
+		t.Fatal("expected error for verify-only active key")
+	}
+	ring, err := NewKeyring(key)
+	if err != nil {
+		t.Fatalf("new keyring: %v", err)
+	}
+	if err := ring.Promote(verifyOnly, time.Time{}); err == nil {
+		t.Fatal("expected error promoting verify-only key")
+	}
+	if err := ring.Promote(key, time.Time{}); err == nil {
+		t.Fatal("expected error promoting the active key")
+	}
+}
+
+func TestManagerReloadDropsRemovedKeys(t *testing.T) {
+	leaked, current := newECKey(t, "leaked"), newECKey(t, "current")
+	leakedToken, err := NewManagerWithKey(leaked, time.Hour, "issuer").GenerateToken("user", nil)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	ring, err := NewKeyring(current, VerificationKey{Key: leaked})
+	if err != nil {
+		t.Fatalf("new keyring: %v", err)
+	}
+	manager := NewManagerWithKeyring(ring, time.Hour, "issuer")
+	if _, err := manager.ValidateToken(leakedToken); err != nil {
+		t.Fatalf("expected the listed key to verify: %v", err)
+	}
+	currentToken, err := manager.GenerateToken("user", nil)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+
+	// The file now lists only the next key, so the leaked one is revoked and
+	// the demoted one retires after a token lifetime.
+	next := newECKey(t, "next")
+	if err := manager.Reload(next, nil); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if _, err := manager.ValidateToken(leakedToken); err == nil {
+		t.Fatal("expected a key removed from the keyring to stop verifying")
+	}
+	if _, err := manager.ValidateToken(currentToken); err != nil {
+		t.Fatalf("expected the demoted key to keep verifying: %v", err)
+	}
+	if keys := manager.JWKS().Keys; len(keys) != 2 || keys[0].KeyID != "next" || keys[1].KeyID != "current" {
+		t.Fatalf("expected the next and demoted keys in jwks got %+v", keys)
+	}
+
+	if err := manager.Reload(next, nil); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if _, err := manager.ValidateToken(currentToken); err != nil {
+		t.Fatalf("expected the demoted key to survive another reload until it retires: %v", err)
+	}
+}
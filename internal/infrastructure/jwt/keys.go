@@ -0,0 +1,204 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const defaultHMACKeyID = "default"
+
+// SigningKey is a keyed JWT signing method. Symmetric keys hold the shared
+// secret on both sides; asymmetric keys keep the private half for signing and
+// the public half for verification and publication. Keys loaded from a public
+// key only can verify but never sign.
+type SigningKey struct {
+	ID     string
+	Method jwt.SigningMethod
+
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// NewHMACKey builds an HS256 key from a shared secret.
+func NewHMACKey(id string, secret []byte) SigningKey {
+	if id == "" {
+		id = defaultHMACKeyID
+	}
+	return SigningKey{
+		ID:        id,
+		Method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+	}
+}
+
+// NewSigningKey builds a key from an RSA, ECDSA, or Ed25519 private key. The
+// algorithm is derived from the key type. When id is empty the RFC 7638
+// thumbprint of the public key is used.
+func NewSigningKey(id string, private crypto.Signer) (SigningKey, error) {
+	method, err := methodForKey(private.Public())
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	key := SigningKey{
+		ID:        id,
+		Method:    method,
+		signKey:   private,
+		verifyKey: private.Public(),
+	}
+	if key.ID == "" {
+		thumbprint, err := key.thumbprint()
+		if err != nil {
+			return SigningKey{}, err
+		}
+		key.ID = thumbprint
+	}
+	return key, nil
+}
+
+// NewVerificationKey builds a verify-only key from an RSA, ECDSA, or Ed25519
+// public key.
+func NewVerificationKey(id string, public crypto.PublicKey) (SigningKey, error) {
+	method, err := methodForKey(public)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	key := SigningKey{
+		ID:        id,
+		Method:    method,
+		verifyKey: public,
+	}
+	if key.ID == "" {
+		thumbprint, err := key.thumbprint()
+		if err != nil {
+			return SigningKey{}, err
+		}
+		key.ID = thumbprint
+	}
+	return key, nil
+}
+
+// LoadSigningKeyPEM parses a PEM encoded PKCS#8, PKCS#1, or SEC 1 private key.
+func LoadSigningKeyPEM(id string, data []byte) (SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return SigningKey{}, errors.New("no PEM block found")
+	}
+
+	var (
+		parsed interface{}
+		err    error
+	)
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return SigningKey{}, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("parse private key: %w", err)
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return SigningKey{}, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+	return NewSigningKey(id, signer)
+}
+
+// LoadSigningKeyFile reads a PEM private key from disk.
+func LoadSigningKeyFile(id, path string) (SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("read signing key: %w", err)
+	}
+	return LoadSigningKeyPEM(id, data)
+}
+
+// LoadVerificationKeyPEM parses a PEM encoded PKIX or PKCS#1 public key. A
+// private key is accepted too, in which case only its public half is kept.
+func LoadVerificationKeyPEM(id string, data []byte) (SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return SigningKey{}, errors.New("no PEM block found")
+	}
+
+	var (
+		parsed interface{}
+		err    error
+	)
+	switch block.Type {
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		key, err := LoadSigningKeyPEM(id, data)
+		if err != nil {
+			return SigningKey{}, err
+		}
+		key.signKey = nil
+		return key, nil
+	}
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("parse public key: %w", err)
+	}
+	return NewVerificationKey(id, parsed)
+}
+
+// LoadVerificationKeyFile reads a PEM public (or private) key from disk.
+func LoadVerificationKeyFile(id, path string) (SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("read verification key: %w", err)
+	}
+	return LoadVerificationKeyPEM(id, data)
+}
+
+// CanSign reports whether the key holds signing material.
+func (k SigningKey) CanSign() bool {
+	return k.signKey != nil
+}
+
+// Asymmetric reports whether the key can be published in a JWKS.
+func (k SigningKey) Asymmetric() bool {
+	_, symmetric := k.verifyKey.([]byte)
+	return !symmetric
+}
+
+func methodForKey(public crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			return jwt.SigningMethodES256, nil
+		case elliptic.P384():
+			return jwt.SigningMethodES384, nil
+		case elliptic.P521():
+			return jwt.SigningMethodES512, nil
+		}
+		return nil, fmt.Errorf("unsupported ECDSA curve %s", pub.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", public)
+	}
+}
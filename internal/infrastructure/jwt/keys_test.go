@@ -0,0 +1,153 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func generateKeys(t *testing.T) map[string]crypto.Signer {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ecdsa key: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ed25519 key: %v", err)
+	}
+	return map[string]crypto.Signer{"RS256": rsaKey, "ES256": ecKey, "EdDSA": edKey}
+}
+
+func TestAsymmetricSigningRoundTrip(t *testing.T) {
+	for alg, private := range generateKeys(t) {
+		t.Run(alg, func(t *testing.T) {
+			der, err := x509.MarshalPKCS8PrivateKey(private)
+			if err != nil {
+				t.Fatalf("marshal key: %v", err)
+			}
+			key, err := LoadSigningKeyPEM("", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+			if err != nil {
+				t.Fatalf("load key: %v", err)
+			}
+			if key.Method.Alg() != alg {
+				t.Fatalf("expected alg %s got %s", alg, key.Method.Alg())
+			}
+			if key.ID == "" {
+				t.Fatal("expected thumbprint key id")
+			}
+
+			manager := NewManagerWithKey(key, time.Hour, "issuer")
+			token, err := manager.GenerateToken("user", nil)
+			if err != nil {
+				t.Fatalf("generate token: %v", err)
+			}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+			if err != nil {
+				t.Fatalf("parse unverified: %v", err)
+			}
+			if parsed.Header["kid"] != key.ID {
+				t.Fatalf("expected kid %s got %v", key.ID, parsed.Header["kid"])
+			}
+
+			subject, err := manager.ValidateToken(token)
+			if err != nil || subject != "user" {
+				t.Fatalf("validate token: %v %s", err, subject)
+			}
+
+			jwks := manager.JWKS()
+			if len(jwks.Keys) != 1 || jwks.Keys[0].KeyID != key.ID || jwks.Keys[0].Algorithm != alg {
+				t.Fatalf("unexpected jwks %+v", jwks)
+			}
+		})
+	}
+}
+
+func TestLoadSigningKeyLegacyPEM(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	key, err := LoadSigningKeyPEM("rsa-1", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+	if err != nil || key.ID != "rsa-1" || key.Method != jwt.SigningMethodRS256 {
+		t.Fatalf("load pkcs1 key: %v %+v", err, key)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ecdsa key: %v", err)
+	}
+	der, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatalf("marshal ec key: %v", err)
+	}
+	key, err = LoadSigningKeyPEM("ec-1", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+	if err != nil || key.Method != jwt.SigningMethodES384 {
+		t.Fatalf("load sec1 key: %v %+v", err, key)
+	}
+
+	if _, err := LoadSigningKeyPEM("", []byte("not pem")); err == nil {
+		t.Fatal("expected error for invalid PEM")
+	}
+}
+
+func TestParseTokenRejectsAlgorithmConfusion(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	key, err := NewSigningKey("rsa", rsaKey)
+	if err != nil {
+		t.Fatalf("new signing key: %v", err)
+	}
+	manager := NewManagerWithKey(key, time.Hour, "issuer")
+
+	// Sign with HS256 using the published public key as the HMAC secret.
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   "attacker",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	forged.Header["kid"] = "rsa"
+	str, err := forged.SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	if err != nil {
+		t.Fatalf("sign forged token: %v", err)
+	}
+	if _, err := manager.ValidateToken(str); err == nil {
+		t.Fatal("expected HS256 token to be rejected for an RSA key")
+	}
+
+	unknown := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{Subject: "user"})
+	unknown.Header["kid"] = "other"
+	str, err = unknown.SignedString(rsaKey)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	if _, err := manager.ValidateToken(str); err == nil {
+		t.Fatal("expected unknown kid to be rejected")
+	}
+}
+
+func TestJWKSExcludesSymmetricKeys(t *testing.T) {
+	manager := NewManager("secret", time.Hour, "issuer")
+	if keys := manager.JWKS().Keys; len(keys) != 0 {
+		t.Fatalf("expected no published keys got %d", len(keys))
+	}
+}
@@ -1,55 +1,218 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// accessClaims are the claims of an access token: the registered ones plus the
+// user's roles. iat and exp shadow the registered ones to keep millisecond
+// precision; see claimTime.
+type accessClaims struct {
+	jwt.RegisteredClaims
+	IssuedAt  *claimTime    `json:"iat,omitempty"`
+	ExpiresAt *claimTime    `json:"exp,omitempty"`
+	Roles     []domain.Role `json:"roles,omitempty"`
+}
+
+// GetIssuedAt lets the library validate the millisecond iat claim.
+func (c accessClaims) GetIssuedAt() (*jwt.NumericDate, error) {
+	return c.IssuedAt.numericDate(), nil
+}
+
+// GetExpirationTime lets the library validate the millisecond exp claim.
+func (c accessClaims) GetExpirationTime() (*jwt.NumericDate, error) {
+	return c.ExpiresAt.numericDate(), nil
+}
+
+// claimTime is a NumericDate claim with millisecond precision. Revocation
+// cutoffs are compared against iat, so whole seconds would reject tokens
+// minted right after a logout. The library only keeps sub-second precision
+// through its package-wide TimePrecision, which would change every other user
+// of it in the binary.
+type claimTime struct {
+	time.Time
+}
+
+func newClaimTime(t time.Time) *claimTime {
+	return &claimTime{t.Truncate(time.Millisecond)}
+}
+
+func (t *claimTime) numericDate() *jwt.NumericDate {
+	if t == nil {
+		return nil
+	}
+	return &jwt.NumericDate{Time: t.Time}
+}
+
+// MarshalJSON writes the time as seconds since the epoch with three decimals.
+func (t claimTime) MarshalJSON() ([]byte, error) {
+	ms := t.Truncate(time.Millisecond).Nanosecond() / int(time.Millisecond)
+	return []byte(fmt.Sprintf("%d.%03d", t.Unix(), ms)), nil
+}
+
+// UnmarshalJSON reads seconds since the epoch, with or without a fraction. The
+// fraction is parsed as digits rather than as a float, which could round a
+// millisecond down.
+func (t *claimTime) UnmarshalJSON(b []byte) error {
+	var number json.Number
+	if err := json.Unmarshal(b, &number); err != nil {
+		return fmt.Errorf("could not parse NumericDate: %w", err)
+	}
+	if strings.ContainsAny(number.String(), "eE") {
+		f, err := number.Float64()
+		if err != nil {
+			return fmt.Errorf("could not parse NumericDate: %w", err)
+		}
+		t.Time = time.UnixMilli(int64(math.Round(f * 1000)))
+		return nil
+	}
+	whole, frac, _ := strings.Cut(number.String(), ".")
+	seconds, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return fmt.Errorf("could not parse NumericDate: %w", err)
+	}
+	var nanos int64
+	if frac != "" {
+		frac = (frac + "000000000")[:9]
+		if nanos, err = strconv.ParseInt(frac, 10, 64); err != nil {
+			return fmt.Errorf("could not parse NumericDate: %w", err)
+		}
+	}
+	t.Time = time.Unix(seconds, nanos).Truncate(time.Millisecond)
+	return nil
+}
+
 // Manager handles JWT generation and validation.
 type Manager struct {
-	secret     []byte
+	keyring    *Keyring
 	expiration time.Duration
 	issuer     string
+	now        func() time.Time
+}
+
+// ManagerOption customises a Manager.
+type ManagerOption func(*Manager)
+
+// WithClock sets the clock tokens are stamped and checked against, which also
+// decides when demoted keys retire. It defaults to time.Now.
+func WithClock(now func() time.Time) ManagerOption {
+	return func(m *Manager) {
+		m.now = now
+	}
+}
+
+// NewManager creates a JWT manager that signs with an HS256 shared secret.
+func NewManager(secret string, expiration time.Duration, issuer string, opts ...ManagerOption) *Manager {
+	return NewManagerWithKey(NewHMACKey("", []byte(secret)), expiration, issuer, opts...)
+}
+
+// NewManagerWithKey creates a JWT manager that signs with the given key.
+func NewManagerWithKey(key SigningKey, expiration time.Duration, issuer string, opts ...ManagerOption) *Manager {
+	return NewManagerWithKeyring(&Keyring{active: key, verify: map[string]VerificationKey{}}, expiration, issuer, opts...)
 }
 
-// NewManager creates a JWT manager.
-func NewManager(secret string, expiration time.Duration, issuer string) *Manager {
-	return &Manager{
-		secret:     []byte(secret),
+// NewManagerWithKeyring creates a JWT manager backed by a rotatable keyring.
+func NewManagerWithKeyring(keyring *Keyring, expiration time.Duration, issuer string, opts ...ManagerOption) *Manager {
+	m := &Manager{
+		keyring:    keyring,
 		expiration: expiration,
 		issuer:     issuer,
+		now:        time.Now,
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Keyring exposes the manager's keys for rotation.
+func (m *Manager) Keyring() *Keyring {
+	return m.keyring
+}
+
+// Promote switches signing to key. The previous key keeps verifying for one
+// token lifetime, so every token it signed can still be used until it expires.
+func (m *Manager) Promote(key SigningKey) error {
+	return m.keyring.Promote(key, m.now().Add(m.expiration))
+}
+
+// Reload replaces the keyring with freshly loaded keys; see Keyring.Replace.
+// Keys that are no longer listed stop verifying at once, except the key that
+// was active until now, which keeps verifying for one token lifetime.
+func (m *Manager) Reload(active SigningKey, verify []VerificationKey) error {
+	now := m.now()
+	return m.keyring.Replace(active, verify, now.Add(m.expiration), now)
 }
 
-// GenerateToken issues a signed JWT with the user ID as subject.
-func (m *Manager) GenerateToken(userID string) (string, error) {
-	now := time.Now().UTC()
-	claims := jwt.RegisteredClaims{
-		Subject:   userID,
-		Issuer:    m.issuer,
-		IssuedAt:  jwt.NewNumericDate(now),
-		ExpiresAt: jwt.NewNumericDate(now.Add(m.expiration)),
+// GenerateToken issues a signed JWT with the user ID as subject, the user's
+// roles, and a unique token ID (jti) that can be revoked.
+func (m *Manager) GenerateToken(userID string, roles []domain.Role) (string, error) {
+	tokenID, err := newTokenID()
+	if err != nil {
+		return "", err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(m.secret)
+	now := m.now().UTC()
+	claims := accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:      tokenID,
+			Subject: userID,
+			Issuer:  m.issuer,
+		},
+		IssuedAt:  newClaimTime(now),
+		ExpiresAt: newClaimTime(now.Add(m.expiration)),
+		Roles:     roles,
+	}
+
+	signing := m.keyring.Active()
+	token := jwt.NewWithClaims(signing.Method, claims)
+	token.Header["kid"] = signing.ID
+	return token.SignedString(signing.signKey)
 }
 
-// ParseToken validates and returns JWT claims.
+// ParseToken validates and returns JWT claims. The verification key is chosen
+// by the kid header, and the token's alg must match that key's algorithm so a
+// public key can never be used as an HMAC secret.
 func (m *Manager) ParseToken(tokenString string) (*jwt.RegisteredClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if token.Method != jwt.SigningMethodHS256 {
+	claims, err := m.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	registered := claims.RegisteredClaims
+	registered.IssuedAt = claims.IssuedAt.numericDate()
+	registered.ExpiresAt = claims.ExpiresAt.numericDate()
+	return &registered, nil
+}
+
+func (m *Manager) parse(tokenString string) (*accessClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &accessClaims{}, func(token *jwt.Token) (interface{}, error) {
+		key, err := m.keyFor(token)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != key.Method.Alg() {
 			return nil, errors.New("unexpected signing method")
 		}
-		return m.secret, nil
-	})
+		return key.verifyKey, nil
+	}, jwt.WithTimeFunc(m.now))
 	if err != nil {
 		return nil, err
 	}
 
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
+	claims, ok := token.Claims.(*accessClaims)
 	if !ok || !token.Valid {
 		return nil, errors.New("invalid token")
 	}
@@ -58,16 +221,86 @@ func (m *Manager) ParseToken(tokenString string) (*jwt.RegisteredClaims, error)
 
 // ValidateToken returns the subject (user id) if token is valid.
 func (m *Manager) ValidateToken(token string) (string, error) {
-	claims, err := m.ParseToken(token)
+	claims, err := m.validate(token)
 	if err != nil {
 		return "", err
 	}
-	now := time.Now().UTC()
+	return claims.Subject, nil
+}
+
+// VerifyToken validates a token and returns the claims the application relies on.
+func (m *Manager) VerifyToken(token string) (application.AccessClaims, error) {
+	claims, err := m.validate(token)
+	if err != nil {
+		return application.AccessClaims{}, err
+	}
+
+	verified := application.AccessClaims{
+		UserID:  claims.Subject,
+		Roles:   claims.Roles,
+		TokenID: claims.ID,
+	}
+	if claims.IssuedAt != nil {
+		verified.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		verified.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return verified, nil
+}
+
+// JWKS returns the public keys that downstream services can use to verify
+// tokens. Symmetric keys are never published.
+func (m *Manager) JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range m.keyring.Keys(m.now()) {
+		if !key.Asymmetric() {
+			continue
+		}
+		if jwk, err := key.JWK(); err == nil {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+// keyFor resolves the verification key for a token. Tokens minted before kid
+// headers were introduced are checked against the active key.
+func (m *Manager) keyFor(token *jwt.Token) (SigningKey, error) {
+	raw, present := token.Header["kid"]
+	if !present {
+		return m.keyring.Active(), nil
+	}
+	kid, ok := raw.(string)
+	if !ok {
+		return SigningKey{}, errors.New("invalid kid header")
+	}
+	key, ok := m.keyring.Lookup(kid, m.now())
+	if !ok {
+		return SigningKey{}, fmt.Errorf("unknown or retired key id %q", kid)
+	}
+	return key, nil
+}
+
+func (m *Manager) validate(token string) (*accessClaims, error) {
+	claims, err := m.parse(token)
+	if err != nil {
+		return nil, err
+	}
+	now := m.now().UTC()
 	if claims.ExpiresAt != nil && now.After(claims.ExpiresAt.Time) {
-		return "", errors.New("token expired")
+		return nil, errors.New("token expired")
 	}
 	if claims.NotBefore != nil && now.Before(claims.NotBefore.Time) {
-		return "", errors.New("token not yet valid")
+		return nil, errors.New("token not yet valid")
 	}
-	return claims.Subject, nil
+	return claims, nil
+}
+
+func newTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
 }
@@ -1,16 +1,19 @@
 package jwt
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
+	"backend-challenge/internal/domain"
+
 	"github.com/golang-jwt/jwt/v5"
 )
 
 func TestGenerateAndValidateToken(t *testing.T) {
 	manager := NewManager("secret", time.Hour, "issuer")
 
-	token, err := manager.GenerateToken("user-id")
+	token, err := manager.GenerateToken("user-id", nil)
 	if err != nil {
 		t.Fatalf("generate token: %v", err)
 	}
@@ -24,6 +27,23 @@ func TestGenerateAndValidateToken(t *testing.T) {
 	}
 }
 
+func TestVerifyTokenCarriesRoles(t *testing.T) {
+	manager := NewManager("secret", time.Hour, "issuer")
+
+	token, err := manager.GenerateToken("user-id", []domain.Role{domain.RoleUser, domain.RoleAdmin})
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+
+	claims, err := manager.VerifyToken(token)
+	if err != nil {
+		t.Fatalf("verify token: %v", err)
+	}
+	if len(claims.Roles) != 2 || claims.Roles[1] != domain.RoleAdmin {
+		t.Fatalf("unexpected roles %v", claims.Roles)
+	}
+}
+
 func TestValidateTokenErrors(t *testing.T) {
 	manager := NewManager("secret", time.Second, "issuer")
 
@@ -34,7 +54,7 @@ func TestValidateTokenErrors(t *testing.T) {
 
 	// expired token
 	short := NewManager("secret", -time.Second, "issuer")
-	token, err := short.GenerateToken("user")
+	token, err := short.GenerateToken("user", nil)
 	if err != nil {
 		t.Fatalf("generate token: %v", err)
 	}
@@ -60,3 +80,75 @@ func TestValidateTokenNotYetValid(t *testing.T) {
 		t.Fatalf("expected not yet valid error")
 	}
 }
+
+func TestVerifyTokenIncludesTokenID(t *testing.T) {
+	manager := NewManager("secret", time.Hour, "issuer")
+
+	first, err := manager.GenerateToken("user", nil)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	second, err := manager.GenerateToken("user", nil)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+
+	a, err := manager.VerifyToken(first)
+	if err != nil {
+		t.Fatalf("verify token: %v", err)
+	}
+	b, err := manager.VerifyToken(second)
+	if err != nil {
+		t.Fatalf("verify token: %v", err)
+	}
+	if a.TokenID == "" || a.TokenID == b.TokenID {
+		t.Fatalf("expected unique token ids got %q and %q", a.TokenID, b.TokenID)
+	}
+	if a.UserID != "user" || a.IssuedAt.IsZero() || a.ExpiresAt.IsZero() {
+		t.Fatalf("unexpected claims %+v", a)
+	}
+}
+
+func TestTokenTimesKeepMilliseconds(t *testing.T) {
+	if jwt.TimePrecision != time.Second {
+		t.Fatalf("expected the library precision to be left alone got %s", jwt.TimePrecision)
+	}
+	manager := NewManager("secret", time.Hour, "issuer")
+
+	before := time.Now().Truncate(time.Millisecond)
+	token, err := manager.GenerateToken("user", nil)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	claims, err := manager.VerifyToken(token)
+	if err != nil {
+		t.Fatalf("verify token: %v", err)
+	}
+	if claims.IssuedAt.Before(before) || claims.IssuedAt.After(time.Now()) {
+		t.Fatalf("expected iat to keep millisecond precision got %s not after %s", claims.IssuedAt, before)
+	}
+	if !claims.ExpiresAt.Equal(claims.IssuedAt.Add(time.Hour)) {
+		t.Fatalf("expected exp one hour after iat got %s and %s", claims.ExpiresAt, claims.IssuedAt)
+	}
+}
+
+func TestClaimTimeRoundTrip(t *testing.T) {
+	want := time.UnixMilli(1700000000123)
+	raw, err := json.Marshal(newClaimTime(want))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(raw) != "1700000000.123" {
+		t.Fatalf("unexpected encoding %s", raw)
+	}
+	for _, in := range []string{"1700000000.123", "1700000000.1239", "1.700000000123e9"} {
+		var got claimTime
+		if err := json.Unmarshal([]byte(in), &got); err != nil || !got.Equal(want) {
+			t.Fatalf("%s: expected %s got %s, %v", in, want, got.Time, err)
+		}
+	}
+	var whole claimTime
+	if err := json.Unmarshal([]byte("1700000000"), &whole); err != nil || !whole.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("expected whole seconds to parse got %s, %v", whole.Time, err)
+	}
+}
@@ -0,0 +1,71 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/mail"
+	"os"
+	"sync"
+	"time"
+
+	"backend-challenge/internal/application"
+)
+
+const logSeparator = "----------------------------------------\n"
+
+// LogMailer writes each message to a writer instead of delivering it. It is
+// meant for development, where reading a link from the console or a file is
+// enough.
+type LogMailer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from *mail.Address
+}
+
+// NewLogMailer builds a mailer that writes to w.
+func NewLogMailer(w io.Writer, from string) (*LogMailer, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender: %w", err)
+	}
+	return &LogMailer{w: w, from: sender}, nil
+}
+
+// OpenFileMailer builds a mailer that appends to the file at path.
+func OpenFileMailer(path, from string) (*LogMailer, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open mail log: %w", err)
+	}
+	mailer, err := NewLogMailer(file, from)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return mailer, nil
+}
+
+// Send writes the formatted message followed by a separator line.
+func (m *LogMailer) Send(ctx context.Context, msg application.Message) error {
+	data, err := formatMessage(m.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, err := m.w.Write(data); err != nil {
+		return err
+	}
+	_, err = io.WriteString(m.w, "\r\n"+logSeparator)
+	return err
+}
+
+// Close closes the underlying writer when it is closable.
+func (m *LogMailer) Close() error {
+	if closer, ok := m.w.(io.Closer); ok && m.w != os.Stdout && m.w != os.Stderr {
+		return closer.Close()
+	}
+	return nil
+}
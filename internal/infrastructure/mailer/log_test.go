@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"backend-challenge/internal/application"
+)
+
+func TestLogMailerWritesMessage(t *testing.T) {
+	var buf bytes.Buffer
+	mailer, err := NewLogMailer(&buf, "no-reply@example.com")
+	if err != nil {
+		t.Fatalf("new mailer: %v", err)
+	}
+
+	if err := mailer.Send(context.Background(), application.Message{To: "jane@example.com", Subject: "Hello", Body: "token: abc\n"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "To: <jane@example.com>\r\n") || !strings.Contains(out, "token: abc\r\n") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+
+	err = mailer.Send(context.Background(), application.Message{To: "jane@example.com", Subject: "Hi\r\nBcc: evil@example.com", Body: "x"})
+	if err == nil {
+		t.Fatal("expected header injection to be rejected")
+	}
+}
+
+func TestOpenFileMailerAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	mailer, err := OpenFileMailer(path, "no-reply@example.com")
+	if err != nil {
+		t.Fatalf("open mailer: %v", err)
+	}
+	for _, to := range []string{"a@example.com", "b@example.com"} {
+		if err := mailer.Send(context.Background(), application.Message{To: to, Subject: "Hi", Body: "body"}); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+	if err := mailer.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	if strings.Count(string(data), logSeparator) != 2 {
+		t.Fatalf("expected two messages:\n%s", data)
+	}
+}
@@ -0,0 +1,128 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"backend-challenge/internal/application"
+)
+
+const defaultSMTPTimeout = 10 * time.Second
+
+// SMTPConfig describes the relay used to deliver mail.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+// SMTPMailer delivers messages through an SMTP relay. STARTTLS is used when
+// the server offers it, and credentials are only sent over TLS or to a
+// loopback server.
+type SMTPMailer struct {
+	addr    string
+	host    string
+	from    *mail.Address
+	auth    smtp.Auth
+	timeout time.Duration
+}
+
+// NewSMTPMailer builds a mailer for the configured relay.
+func NewSMTPMailer(cfg SMTPConfig) (*SMTPMailer, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("smtp host is required")
+	}
+	sender, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender: %w", err)
+	}
+
+	mailer := &SMTPMailer{
+		addr:    net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		host:    cfg.Host,
+		from:    sender,
+		timeout: cfg.Timeout,
+	}
+	if mailer.timeout <= 0 {
+		mailer.timeout = defaultSMTPTimeout
+	}
+	if cfg.Username != "" {
+		mailer.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return mailer, nil
+}
+
+// Send delivers msg to its recipient.
+func (m *SMTPMailer) Send(ctx context.Context, msg application.Message) error {
+	data, err := formatMessage(m.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+
+	dialer := net.Dialer{Timeout: m.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return fmt.Errorf("dial smtp: %w", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(m.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		return fmt.Errorf("smtp handshake: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	if m.auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp server does not support authentication")
+		}
+		if err := client.Auth(m.auth); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(m.from.Address); err != nil {
+		return fmt.Errorf("smtp mail from: %w", err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return fmt.Errorf("smtp rcpt to: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("smtp write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	return client.Quit()
+}
@@ -0,0 +1,173 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"backend-challenge/internal/application"
+)
+
+// fakeSMTPServer accepts a single connection and records the envelope and
+// message it receives. Commands listed in reject are answered with 550.
+type fakeSMTPServer struct {
+	listener net.Listener
+	reject   map[string]bool
+	done     chan struct{}
+
+	from string
+	rcpt string
+	data string
+}
+
+func startFakeSMTPServer(t *testing.T, reject ...string) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := &fakeSMTPServer{listener: listener, reject: map[string]bool{}, done: make(chan struct{})}
+	for _, cmd := range reject {
+		server.reject[cmd] = true
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go server.serve()
+	return server
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	_ = text.PrintfLine("220 fake.test ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		if strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:") {
+			cmd = "MAIL"
+		}
+		if s.reject[cmd] {
+			_ = text.PrintfLine("550 rejected")
+			continue
+		}
+
+		switch cmd {
+		case "EHLO", "HELO":
+			_ = text.PrintfLine("250-fake.test")
+			_ = text.PrintfLine("250 8BITMIME")
+		case "MAIL":
+			s.from = line
+			_ = text.PrintfLine("250 ok")
+		case "RCPT":
+			s.rcpt = line
+			_ = text.PrintfLine("250 ok")
+		case "DATA":
+			_ = text.PrintfLine("354 go ahead")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.data = string(data)
+			_ = text.PrintfLine("250 queued")
+		case "QUIT":
+			_ = text.PrintfLine("221 bye")
+			return
+		default:
+			_ = text.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	server := startFakeSMTPServer(t)
+	mailer, err := NewSMTPMailer(SMTPConfig{Host: "127.0.0.1", Port: server.port(), From: "Backend <no-reply@example.com>"})
+	if err != nil {
+		t.Fatalf("new mailer: %v", err)
+	}
+
+	err = mailer.Send(context.Background(), application.Message{
+		To:      "jane@example.com",
+		Subject: "Reset your password",
+		Body:    "line one\nline two\n",
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	<-server.done
+
+	if !strings.HasPrefix(server.from, "MAIL FROM:<no-reply@example.com>") {
+		t.Fatalf("unexpected envelope sender %q", server.from)
+	}
+	if server.rcpt != "RCPT TO:<jane@example.com>" {
+		t.Fatalf("unexpected envelope recipient %q", server.rcpt)
+	}
+	for _, want := range []string{
+		"From: \"Backend\" <no-reply@example.com>\n",
+		"To: <jane@example.com>\n",
+		"Subject: Reset your password\n",
+		"\nline one\nline two\n",
+	} {
+		if !strings.Contains(server.data, want) {
+			t.Fatalf("message missing %q:\n%s", want, server.data)
+		}
+	}
+}
+
+func TestSMTPMailerRejectedRecipient(t *testing.T) {
+	server := startFakeSMTPServer(t, "RCPT")
+	mailer, err := NewSMTPMailer(SMTPConfig{Host: "127.0.0.1", Port: server.port(), From: "no-reply@example.com"})
+	if err != nil {
+		t.Fatalf("new mailer: %v", err)
+	}
+
+	err = mailer.Send(context.Background(), application.Message{To: "jane@example.com", Subject: "Hi", Body: "body"})
+	if err == nil || !strings.Contains(err.Error(), "rcpt") {
+		t.Fatalf("expected rcpt error got %v", err)
+	}
+}
+
+func TestSMTPMailerRequiresAuthSupport(t *testing.T) {
+	server := startFakeSMTPServer(t)
+	mailer, err := NewSMTPMailer(SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     server.port(),
+		Username: "user",
+		Password: "pass",
+		From:     "no-reply@example.com",
+	})
+	if err != nil {
+		t.Fatalf("new mailer: %v", err)
+	}
+
+	err = mailer.Send(context.Background(), application.Message{To: "jane@example.com", Subject: "Hi", Body: "body"})
+	if err == nil || !strings.Contains(err.Error(), "authentication") {
+		t.Fatalf("expected auth support error got %v", err)
+	}
+}
+
+func TestNewSMTPMailerValidatesConfig(t *testing.T) {
+	if _, err := NewSMTPMailer(SMTPConfig{From: "no-reply@example.com"}); err == nil {
+		t.Fatal("expected error for missing host")
+	}
+	if _, err := NewSMTPMailer(SMTPConfig{Host: "localhost", Port: 25, From: "not an address"}); err == nil {
+		t.Fatal("expected error for invalid sender")
+	}
+	if _, err := NewSMTPMailer(SMTPConfig{Host: "localhost", Port: 25, From: "a@example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
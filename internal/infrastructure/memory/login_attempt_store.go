@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"backend-challenge/internal/application"
+)
+
+// LoginAttemptStore is an in-memory implementation for tests and single-node
+// deployments.
+type LoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]application.LoginAttempts
+}
+
+// NewLoginAttemptStore builds an empty store.
+func NewLoginAttemptStore() *LoginAttemptStore {
+	return &LoginAttemptStore{attempts: make(map[string]application.LoginAttempts)}
+}
+
+func (s *LoginAttemptStore) RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (application.LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record := s.attempts[key]
+	if at.Sub(record.LastFailure) > window {
+		record = application.LoginAttempts{}
+	}
+	record.Failures++
+	record.PreviousFailure = record.LastFailure
+	record.LastFailure = at
+	s.attempts[key] = record
+	return record, nil
+}
+
+func (s *LoginAttemptStore) Release(ctx context.Context, key string, recorded application.LoginAttempts) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.attempts[key]
+	if !ok {
+		return nil
+	}
+	if record.Failures == recorded.Failures && record.LastFailure.Equal(recorded.LastFailure) {
+		record.LastFailure = recorded.PreviousFailure
+		record.PreviousFailure = time.Time{}
+	}
+	if record.Failures--; record.Failures <= 0 {
+		delete(s.attempts, key)
+		return nil
+	}
+	s.attempts[key] = record
+	return nil
+}
+
+func (s *LoginAttemptStore) Get(ctx context.Context, key string) (application.LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.attempts[key], nil
+}
+
+func (s *LoginAttemptStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend-challenge/internal/application"
+)
+
+func TestLoginAttemptStore_RecordAndReset(t *testing.T) {
+	store := NewLoginAttemptStore()
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		if _, err := store.RecordFailure(ctx, "k", start.Add(time.Duration(i)*time.Minute), time.Hour); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+	got, _ := store.Get(ctx, "k")
+	if got.Failures != 3 || !got.LastFailure.Equal(start.Add(2*time.Minute)) {
+		t.Fatalf("unexpected attempts %+v", got)
+	}
+
+	got, _ = store.RecordFailure(ctx, "k", start.Add(3*time.Hour), time.Hour)
+	if got.Failures != 1 {
+		t.Fatalf("expected count to restart after the window got %d", got.Failures)
+	}
+
+	if err := store.Reset(ctx, "k"); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if got, _ := store.Get(ctx, "k"); got.Failures != 0 {
+		t.Fatalf("expected no attempts after reset got %+v", got)
+	}
+}
+
+func TestLoginAttemptStore_Release(t *testing.T) {
+	store := NewLoginAttemptStore()
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, _ = store.RecordFailure(ctx, "k", start, time.Hour)
+	second, _ := store.RecordFailure(ctx, "k", start.Add(time.Minute), time.Hour)
+	if !second.PreviousFailure.Equal(start) {
+		t.Fatalf("expected previous failure %v got %+v", start, second)
+	}
+
+	if err := store.Release(ctx, "k", second); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if got, _ := store.Get(ctx, "k"); got.Failures != 1 || !got.LastFailure.Equal(start) {
+		t.Fatalf("expected the record to rewind to the first failure got %+v", got)
+	}
+
+	third, _ := store.RecordFailure(ctx, "k", start.Add(2*time.Minute), time.Hour)
+	_, _ = store.RecordFailure(ctx, "k", start.Add(3*time.Minute), time.Hour)
+	if err := store.Release(ctx, "k", third); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if got, _ := store.Get(ctx, "k"); got.Failures != 2 || !got.LastFailure.Equal(start.Add(3*time.Minute)) {
+		t.Fatalf("expected only the count to drop after a later failure got %+v", got)
+	}
+
+	_ = store.Reset(ctx, "k")
+	first, _ := store.RecordFailure(ctx, "k", start, time.Hour)
+	if err := store.Release(ctx, "k", first); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if got, _ := store.Get(ctx, "k"); got != (application.LoginAttempts{}) {
+		t.Fatalf("expected releasing the only failure to clear the record got %+v", got)
+	}
+}
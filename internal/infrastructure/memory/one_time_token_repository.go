@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OneTimeTokenRepository is an in-memory implementation for tests.
+type OneTimeTokenRepository struct {
+	mu    sync.Mutex
+	store map[string]domain.OneTimeToken
+}
+
+// NewOneTimeTokenRepository builds an empty repository.
+func NewOneTimeTokenRepository() *OneTimeTokenRepository {
+	return &OneTimeTokenRepository{
+		store: make(map[string]domain.OneTimeToken),
+	}
+}
+
+func (r *OneTimeTokenRepository) Create(ctx context.Context, token domain.OneTimeToken) (domain.OneTimeToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token.ID = primitive.NewObjectID().Hex()
+	r.store[token.ID] = token
+	return token, nil
+}
+
+func (r *OneTimeTokenRepository) Consume(ctx context.Context, purpose domain.TokenPurpose, hash string, at time.Time) (domain.OneTimeToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.store {
+		if token.Purpose != purpose || token.TokenHash != hash {
+			continue
+		}
+		if token.UsedAt != nil || token.Expired(at) {
+			return domain.OneTimeToken{}, application.ErrNotFound
+		}
+		token.UsedAt = &at
+		r.store[id] = token
+		return token, nil
+	}
+	return domain.OneTimeToken{}, application.ErrNotFound
+}
+
+func (r *OneTimeTokenRepository) DeleteByUser(ctx context.Context, userID string, purpose domain.TokenPurpose) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.store {
+		if token.UserID == userID && token.Purpose == purpose {
+			delete(r.store, id)
+		}
+	}
+	return nil
+}
+
+func (r *OneTimeTokenRepository) DeleteUser(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.store {
+		if token.UserID == userID {
+			delete(r.store, id)
+		}
+	}
+	return nil
+}
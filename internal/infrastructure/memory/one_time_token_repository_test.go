@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+)
+
+func TestOneTimeTokenRepository_ConsumeOnce(t *testing.T) {
+	repo := NewOneTimeTokenRepository()
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	if _, err := repo.Create(ctx, domain.OneTimeToken{UserID: "u1", Purpose: domain.PurposePasswordReset, TokenHash: "h1", ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := repo.Create(ctx, domain.OneTimeToken{UserID: "u1", Purpose: domain.PurposePasswordReset, TokenHash: "expired", ExpiresAt: now.Add(-time.Second)}); err != nil {
+		t.Fatalf("create expired: %v", err)
+	}
+
+	if _, err := repo.Consume(ctx, "other", "h1", now); !errors.Is(err, application.ErrNotFound) {
+		t.Fatalf("expected purpose mismatch to fail got %v", err)
+	}
+	consumed, err := repo.Consume(ctx, domain.PurposePasswordReset, "h1", now)
+	if err != nil || consumed.UserID != "u1" || consumed.UsedAt == nil {
+		t.Fatalf("consume: %v %+v", err, consumed)
+	}
+	if _, err := repo.Consume(ctx, domain.PurposePasswordReset, "h1", now); !errors.Is(err, application.ErrNotFound) {
+		t.Fatalf("expected second consume to fail got %v", err)
+	}
+	if _, err := repo.Consume(ctx, domain.PurposePasswordReset, "expired", now); !errors.Is(err, application.ErrNotFound) {
+		t.Fatalf("expected expired token to fail got %v", err)
+	}
+
+	if err := repo.DeleteByUser(ctx, "u1", domain.PurposePasswordReset); err != nil {
+		t.Fatalf("delete by user: %v", err)
+	}
+	if len(repo.store) != 0 {
+		t.Fatalf("expected tokens to be deleted, %d left", len(repo.store))
+	}
+}
+
+func TestOneTimeTokenRepository_DeleteUser(t *testing.T) {
+	repo := NewOneTimeTokenRepository()
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	for _, token := range []domain.OneTimeToken{
+		{UserID: "u1", Purpose: domain.PurposePasswordReset, TokenHash: "h1", ExpiresAt: now.Add(time.Hour)},
+		{UserID: "u1", Purpose: domain.PurposeEmailVerification, TokenHash: "h2", ExpiresAt: now.Add(time.Hour)},
+		{UserID: "u2", Purpose: domain.PurposePasswordReset, TokenHash: "h3", ExpiresAt: now.Add(time.Hour)},
+	} {
+		if _, err := repo.Create(ctx, token); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	if err := repo.DeleteUser(ctx, "u1"); err != nil {
+		t.Fatalf("delete user: %v", err)
+	}
+	if len(repo.store) != 1 {
+		t.Fatalf("expected only the other user's token to remain, %d left", len(repo.store))
+	}
+	if _, err := repo.Consume(ctx, domain.PurposePasswordReset, "h3", now); err != nil {
+		t.Fatalf("expected another user's token to remain: %v", err)
+	}
+}
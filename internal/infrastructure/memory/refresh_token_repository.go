@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RefreshTokenRepository is an in-memory implementation for tests.
+type RefreshTokenRepository struct {
+	mu    sync.Mutex
+	store map[string]domain.RefreshToken
+}
+
+// NewRefreshTokenRepository builds an empty repository.
+func NewRefreshTokenRepository() *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		store: make(map[string]domain.RefreshToken),
+	}
+}
+
+func (r *RefreshTokenRepository) Create(ctx context.Context, token domain.RefreshToken) (domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token.ID = primitive.NewObjectID().Hex()
+	r.store[token.ID] = token
+	return token, nil
+}
+
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, hash string) (domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.store {
+		if token.TokenHash == hash {
+			return token, nil
+		}
+	}
+	return domain.RefreshToken{}, application.ErrNotFound
+}
+
+func (r *RefreshTokenRepository) MarkRotated(ctx context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.store[id]
+	if !ok {
+		return application.ErrNotFound
+	}
+	if token.RotatedAt != nil {
+		return application.ErrRefreshTokenReused
+	}
+	token.RotatedAt = &at
+	r.store[id] = token
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.store {
+		if token.FamilyID != familyID || token.RevokedAt != nil {
+			continue
+		}
+		token.RevokedAt = &at
+		r.store[id] = token
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeUser(ctx context.Context, userID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.store {
+		if token.UserID != userID || token.RevokedAt != nil {
+			continue
+		}
+		token.RevokedAt = &at
+		r.store[id] = token
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepository) DeleteUser(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.store {
+		if token.UserID == userID {
+			delete(r.store, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+)
+
+func TestRefreshTokenRepository_RotateAndRevoke(t *testing.T) {
+	repo := NewRefreshTokenRepository()
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	created, err := repo.Create(ctx, domain.RefreshToken{UserID: "u1", FamilyID: "f1", TokenHash: "h1", ExpiresAt: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := repo.Create(ctx, domain.RefreshToken{UserID: "u1", FamilyID: "f2", TokenHash: "h2", ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("create other family: %v", err)
+	}
+
+	fetched, err := repo.GetByHash(ctx, "h1")
+	if err != nil {
+		t.Fatalf("get by hash: %v", err)
+	}
+	if fetched.ID != created.ID {
+		t.Fatalf("unexpected token: %+v", fetched)
+	}
+
+	if err := repo.MarkRotated(ctx, created.ID, now); err != nil {
+		t.Fatalf("mark rotated: %v", err)
+	}
+	if err := repo.MarkRotated(ctx, created.ID, now); !errors.Is(err, application.ErrRefreshTokenReused) {
+		t.Fatalf("expected ErrRefreshTokenReused got %v", err)
+	}
+
+	if err := repo.RevokeFamily(ctx, "f1", now); err != nil {
+		t.Fatalf("revoke family: %v", err)
+	}
+	if fetched, _ = repo.GetByHash(ctx, "h1"); fetched.RevokedAt == nil {
+		t.Fatal("expected token to be revoked")
+	}
+	if other, _ := repo.GetByHash(ctx, "h2"); other.RevokedAt != nil {
+		t.Fatal("expected other family to remain active")
+	}
+
+	if _, err := repo.GetByHash(ctx, "missing"); !errors.Is(err, application.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound got %v", err)
+	}
+}
+
+func TestRefreshTokenRepository_DeleteUser(t *testing.T) {
+	repo := NewRefreshTokenRepository()
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	for _, token := range []domain.RefreshToken{
+		{UserID: "u1", FamilyID: "f1", TokenHash: "h1", ExpiresAt: now.Add(time.Hour)},
+		{UserID: "u1", FamilyID: "f2", TokenHash: "h2", ExpiresAt: now.Add(time.Hour), RevokedAt: &now},
+		{UserID: "u2", FamilyID: "f3", TokenHash: "h3", ExpiresAt: now.Add(time.Hour)},
+	} {
+		if _, err := repo.Create(ctx, token); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	if err := repo.DeleteUser(ctx, "u1"); err != nil {
+		t.Fatalf("delete user: %v", err)
+	}
+	for _, hash := range []string{"h1", "h2"} {
+		if _, err := repo.GetByHash(ctx, hash); !errors.Is(err, application.ErrNotFound) {
+			t.Fatalf("expected %s to be deleted got %v", hash, err)
+		}
+	}
+	if _, err := repo.GetByHash(ctx, "h3"); err != nil {
+		t.Fatalf("expected another user's token to remain: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TokenRevocationStore is an in-memory implementation for tests.
+type TokenRevocationStore struct {
+	mu      sync.Mutex
+	tokens  map[string]time.Time
+	cutoffs map[string]time.Time
+}
+
+// NewTokenRevocationStore builds an empty store.
+func NewTokenRevocationStore() *TokenRevocationStore {
+	return &TokenRevocationStore{
+		tokens:  make(map[string]time.Time),
+		cutoffs: make(map[string]time.Time),
+	}
+}
+
+func (s *TokenRevocationStore) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[tokenID] = expiresAt
+	return nil
+}
+
+func (s *TokenRevocationStore) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt, ok := s.tokens[tokenID]
+	if !ok {
+		return false, nil
+	}
+	if time.Now().After(expiresAt) {
+		delete(s.tokens, tokenID)
+		return false, nil
+	}
+	return true, nil
+}
+
+func (s *TokenRevocationStore) RevokeUserTokens(ctx context.Context, userID string, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if before.After(s.cutoffs[userID]) {
+		s.cutoffs[userID] = before
+	}
+	return nil
+}
+
+func (s *TokenRevocationStore) UserTokensRevokedBefore(ctx context.Context, userID string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cutoffs[userID], nil
+}
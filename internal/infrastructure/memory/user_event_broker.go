@@ -0,0 +1,171 @@
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+)
+
+// watcherBuffer is how many changes a watcher may fall behind before it is
+// caught up again from the history.
+const watcherBuffer = 64
+
+// UserEventBroker fans user events out to watchers in this process. It keeps
+// the most recent events so that a watcher can resume after the token of the
+// last change it saw, as long as that change is still in the history.
+// Tokens from another broker, such as one from before a restart, are rejected.
+type UserEventBroker struct {
+	mu       sync.Mutex
+	epoch    string
+	seq      uint64
+	history  []application.UserChange
+	capacity int
+	watchers map[chan application.UserChange]struct{}
+}
+
+// NewUserEventBroker builds a broker that keeps the last history events for
+// resuming watchers.
+func NewUserEventBroker(history int) *UserEventBroker {
+	raw := make([]byte, 4)
+	_, _ = rand.Read(raw)
+	return &UserEventBroker{
+		epoch:    hex.EncodeToString(raw),
+		capacity: history,
+		watchers: make(map[chan application.UserChange]struct{}),
+	}
+}
+
+// Publish records the event and hands it to every watcher. Watchers that are
+// too far behind are dropped and catch up from the history themselves.
+func (b *UserEventBroker) Publish(ctx context.Context, event domain.UserEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	change := application.UserChange{Event: event, ResumeToken: b.token(b.seq)}
+	if b.capacity > 0 {
+		if len(b.history) == b.capacity {
+			b.history = append(b.history[:0], b.history[1:]...)
+		}
+		b.history = append(b.history, change)
+	}
+
+	for ch := range b.watchers {
+		select {
+		case ch <- change:
+		default:
+			delete(b.watchers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (b *UserEventBroker) Watch(ctx context.Context, resumeToken string, fn func(application.UserChange) error) error {
+	after, err := b.parse(resumeToken)
+	if err != nil {
+		return err
+	}
+
+	for {
+		ch, backlog, err := b.subscribe(after)
+		if err != nil {
+			return err
+		}
+		after, err = b.deliver(ctx, ch, backlog, after, fn)
+		if err != nil {
+			b.unsubscribe(ch)
+			return err
+		}
+		// The channel was closed because the watcher fell behind; resume from
+		// the last change it saw.
+	}
+}
+
+// deliver passes on the backlog and then live changes until ch is closed. It
+// returns the sequence number of the last change delivered.
+func (b *UserEventBroker) deliver(ctx context.Context, ch chan application.UserChange, backlog []application.UserChange, after uint64, fn func(application.UserChange) error) (uint64, error) {
+	for _, change := range backlog {
+		if err := fn(change); err != nil {
+			return after, err
+		}
+		after = b.sequence(change)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return after, ctx.Err()
+		case change, ok := <-ch:
+			if !ok {
+				return after, nil
+			}
+			if err := fn(change); err != nil {
+				return after, err
+			}
+			after = b.sequence(change)
+		}
+	}
+}
+
+// subscribe registers a watcher and returns the changes after the given
+// sequence number that it has already missed.
+func (b *UserEventBroker) subscribe(after uint64) (chan application.UserChange, []application.UserChange, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if after > b.seq {
+		return nil, nil, application.ErrInvalidResumeToken
+	}
+	missed := int(b.seq - after)
+	if missed > len(b.history) {
+		return nil, nil, application.ErrInvalidResumeToken
+	}
+	backlog := append([]application.UserChange(nil), b.history[len(b.history)-missed:]...)
+
+	ch := make(chan application.UserChange, watcherBuffer)
+	b.watchers[ch] = struct{}{}
+	return ch, backlog, nil
+}
+
+func (b *UserEventBroker) unsubscribe(ch chan application.UserChange) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.watchers[ch]; ok {
+		delete(b.watchers, ch)
+		close(ch)
+	}
+}
+
+// parse returns the sequence number a token resumes after. An empty token
+// starts from the latest change.
+func (b *UserEventBroker) parse(token string) (uint64, error) {
+	if token == "" {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return b.seq, nil
+	}
+	epoch, seq, ok := strings.Cut(token, "-")
+	if !ok || epoch != b.epoch {
+		return 0, application.ErrInvalidResumeToken
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return 0, application.ErrInvalidResumeToken
+	}
+	return n, nil
+}
+
+func (b *UserEventBroker) token(seq uint64) string {
+	return fmt.Sprintf("%s-%d", b.epoch, seq)
+}
+
+func (b *UserEventBroker) sequence(change application.UserChange) uint64 {
+	n, _ := b.parse(change.ResumeToken)
+	return n
+}
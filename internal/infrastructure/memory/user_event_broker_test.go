@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+)
+
+func publishN(broker *UserEventBroker, from, n int) {
+	for i := from; i < from+n; i++ {
+		broker.Publish(context.Background(), domain.NewUserEvent(domain.UserUpdated, domain.User{ID: fmt.Sprint(i)}))
+	}
+}
+
+// collect watches until want changes have arrived and returns them.
+func collect(t *testing.T, broker *UserEventBroker, token string, want int, publish func()) []application.UserChange {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var got []application.UserChange
+	done := errors.New("done")
+	result := make(chan error, 1)
+	go func() {
+		result <- broker.Watch(ctx, token, func(change application.UserChange) error {
+			got = append(got, change)
+			if len(got) == want {
+				return done
+			}
+			return nil
+		})
+	}()
+	// Give the watcher a moment to subscribe before publishing live events.
+	time.Sleep(10 * time.Millisecond)
+	publish()
+
+	if err := <-result; !errors.Is(err, done) {
+		t.Fatalf("watch ended with %v after %d changes", err, len(got))
+	}
+	return got
+}
+
+func TestUserEventBrokerLiveAndResume(t *testing.T) {
+	broker := NewUserEventBroker(10)
+	publishN(broker, 0, 2)
+
+	live := collect(t, broker, "", 3, func() { publishN(broker, 2, 3) })
+	for i, change := range live {
+		if change.Event.UserID != fmt.Sprint(i+2) {
+			t.Fatalf("expected only changes after the watch started got %s at %d", change.Event.UserID, i)
+		}
+	}
+
+	resumed := collect(t, broker, live[0].ResumeToken, 3, func() { publishN(broker, 5, 1) })
+	for i, want := range []string{"3", "4", "5"} {
+		if resumed[i].Event.UserID != want {
+			t.Fatalf("expected replay then live changes, got %s at %d", resumed[i].Event.UserID, i)
+		}
+	}
+}
+
+func TestUserEventBrokerRejectsUnusableTokens(t *testing.T) {
+	broker := NewUserEventBroker(2)
+	publishN(broker, 0, 1)
+	first := collect(t, broker, "", 1, func() { publishN(broker, 1, 1) })[0].ResumeToken
+	publishN(broker, 2, 3)
+
+	for name, token := range map[string]string{
+		"expired":   first,
+		"foreign":   NewUserEventBroker(2).token(1),
+		"future":    broker.token(99),
+		"malformed": "not-a-token",
+	} {
+		err := broker.Watch(context.Background(), token, func(application.UserChange) error { return nil })
+		if !errors.Is(err, application.ErrInvalidResumeToken) {
+			t.Fatalf("%s: expected ErrInvalidResumeToken got %v", name, err)
+		}
+	}
+}
+
+func TestUserEventBrokerSlowWatcherCatchesUp(t *testing.T) {
+	broker := NewUserEventBroker(1000)
+	total := watcherBuffer * 3
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The watcher blocks on its first change until everything has been
+	// published, so its buffer overflows and it is dropped by the broker.
+	release := make(chan struct{})
+	var got []string
+	done := errors.New("done")
+	result := make(chan error, 1)
+	go func() {
+		result <- broker.Watch(ctx, "", func(change application.UserChange) error {
+			if len(got) == 0 {
+				<-release
+			}
+			got = append(got, change.Event.UserID)
+			if len(got) == total {
+				return done
+			}
+			return nil
+		})
+	}()
+	time.Sleep(10 * time.Millisecond)
+	publishN(broker, 0, total)
+	close(release)
+
+	if err := <-result; !errors.Is(err, done) {
+		t.Fatalf("watch ended with %v after %d changes", err, len(got))
+	}
+	for i, id := range got {
+		if id != fmt.Sprint(i) {
+			t.Fatalf("expected changes in order without gaps, got %s at %d", id, i)
+		}
+	}
+}
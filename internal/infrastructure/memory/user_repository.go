@@ -2,6 +2,7 @@ package memory
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 
@@ -28,14 +29,13 @@ func (r *UserRepository) Create(ctx context.Context, user domain.User) (domain.U
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	for _, v := range r.store {
-		if v.Email == user.Email {
-			return domain.User{}, application.ErrDuplicateEmail
-		}
+	if r.emailTaken(user.Email, "") {
+		return domain.User{}, application.ErrDuplicateEmail
 	}
 
 	id := primitive.NewObjectID().Hex()
 	user.ID = id
+	user.Version = 1
 	if user.CreatedAt.IsZero() {
 		user.CreatedAt = time.Now().UTC()
 	}
@@ -48,7 +48,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (domain.U
 	defer r.mu.RUnlock()
 
 	for _, user := range r.store {
-		if user.Email == email {
+		if user.Email == email && !user.Deleted() {
 			return user, nil
 		}
 	}
@@ -60,21 +60,39 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (domain.User, e
 	defer r.mu.RUnlock()
 
 	user, ok := r.store[id]
-	if !ok {
+	if !ok || user.Deleted() {
 		return domain.User{}, application.ErrNotFound
 	}
 	return user, nil
 }
 
-func (r *UserRepository) List(ctx context.Context) ([]domain.User, error) {
+func (r *UserRepository) List(ctx context.Context, query domain.UserListQuery) (domain.UserPage, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	order := query.SortOrDefault()
 	users := make([]domain.User, 0, len(r.store))
 	for _, user := range r.store {
+		if !query.Filter.Matches(user) {
+			continue
+		}
+		if query.After != nil && !order.Precedes(*query.After, order.CursorOf(user)) {
+			continue
+		}
 		users = append(users, user)
 	}
-	return users, nil
+	sort.Slice(users, func(i, j int) bool {
+		return order.Precedes(order.CursorOf(users[i]), order.CursorOf(users[j]))
+	})
+
+	var page domain.UserPage
+	if query.Limit > 0 && len(users) > query.Limit {
+		users = users[:query.Limit]
+		next := order.CursorOf(users[len(users)-1])
+		page.Next = &next
+	}
+	page.Users = users
+	return page, nil
 }
 
 func (r *UserRepository) Update(ctx context.Context, id string, update domain.UpdateUser) (domain.User, error) {
@@ -82,42 +100,201 @@ func (r *UserRepository) Update(ctx context.Context, id string, update domain.Up
 	defer r.mu.Unlock()
 
 	user, ok := r.store[id]
-	if !ok {
+	if !ok || user.Deleted() {
 		return domain.User{}, application.ErrNotFound
 	}
+	if update.IfVersion != nil && *update.IfVersion != user.Version {
+		return domain.User{}, application.ErrVersionMismatch
+	}
 
 	if update.Email != nil {
-		for otherID, existing := range r.store {
-			if otherID == id {
-				continue
-			}
-			if existing.Email == *update.Email {
-				return domain.User{}, application.ErrDuplicateEmail
-			}
+		if r.emailTaken(*update.Email, id) {
+			return domain.User{}, application.ErrDuplicateEmail
+		}
+		if *update.Email != user.Email {
+			user.VerifiedAt = nil
 		}
 		user.Email = *update.Email
 	}
 	if update.Name != nil {
 		user.Name = *update.Name
 	}
+	user.Version++
+
+	r.store[id] = user
+	return user, nil
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id, passwordHash string, changedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.store[id]
+	if !ok || user.Deleted() {
+		return application.ErrNotFound
+	}
+	user.Password = passwordHash
+	user.PasswordChangedAt = &changedAt
+	r.store[id] = user
+	return nil
+}
+
+func (r *UserRepository) UpdateRoles(ctx context.Context, id string, roles []domain.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.store[id]
+	if !ok || user.Deleted() {
+		return application.ErrNotFound
+	}
+	user.Roles = append([]domain.Role(nil), roles...)
+	user.Version++
+	r.store[id] = user
+	return nil
+}
+
+func (r *UserRepository) ReplacePasswordHash(ctx context.Context, id, oldHash, newHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
+	user, ok := r.store[id]
+	if !ok || user.Deleted() || user.Password != oldHash {
+		return application.ErrNotFound
+	}
+	user.Password = newHash
 	r.store[id] = user
+	return nil
+}
+
+func (r *UserRepository) MarkVerified(ctx context.Context, id string, at time.Time) (domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.store[id]
+	if !ok || user.Deleted() {
+		return domain.User{}, application.ErrNotFound
+	}
+	if user.VerifiedAt == nil {
+		user.VerifiedAt = &at
+		user.Version++
+		r.store[id] = user
+	}
 	return user, nil
 }
 
-func (r *UserRepository) Delete(ctx context.Context, id string) error {
+func (r *UserRepository) UpdateMFA(ctx context.Context, id string, mfa domain.MFA) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, ok := r.store[id]; !ok {
+	user, ok := r.store[id]
+	if !ok || user.Deleted() {
 		return application.ErrNotFound
 	}
-	delete(r.store, id)
+	mfa.RecoveryCodes = append([]string(nil), mfa.RecoveryCodes...)
+	user.MFA = mfa
+	user.Version++
+	r.store[id] = user
 	return nil
 }
 
+func (r *UserRepository) UseTOTPStep(ctx context.Context, id string, step int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.store[id]
+	if !ok || user.Deleted() || step <= user.MFA.LastStep {
+		return application.ErrNotFound
+	}
+	user.MFA.LastStep = step
+	r.store[id] = user
+	return nil
+}
+
+func (r *UserRepository) UseRecoveryCode(ctx context.Context, id, codeHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.store[id]
+	if !ok || user.Deleted() {
+		return application.ErrNotFound
+	}
+	for i, hash := range user.MFA.RecoveryCodes {
+		if hash == codeHash {
+			remaining := make([]string, 0, len(user.MFA.RecoveryCodes)-1)
+			remaining = append(remaining, user.MFA.RecoveryCodes[:i]...)
+			user.MFA.RecoveryCodes = append(remaining, user.MFA.RecoveryCodes[i+1:]...)
+			r.store[id] = user
+			return nil
+		}
+	}
+	return application.ErrNotFound
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.store[id]
+	if !ok || user.Deleted() {
+		return application.ErrNotFound
+	}
+	user.DeletedAt = &at
+	user.Version++
+	r.store[id] = user
+	return nil
+}
+
+func (r *UserRepository) Restore(ctx context.Context, id string) (domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.store[id]
+	if !ok || !user.Deleted() {
+		return domain.User{}, application.ErrNotFound
+	}
+	if r.emailTaken(user.Email, id) {
+		return domain.User{}, application.ErrDuplicateEmail
+	}
+	user.DeletedAt = nil
+	user.Version++
+	r.store[id] = user
+	return user, nil
+}
+
+func (r *UserRepository) Purge(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged []string
+	for id, user := range r.store {
+		if user.Deleted() && user.DeletedAt.Before(deletedBefore) {
+			delete(r.store, id)
+			purged = append(purged, id)
+		}
+	}
+	return purged, nil
+}
+
 func (r *UserRepository) Count(ctx context.Context) (int64, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	return int64(len(r.store)), nil
+
+	var count int64
+	for _, user := range r.store {
+		if !user.Deleted() {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// emailTaken reports whether a user other than exceptID that is not deleted
+// has email. The caller must hold the lock.
+func (r *UserRepository) emailTaken(email, exceptID string) bool {
+	for id, user := range r.store {
+		if id != exceptID && user.Email == email && !user.Deleted() {
+			return true
+		}
+	}
+	return false
 }
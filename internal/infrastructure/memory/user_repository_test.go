@@ -3,7 +3,10 @@ package memory
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"backend-challenge/internal/application"
 	"backend-challenge/internal/domain"
@@ -35,12 +38,12 @@ func TestUserRepository_CreateGetListCount(t *testing.T) {
 		t.Fatalf("get by email: %v", err)
 	}
 
-	users, err := repo.List(ctx)
+	page, err := repo.List(ctx, domain.UserListQuery{})
 	if err != nil {
 		t.Fatalf("list: %v", err)
 	}
-	if len(users) != 1 {
-		t.Fatalf("expected 1 user got %d", len(users))
+	if len(page.Users) != 1 || page.Next != nil {
+		t.Fatalf("expected a single page with 1 user got %+v", page)
 	}
 
 	count, err := repo.Count(ctx)
@@ -84,11 +87,316 @@ func TestUserRepository_DuplicateAndUpdate(t *testing.T) {
 	}
 }
 
+func TestUserRepository_UpdateIfVersion(t *testing.T) {
+	repo := NewUserRepository()
+	ctx := context.Background()
+
+	user, err := repo.Create(ctx, domain.User{Name: "A", Email: "a@example.com"})
+	if err != nil || user.Version != 1 {
+		t.Fatalf("expected a new user at version 1 got %d, %v", user.Version, err)
+	}
+
+	name, stale := "B", user.Version
+	updated, err := repo.Update(ctx, user.ID, domain.UpdateUser{Name: &name, IfVersion: &stale})
+	if err != nil || updated.Version != 2 {
+		t.Fatalf("expected the update to move to version 2 got %d, %v", updated.Version, err)
+	}
+	name = "C"
+	if _, err := repo.Update(ctx, user.ID, domain.UpdateUser{Name: &name, IfVersion: &stale}); !errors.Is(err, application.ErrVersionMismatch) {
+		t.Fatalf("expected ErrVersionMismatch for a stale version got %v", err)
+	}
+	if got, _ := repo.GetByID(ctx, user.ID); got.Name != "B" {
+		t.Fatalf("expected the stale update not to apply got %q", got.Name)
+	}
+	if _, err := repo.Update(ctx, "missing", domain.UpdateUser{Name: &name, IfVersion: &stale}); !errors.Is(err, application.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing user got %v", err)
+	}
+
+	if err := repo.UpdateRoles(ctx, user.ID, []domain.Role{domain.RoleUser, domain.RoleAdmin}); err != nil {
+		t.Fatalf("update roles: %v", err)
+	}
+	if got, _ := repo.GetByID(ctx, user.ID); got.Version != 3 {
+		t.Fatalf("expected a role change to bump the version got %d", got.Version)
+	}
+}
+
 func TestUserRepository_DeleteNotFound(t *testing.T) {
 	repo := NewUserRepository()
 	ctx := context.Background()
 
-	if err := repo.Delete(ctx, "missing"); !errors.Is(err, application.ErrNotFound) {
+	if err := repo.Delete(ctx, "missing", time.Now()); !errors.Is(err, application.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound got %v", err)
+	}
+}
+
+func TestUserRepository_MarkVerified(t *testing.T) {
+	repo := NewUserRepository()
+	ctx := context.Background()
+
+	user, err := repo.Create(ctx, domain.User{Name: "A", Email: "a@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if user.Verified() {
+		t.Fatal("new user should not be verified")
+	}
+
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	verified, err := repo.MarkVerified(ctx, user.ID, first)
+	if err != nil || !verified.Verified() {
+		t.Fatalf("mark verified: %+v %v", verified, err)
+	}
+
+	again, err := repo.MarkVerified(ctx, user.ID, first.Add(time.Hour))
+	if err != nil || !again.VerifiedAt.Equal(first) {
+		t.Fatalf("expected first verification time kept got %+v %v", again.VerifiedAt, err)
+	}
+
+	if _, err := repo.MarkVerified(ctx, "missing", first); !errors.Is(err, application.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound got %v", err)
+	}
+
+	same := "a@example.com"
+	kept, err := repo.Update(ctx, user.ID, domain.UpdateUser{Email: &same})
+	if err != nil || !kept.Verified() {
+		t.Fatalf("expected the same email to stay verified got %+v %v", kept, err)
+	}
+	changed := "b@example.com"
+	cleared, err := repo.Update(ctx, user.ID, domain.UpdateUser{Email: &changed})
+	if err != nil || cleared.Verified() {
+		t.Fatalf("expected a new email to be unverified got %+v %v", cleared, err)
+	}
+}
+
+func TestUserRepository_MFA(t *testing.T) {
+	repo := NewUserRepository()
+	ctx := context.Background()
+
+	user, err := repo.Create(ctx, domain.User{Name: "A", Email: "a@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	now := time.Now()
+	codes := []string{"hash-1", "hash-2"}
+	if err := repo.UpdateMFA(ctx, user.ID, domain.MFA{Secret: "SECRET", EnabledAt: &now, RecoveryCodes: codes, LastStep: 10}); err != nil {
+		t.Fatalf("update mfa: %v", err)
+	}
+	codes[0] = "mutated"
+
+	if err := repo.UseTOTPStep(ctx, user.ID, 10); !errors.Is(err, application.ErrNotFound) {
+		t.Fatalf("expected replayed step to fail got %v", err)
+	}
+	if err := repo.UseTOTPStep(ctx, user.ID, 11); err != nil {
+		t.Fatalf("use step: %v", err)
+	}
+
+	if err := repo.UseRecoveryCode(ctx, user.ID, "hash-1"); err != nil {
+		t.Fatalf("use recovery code: %v", err)
+	}
+	if err := repo.UseRecoveryCode(ctx, user.ID, "hash-1"); !errors.Is(err, application.ErrNotFound) {
+		t.Fatalf("expected used recovery code to fail got %v", err)
+	}
+
+	stored, err := repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !stored.MFA.Enabled() || stored.MFA.LastStep != 11 || len(stored.MFA.RecoveryCodes) != 1 || stored.MFA.RecoveryCodes[0] != "hash-2" {
+		t.Fatalf("unexpected mfa state %+v", stored.MFA)
+	}
+}
+
+func TestUserRepository_ReplacePasswordHash(t *testing.T) {
+	repo := NewUserRepository()
+	ctx := context.Background()
+
+	user, err := repo.Create(ctx, domain.User{Name: "A", Email: "a@example.com", Password: "old"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if err := repo.ReplacePasswordHash(ctx, user.ID, "stale", "new"); !errors.Is(err, application.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for stale hash got %v", err)
+	}
+	if err := repo.ReplacePasswordHash(ctx, user.ID, "old", "new"); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	stored, _ := repo.GetByID(ctx, user.ID)
+	if stored.Password != "new" {
+		t.Fatalf("expected new hash got %q", stored.Password)
+	}
+}
+
+func TestUserRepository_UpdateRoles(t *testing.T) {
+	repo := NewUserRepository()
+	ctx := context.Background()
+
+	user, err := repo.Create(ctx, domain.User{Name: "A", Email: "a@example.com", Roles: []domain.Role{domain.RoleUser}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.UpdateRoles(ctx, user.ID, []domain.Role{domain.RoleUser, domain.RoleAdmin}); err != nil {
+		t.Fatalf("update roles: %v", err)
+	}
+	stored, _ := repo.GetByID(ctx, user.ID)
+	if !stored.HasRole(domain.RoleAdmin) {
+		t.Fatalf("expected admin role got %v", stored.Roles)
+	}
+	if err := repo.UpdateRoles(ctx, "missing", nil); !errors.Is(err, application.ErrNotFound) {
 		t.Fatalf("expected ErrNotFound got %v", err)
 	}
 }
+
+func TestUserRepository_ListPages(t *testing.T) {
+	repo := NewUserRepository()
+	ctx := context.Background()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var want []string
+	for i, at := range []time.Time{base, base.Add(time.Minute), base.Add(time.Minute), base.Add(2 * time.Minute), base} {
+		user, err := repo.Create(ctx, domain.User{Name: "User", Email: fmt.Sprintf("user%d@example.com", i), CreatedAt: at})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		want = append(want, user.ID)
+	}
+	// Newest first; users created at the same instant by descending ID.
+	want = []string{want[3], want[2], want[1], want[4], want[0]}
+
+	var got []string
+	query := domain.UserListQuery{Limit: 2}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatalf("expected 3 pages, still paging after %v", got)
+		}
+		page, err := repo.List(ctx, query)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		for _, user := range page.Users {
+			got = append(got, user.ID)
+		}
+		if page.Next == nil {
+			break
+		}
+		query.After = page.Next
+	}
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected order %v got %v", want, got)
+	}
+}
+
+func TestUserRepository_ListFiltersAndSorts(t *testing.T) {
+	repo := NewUserRepository()
+	ctx := context.Background()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, name := range []string{"carol", "Alice", "bob", "alan"} {
+		_, err := repo.Create(ctx, domain.User{Name: name, Email: fmt.Sprintf("%s@example.com", strings.ToLower(name)), CreatedAt: base.Add(time.Duration(i) * time.Hour)})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	names := func(query domain.UserListQuery) []string {
+		var got []string
+		for {
+			page, err := repo.List(ctx, query)
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			for _, user := range page.Users {
+				got = append(got, user.Name)
+			}
+			if page.Next == nil {
+				return got
+			}
+			query.After = page.Next
+		}
+	}
+
+	byName := domain.UserSort{Field: domain.SortByName}
+	if got := names(domain.UserListQuery{Sort: byName, Limit: 1}); fmt.Sprint(got) != "[alan Alice bob carol]" {
+		t.Fatalf("expected case-insensitive name order got %v", got)
+	}
+	if got := names(domain.UserListQuery{Filter: domain.UserFilter{Search: "AL"}, Sort: byName, Limit: 1}); fmt.Sprint(got) != "[alan Alice]" {
+		t.Fatalf("expected users starting with al got %v", got)
+	}
+	after := base
+	if got := names(domain.UserListQuery{Filter: domain.UserFilter{CreatedAfter: &after}}); fmt.Sprint(got) != "[alan bob Alice]" {
+		t.Fatalf("expected users created after the first, newest first, got %v", got)
+	}
+}
+
+func TestUserRepository_SoftDelete(t *testing.T) {
+	repo := NewUserRepository()
+	ctx := context.Background()
+
+	user, err := repo.Create(ctx, domain.User{Name: "A", Email: "a@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	deletedAt := time.Now().UTC()
+	if err := repo.Delete(ctx, user.ID, deletedAt); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := repo.Delete(ctx, user.ID, deletedAt); !errors.Is(err, application.ErrNotFound) {
+		t.Fatalf("expected deleting twice to fail with ErrNotFound got %v", err)
+	}
+
+	if _, err := repo.GetByID(ctx, user.ID); !errors.Is(err, application.ErrNotFound) {
+		t.Fatalf("expected deleted user to be hidden from GetByID got %v", err)
+	}
+	if _, err := repo.GetByEmail(ctx, user.Email); !errors.Is(err, application.ErrNotFound) {
+		t.Fatalf("expected deleted user to be hidden from GetByEmail got %v", err)
+	}
+	if _, err := repo.Update(ctx, user.ID, domain.UpdateUser{Name: &user.Name}); !errors.Is(err, application.ErrNotFound) {
+		t.Fatalf("expected deleted user not to be updated got %v", err)
+	}
+	if count, _ := repo.Count(ctx); count != 0 {
+		t.Fatalf("expected deleted user not to be counted got %d", count)
+	}
+	if page, _ := repo.List(ctx, domain.UserListQuery{}); len(page.Users) != 0 {
+		t.Fatalf("expected deleted user not to be listed got %+v", page.Users)
+	}
+	page, _ := repo.List(ctx, domain.UserListQuery{Filter: domain.UserFilter{IncludeDeleted: true}})
+	if len(page.Users) != 1 || page.Users[0].DeletedAt == nil || !page.Users[0].DeletedAt.Equal(deletedAt) {
+		t.Fatalf("expected deleted user to be listed on request got %+v", page.Users)
+	}
+
+	// The email is free again, so restoring would create a duplicate.
+	reused, err := repo.Create(ctx, domain.User{Name: "B", Email: "a@example.com"})
+	if err != nil {
+		t.Fatalf("expected a deleted user's email to be reusable got %v", err)
+	}
+	if _, err := repo.Restore(ctx, user.ID); !errors.Is(err, application.ErrDuplicateEmail) {
+		t.Fatalf("expected ErrDuplicateEmail restoring a taken email got %v", err)
+	}
+	if err := repo.Delete(ctx, reused.ID, deletedAt); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	restored, err := repo.Restore(ctx, user.ID)
+	if err != nil || restored.DeletedAt != nil {
+		t.Fatalf("expected user to be restored got %+v, %v", restored, err)
+	}
+	if _, err := repo.Restore(ctx, user.ID); !errors.Is(err, application.ErrNotFound) {
+		t.Fatalf("expected restoring an active user to fail with ErrNotFound got %v", err)
+	}
+
+	purged, err := repo.Purge(ctx, deletedAt)
+	if err != nil || len(purged) != 0 {
+		t.Fatalf("expected nothing deleted before the cutoff got %v, %v", purged, err)
+	}
+	purged, err = repo.Purge(ctx, deletedAt.Add(time.Second))
+	if err != nil || len(purged) != 1 || purged[0] != reused.ID {
+		t.Fatalf("expected the deleted user to be purged got %v, %v", purged, err)
+	}
+	if _, err := repo.Restore(ctx, reused.ID); !errors.Is(err, application.ErrNotFound) {
+		t.Fatalf("expected a purged user to be gone got %v", err)
+	}
+	if _, err := repo.GetByID(ctx, user.ID); err != nil {
+		t.Fatalf("expected the restored user to survive the purge got %v", err)
+	}
+}
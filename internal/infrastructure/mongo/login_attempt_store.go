@@ -0,0 +1,120 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend-challenge/internal/application"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const loginAttemptsCollection = "login_attempts"
+
+// LoginAttemptStore is a Mongo-backed implementation of application.LoginAttemptStore.
+type LoginAttemptStore struct {
+	collection *mongo.Collection
+}
+
+// NewLoginAttemptStore constructs a store. Records are removed by a TTL index
+// once their tracking window has passed.
+func NewLoginAttemptStore(db *mongo.Database) (*LoginAttemptStore, error) {
+	collection := db.Collection(loginAttemptsCollection)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0).SetName("ttl_expires_at"),
+	}
+
+	if _, err := collection.Indexes().CreateOne(ctx, indexModel); err != nil {
+		return nil, fmt.Errorf("create login attempt index: %w", err)
+	}
+
+	return &LoginAttemptStore{collection: collection}, nil
+}
+
+type mongoLoginAttempts struct {
+	Key             string    `bson:"_id"`
+	Failures        int       `bson:"failures"`
+	LastFailure     time.Time `bson:"last_failure"`
+	PreviousFailure time.Time `bson:"previous_failure,omitempty"`
+	ExpiresAt       time.Time `bson:"expires_at"`
+}
+
+func (d mongoLoginAttempts) toApplication() application.LoginAttempts {
+	return application.LoginAttempts{Failures: d.Failures, LastFailure: d.LastFailure, PreviousFailure: d.PreviousFailure}
+}
+
+// RecordFailure increments the counter in a single update, restarting it when
+// the previous failure fell outside the window.
+func (s *LoginAttemptStore) RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (application.LoginAttempts, error) {
+	at = at.UTC()
+	recent := bson.M{"$gte": bson.A{"$last_failure", at.Add(-window)}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"failures": bson.M{"$cond": bson.A{
+				recent,
+				bson.M{"$add": bson.A{"$failures", 1}},
+				1,
+			}},
+			"previous_failure": bson.M{"$cond": bson.A{recent, "$last_failure", "$$REMOVE"}},
+			"last_failure":     at,
+			"expires_at":       at.Add(window),
+		}}},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var doc mongoLoginAttempts
+	if err := s.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&doc); err != nil {
+		return application.LoginAttempts{}, err
+	}
+	return doc.toApplication(), nil
+}
+
+// Release drops the count in a single update, and rewinds the last failure
+// only while the record is still the one RecordFailure returned.
+func (s *LoginAttemptStore) Release(ctx context.Context, key string, recorded application.LoginAttempts) error {
+	unchanged := bson.M{"$and": bson.A{
+		bson.M{"$eq": bson.A{"$failures", recorded.Failures}},
+		bson.M{"$eq": bson.A{"$last_failure", recorded.LastFailure.UTC()}},
+	}}
+	var previous interface{} = "$$REMOVE"
+	if !recorded.PreviousFailure.IsZero() {
+		previous = recorded.PreviousFailure.UTC()
+	}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"failures":         bson.M{"$max": bson.A{bson.M{"$subtract": bson.A{"$failures", 1}}, 0}},
+			"last_failure":     bson.M{"$cond": bson.A{unchanged, previous, "$last_failure"}},
+			"previous_failure": bson.M{"$cond": bson.A{unchanged, "$$REMOVE", "$previous_failure"}},
+		}}},
+	}
+	_, err := s.collection.UpdateOne(ctx, bson.M{"_id": key}, update)
+	return err
+}
+
+// Get returns the record for key, or the zero value if there is none.
+func (s *LoginAttemptStore) Get(ctx context.Context, key string) (application.LoginAttempts, error) {
+	var doc mongoLoginAttempts
+	err := s.collection.FindOne(ctx, bson.M{"_id": key}).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return application.LoginAttempts{}, nil
+		}
+		return application.LoginAttempts{}, err
+	}
+	return doc.toApplication(), nil
+}
+
+// Reset deletes the record for key.
+func (s *LoginAttemptStore) Reset(ctx context.Context, key string) error {
+	_, err := s.collection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
@@ -0,0 +1,131 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const oneTimeTokensCollection = "one_time_tokens"
+
+// OneTimeTokenRepository is a Mongo-backed implementation of application.OneTimeTokenRepository.
+type OneTimeTokenRepository struct {
+	collection *mongo.Collection
+}
+
+// NewOneTimeTokenRepository constructs a repository and sets indices. Expired
+// tokens are removed by a TTL index on expires_at.
+func NewOneTimeTokenRepository(db *mongo.Database) (*OneTimeTokenRepository, error) {
+	col := db.Collection(oneTimeTokensCollection)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("unique_token_hash"),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "purpose", Value: 1}},
+			Options: options.Index().SetName("user_id_purpose"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0).SetName("ttl_expires_at"),
+		},
+	}
+
+	if _, err := col.Indexes().CreateMany(ctx, indexes); err != nil {
+		return nil, fmt.Errorf("create one-time token indexes: %w", err)
+	}
+
+	return &OneTimeTokenRepository{collection: col}, nil
+}
+
+type mongoOneTimeToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    string             `bson:"user_id"`
+	Purpose   string             `bson:"purpose"`
+	TokenHash string             `bson:"token_hash"`
+	CreatedAt time.Time          `bson:"created_at"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+	UsedAt    *time.Time         `bson:"used_at,omitempty"`
+}
+
+func oneTimeTokenToDomain(mt mongoOneTimeToken) domain.OneTimeToken {
+	return domain.OneTimeToken{
+		ID:        mt.ID.Hex(),
+		UserID:    mt.UserID,
+		Purpose:   domain.TokenPurpose(mt.Purpose),
+		TokenHash: mt.TokenHash,
+		CreatedAt: mt.CreatedAt,
+		ExpiresAt: mt.ExpiresAt,
+		UsedAt:    mt.UsedAt,
+	}
+}
+
+// Create persists a new one-time token.
+func (r *OneTimeTokenRepository) Create(ctx context.Context, token domain.OneTimeToken) (domain.OneTimeToken, error) {
+	doc := mongoOneTimeToken{
+		UserID:    token.UserID,
+		Purpose:   string(token.Purpose),
+		TokenHash: token.TokenHash,
+		CreatedAt: token.CreatedAt,
+		ExpiresAt: token.ExpiresAt,
+	}
+	result, err := r.collection.InsertOne(ctx, doc)
+	if err != nil {
+		return domain.OneTimeToken{}, err
+	}
+
+	id, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return domain.OneTimeToken{}, errors.New("unexpected inserted id type")
+	}
+	token.ID = id.Hex()
+	return token, nil
+}
+
+// Consume marks a live token as used in a single atomic update, so two
+// concurrent redemptions cannot both succeed.
+func (r *OneTimeTokenRepository) Consume(ctx context.Context, purpose domain.TokenPurpose, hash string, at time.Time) (domain.OneTimeToken, error) {
+	filter := bson.M{
+		"token_hash": hash,
+		"purpose":    string(purpose),
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": at},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var mt mongoOneTimeToken
+	err := r.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"used_at": at}}, opts).Decode(&mt)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.OneTimeToken{}, application.ErrNotFound
+		}
+		return domain.OneTimeToken{}, err
+	}
+	return oneTimeTokenToDomain(mt), nil
+}
+
+// DeleteByUser removes every token of the given purpose issued to the user.
+func (r *OneTimeTokenRepository) DeleteByUser(ctx context.Context, userID string, purpose domain.TokenPurpose) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID, "purpose": string(purpose)})
+	return err
+}
+
+// DeleteUser removes every token issued to the user.
+func (r *OneTimeTokenRepository) DeleteUser(ctx context.Context, userID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
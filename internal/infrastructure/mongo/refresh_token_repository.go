@@ -0,0 +1,154 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const refreshTokensCollection = "refresh_tokens"
+
+// RefreshTokenRepository is a Mongo-backed implementation of application.RefreshTokenRepository.
+type RefreshTokenRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRefreshTokenRepository constructs a repository and sets indices. Expired
+// tokens are removed by a TTL index on expires_at.
+func NewRefreshTokenRepository(db *mongo.Database) (*RefreshTokenRepository, error) {
+	col := db.Collection(refreshTokensCollection)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("unique_token_hash"),
+		},
+		{
+			Keys:    bson.D{{Key: "family_id", Value: 1}},
+			Options: options.Index().SetName("family_id"),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("user_id"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0).SetName("ttl_expires_at"),
+		},
+	}
+
+	if _, err := col.Indexes().CreateMany(ctx, indexes); err != nil {
+		return nil, fmt.Errorf("create refresh token indexes: %w", err)
+	}
+
+	return &RefreshTokenRepository{collection: col}, nil
+}
+
+type mongoRefreshToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    string             `bson:"user_id"`
+	FamilyID  string             `bson:"family_id"`
+	TokenHash string             `bson:"token_hash"`
+	CreatedAt time.Time          `bson:"created_at"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+	RotatedAt *time.Time         `bson:"rotated_at,omitempty"`
+	RevokedAt *time.Time         `bson:"revoked_at,omitempty"`
+}
+
+func refreshTokenToDomain(mt mongoRefreshToken) domain.RefreshToken {
+	return domain.RefreshToken{
+		ID:        mt.ID.Hex(),
+		UserID:    mt.UserID,
+		FamilyID:  mt.FamilyID,
+		TokenHash: mt.TokenHash,
+		CreatedAt: mt.CreatedAt,
+		ExpiresAt: mt.ExpiresAt,
+		RotatedAt: mt.RotatedAt,
+		RevokedAt: mt.RevokedAt,
+	}
+}
+
+// Create persists a new refresh token.
+func (r *RefreshTokenRepository) Create(ctx context.Context, token domain.RefreshToken) (domain.RefreshToken, error) {
+	doc := mongoRefreshToken{
+		UserID:    token.UserID,
+		FamilyID:  token.FamilyID,
+		TokenHash: token.TokenHash,
+		CreatedAt: token.CreatedAt,
+		ExpiresAt: token.ExpiresAt,
+	}
+	result, err := r.collection.InsertOne(ctx, doc)
+	if err != nil {
+		return domain.RefreshToken{}, err
+	}
+
+	id, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return domain.RefreshToken{}, errors.New("unexpected inserted id type")
+	}
+	token.ID = id.Hex()
+	return token, nil
+}
+
+// GetByHash retrieves a refresh token by the hash of its value.
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, hash string) (domain.RefreshToken, error) {
+	var mt mongoRefreshToken
+	err := r.collection.FindOne(ctx, bson.M{"token_hash": hash}).Decode(&mt)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.RefreshToken{}, application.ErrNotFound
+		}
+		return domain.RefreshToken{}, err
+	}
+	return refreshTokenToDomain(mt), nil
+}
+
+// MarkRotated flags a token as used, failing if another request got there first.
+func (r *RefreshTokenRepository) MarkRotated(ctx context.Context, id string, at time.Time) error {
+	oid, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": oid, "rotated_at": bson.M{"$exists": false}}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"rotated_at": at}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return application.ErrRefreshTokenReused
+	}
+	return nil
+}
+
+// RevokeFamily revokes every live token sharing the family id.
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	filter := bson.M{"family_id": familyID, "revoked_at": bson.M{"$exists": false}}
+	_, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revoked_at": at}})
+	return err
+}
+
+// RevokeUser revokes every live token issued to the user.
+func (r *RefreshTokenRepository) RevokeUser(ctx context.Context, userID string, at time.Time) error {
+	filter := bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}}
+	_, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revoked_at": at}})
+	return err
+}
+
+// DeleteUser removes every token issued to the user.
+func (r *RefreshTokenRepository) DeleteUser(ctx context.Context, userID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
@@ -0,0 +1,96 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	revokedTokensCollection = "revoked_tokens"
+	tokenCutoffsCollection  = "token_cutoffs"
+)
+
+// TokenRevocationStore is a Mongo-backed implementation of application.TokenRevocationStore.
+type TokenRevocationStore struct {
+	tokens  *mongo.Collection
+	cutoffs *mongo.Collection
+}
+
+// NewTokenRevocationStore constructs a store. Revoked token ids are removed by
+// a TTL index once the token would have expired anyway.
+func NewTokenRevocationStore(db *mongo.Database) (*TokenRevocationStore, error) {
+	tokens := db.Collection(revokedTokensCollection)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0).SetName("ttl_expires_at"),
+	}
+
+	if _, err := tokens.Indexes().CreateOne(ctx, indexModel); err != nil {
+		return nil, fmt.Errorf("create revoked token index: %w", err)
+	}
+
+	return &TokenRevocationStore{
+		tokens:  tokens,
+		cutoffs: db.Collection(tokenCutoffsCollection),
+	}, nil
+}
+
+type mongoRevokedToken struct {
+	ID        string    `bson:"_id"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+type mongoTokenCutoff struct {
+	UserID        string    `bson:"_id"`
+	RevokedBefore time.Time `bson:"revoked_before"`
+}
+
+// RevokeToken records a token id until its expiry.
+func (s *TokenRevocationStore) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	opts := options.Replace().SetUpsert(true)
+	_, err := s.tokens.ReplaceOne(ctx, bson.M{"_id": tokenID}, mongoRevokedToken{ID: tokenID, ExpiresAt: expiresAt}, opts)
+	return err
+}
+
+// IsTokenRevoked reports whether the token id was revoked and has not yet expired.
+func (s *TokenRevocationStore) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	filter := bson.M{"_id": tokenID, "expires_at": bson.M{"$gt": time.Now().UTC()}}
+	err := s.tokens.FindOne(ctx, filter).Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// RevokeUserTokens moves the user's cutoff forward.
+func (s *TokenRevocationStore) RevokeUserTokens(ctx context.Context, userID string, before time.Time) error {
+	opts := options.Update().SetUpsert(true)
+	_, err := s.cutoffs.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{"$max": bson.M{"revoked_before": before}}, opts)
+	return err
+}
+
+// UserTokensRevokedBefore returns the user's cutoff, or the zero time if none.
+func (s *TokenRevocationStore) UserTokensRevokedBefore(ctx context.Context, userID string) (time.Time, error) {
+	var doc mongoTokenCutoff
+	err := s.cutoffs.FindOne(ctx, bson.M{"_id": userID}).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	return doc.RevokedBefore, nil
+}
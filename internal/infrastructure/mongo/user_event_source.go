@@ -0,0 +1,189 @@
+package mongo
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Change stream error codes for a resume token whose position is no longer
+// in the oplog, or that cannot be resumed from.
+const (
+	changeStreamFatalError  = 280
+	changeStreamHistoryLost = 286
+)
+
+// profileFields are the document fields whose changes are reported as
+// updates. MFA is checked separately by changesMFA; bookkeeping writes to its
+// nested fields, and password changes, are skipped.
+var profileFields = []string{"name", "email", "roles", "verified_at"}
+
+// UserEventSource reads user changes from a Mongo change stream on the users
+// collection, so every instance sees changes made by any other. Resume tokens
+// are the change stream's own. Change streams need a replica set.
+type UserEventSource struct {
+	collection *mongo.Collection
+}
+
+// NewUserEventSource builds a source watching the users collection.
+func NewUserEventSource(db *mongo.Database) *UserEventSource {
+	return &UserEventSource{collection: db.Collection(usersCollection)}
+}
+
+type userChangeEvent struct {
+	OperationType string              `bson:"operationType"`
+	ClusterTime   primitive.Timestamp `bson:"clusterTime"`
+	FullDocument  *mongoUser          `bson:"fullDocument"`
+	DocumentKey   struct {
+		ID primitive.ObjectID `bson:"_id"`
+	} `bson:"documentKey"`
+	UpdateDescription struct {
+		UpdatedFields bson.M   `bson:"updatedFields"`
+		RemovedFields []string `bson:"removedFields"`
+	} `bson:"updateDescription"`
+}
+
+func (s *UserEventSource) Watch(ctx context.Context, resumeToken string, fn func(application.UserChange) error) error {
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if resumeToken != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(resumeToken)
+		if err != nil || bson.Raw(raw).Validate() != nil {
+			return application.ErrInvalidResumeToken
+		}
+		opts.SetResumeAfter(bson.Raw(raw))
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{
+		"operationType": bson.M{"$in": bson.A{"insert", "update", "replace", "delete"}},
+	}}}}
+	stream, err := s.collection.Watch(ctx, pipeline, opts)
+	if err != nil {
+		return changeStreamError(err)
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		var change userChangeEvent
+		if err := stream.Decode(&change); err != nil {
+			return err
+		}
+		event, ok := change.toDomain()
+		if !ok {
+			continue
+		}
+		token := base64.RawURLEncoding.EncodeToString(stream.ResumeToken())
+		if err := fn(application.UserChange{Event: event, ResumeToken: token}); err != nil {
+			return err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return changeStreamError(stream.Err())
+}
+
+// toDomain maps a change to a user event. It reports false for updates that
+// do not touch the user's profile.
+func (c userChangeEvent) toDomain() (domain.UserEvent, bool) {
+	event := domain.UserEvent{
+		UserID:     c.DocumentKey.ID.Hex(),
+		OccurredAt: time.Unix(int64(c.ClusterTime.T), 0).UTC(),
+	}
+
+	switch c.OperationType {
+	case "insert":
+		event.Type = domain.UserCreated
+	case "delete":
+		// Purging a soft-deleted user reports its deletion a second time;
+		// watchers treat deletions as idempotent.
+		event.Type = domain.UserDeleted
+		return event, true
+	case "update":
+		switch {
+		case c.sets("deleted_at"):
+			event.Type = domain.UserDeleted
+			return event, true
+		case c.removes("deleted_at"):
+			// A restored user reappears as if it had just been created.
+			event.Type = domain.UserCreated
+		case c.touchesProfile():
+			event.Type = domain.UserUpdated
+		default:
+			return domain.UserEvent{}, false
+		}
+	default:
+		event.Type = domain.UserUpdated
+	}
+
+	// The document is gone if it was deleted before the update was looked up;
+	// the delete follows in the stream.
+	if c.FullDocument == nil {
+		return domain.UserEvent{}, false
+	}
+	event.User = toDomain(*c.FullDocument)
+	return event, true
+}
+
+func (c userChangeEvent) touchesProfile() bool {
+	for _, field := range profileFields {
+		if c.sets(field) || c.removes(field) {
+			return true
+		}
+	}
+	return c.changesMFA()
+}
+
+// changesMFA reports whether the write turned two-factor authentication on
+// or off, as the MFA service publishes it. The MFA document is written whole:
+// with enabled_at when enrolment is confirmed, and removed when it is
+// disabled. Starting an enrolment writes it without enabled_at, which leaves
+// mfaEnabled as it was.
+func (c userChangeEvent) changesMFA() bool {
+	if c.removes("mfa") {
+		return true
+	}
+	value, ok := c.UpdateDescription.UpdatedFields["mfa"]
+	if !ok {
+		return false
+	}
+	raw, err := bson.Marshal(value)
+	if err != nil {
+		return false
+	}
+	var mfa mongoMFA
+	if err := bson.Unmarshal(raw, &mfa); err != nil {
+		return false
+	}
+	return mfa.EnabledAt != nil
+}
+
+func (c userChangeEvent) sets(field string) bool {
+	_, ok := c.UpdateDescription.UpdatedFields[field]
+	return ok
+}
+
+func (c userChangeEvent) removes(field string) bool {
+	for _, removed := range c.UpdateDescription.RemovedFields {
+		if removed == field {
+			return true
+		}
+	}
+	return false
+}
+
+func changeStreamError(err error) error {
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) && (serverErr.HasErrorCode(changeStreamHistoryLost) || serverErr.HasErrorCode(changeStreamFatalError)) {
+		return application.ErrInvalidResumeToken
+	}
+	return err
+}
@@ -17,45 +17,182 @@ import (
 
 const usersCollection = "users"
 
+// listCollation compares strings case-insensitively. List queries and the
+// name and email list indexes must share it for the indexes to be used, and
+// domain.UserSort orders the in-memory store the same way.
+var listCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// sortFields maps list sort fields to document fields.
+var sortFields = map[domain.UserSortField]string{
+	domain.SortByCreatedAt: "created_at",
+	domain.SortByName:      "name",
+	domain.SortByEmail:     "email",
+}
+
+// publicFields maps the fields of domain.UserPublic to the document fields
+// they are derived from.
+var publicFields = map[domain.UserField]string{
+	domain.FieldID:            "_id",
+	domain.FieldName:          "name",
+	domain.FieldEmail:         "email",
+	domain.FieldRoles:         "roles",
+	domain.FieldEmailVerified: "verified_at",
+	domain.FieldMFAEnabled:    "mfa.enabled_at",
+	domain.FieldCreatedAt:     "created_at",
+	domain.FieldDeletedAt:     "deleted_at",
+}
+
 // UserRepository is a Mongo-backed implementation of application.UserRepository.
 type UserRepository struct {
-	collection *mongo.Collection
+	collection    *mongo.Collection
+	unverifiedTTL time.Duration
 }
 
-// NewUserRepository constructs a repository and sets indices.
-func NewUserRepository(db *mongo.Database) (*UserRepository, error) {
+// UserRepositoryOption customises a UserRepository.
+type UserRepositoryOption func(*UserRepository)
+
+// WithUnverifiedAccountTTL makes Mongo delete accounts that have not verified
+// their email within ttl of registering. Zero keeps them forever.
+func WithUnverifiedAccountTTL(ttl time.Duration) UserRepositoryOption {
+	return func(r *UserRepository) {
+		r.unverifiedTTL = ttl
+	}
+}
+
+// NewUserRepository constructs a repository and sets indices. Unverified
+// accounts carry a verify_by deadline that a TTL index expires; verifying
+// removes the deadline.
+func NewUserRepository(db *mongo.Database, opts ...UserRepositoryOption) (*UserRepository, error) {
 	col := db.Collection(usersCollection)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	indexModel := mongo.IndexModel{
-		Keys:    bson.D{{Key: "email", Value: 1}},
-		Options: options.Index().SetUnique(true).SetName("unique_email"),
+	indexes := []mongo.IndexModel{
+		{
+			// Documents without deleted_at index it as null, so emails are
+			// unique among users that are not deleted, while any number of
+			// deleted users may share one.
+			Keys:    bson.D{{Key: "email", Value: 1}, {Key: "deleted_at", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("unique_active_email"),
+		},
+		{
+			Keys: bson.D{{Key: "deleted_at", Value: 1}},
+			Options: options.Index().
+				SetPartialFilterExpression(bson.M{"deleted_at": bson.M{"$exists": true}}).
+				SetName("purge_deleted_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "verify_by", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0).SetName("ttl_verify_by"),
+		},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("list_order"),
+		},
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index().SetCollation(listCollation).SetName("list_name"),
+		},
+		{
+			Keys:    bson.D{{Key: "email", Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index().SetCollation(listCollation).SetName("list_email"),
+		},
+	}
+
+	if _, err := col.Indexes().CreateMany(ctx, indexes); err != nil {
+		return nil, fmt.Errorf("create user indexes: %w", err)
+	}
+	// unique_email predates soft deletes and would stop a deleted user's
+	// email from being registered again.
+	if _, err := col.Indexes().DropOne(ctx, "unique_email"); err != nil && !isIndexNotFound(err) {
+		return nil, fmt.Errorf("drop unique_email index: %w", err)
 	}
 
-	if _, err := col.Indexes().CreateOne(ctx, indexModel); err != nil {
-		return nil, fmt.Errorf("create email index: %w", err)
+	// Accounts created before email verification existed have no verified_at
+	// field at all; treat them as verified so they are not locked out.
+	// Unverified accounts store an explicit null and are left alone.
+	grandfather := mongo.Pipeline{{{Key: "$set", Value: bson.M{"verified_at": "$created_at"}}}}
+	if _, err := col.UpdateMany(ctx, bson.M{"verified_at": bson.M{"$exists": false}}, grandfather); err != nil {
+		return nil, fmt.Errorf("backfill verified_at: %w", err)
 	}
 
-	return &UserRepository{collection: col}, nil
+	repo := &UserRepository{collection: col}
+	for _, opt := range opts {
+		opt(repo)
+	}
+	return repo, nil
 }
 
 type mongoUser struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty"`
-	Name      string             `bson:"name"`
-	Email     string             `bson:"email"`
-	Password  string             `bson:"password"`
-	CreatedAt time.Time          `bson:"created_at"`
+	ID                primitive.ObjectID `bson:"_id,omitempty"`
+	Name              string             `bson:"name"`
+	Email             string             `bson:"email"`
+	Password          string             `bson:"password"`
+	Roles             []domain.Role      `bson:"roles,omitempty"`
+	CreatedAt         time.Time          `bson:"created_at"`
+	VerifiedAt        *time.Time         `bson:"verified_at"`
+	VerifyBy          *time.Time         `bson:"verify_by,omitempty"`
+	PasswordChangedAt *time.Time         `bson:"password_changed_at,omitempty"`
+	MFA               *mongoMFA          `bson:"mfa,omitempty"`
+	DeletedAt         *time.Time         `bson:"deleted_at,omitempty"`
+	// Version is missing, and reads as 0, on documents written before
+	// versioning.
+	Version int64 `bson:"version"`
+}
+
+type mongoMFA struct {
+	Secret        string     `bson:"secret"`
+	EnabledAt     *time.Time `bson:"enabled_at,omitempty"`
+	RecoveryCodes []string   `bson:"recovery_codes"`
+	LastStep      int64      `bson:"last_step"`
+}
+
+func toMongoMFA(m domain.MFA) *mongoMFA {
+	if m.Secret == "" {
+		return nil
+	}
+	codes := m.RecoveryCodes
+	if codes == nil {
+		codes = []string{}
+	}
+	return &mongoMFA{
+		Secret:        m.Secret,
+		EnabledAt:     m.EnabledAt,
+		RecoveryCodes: codes,
+		LastStep:      m.LastStep,
+	}
+}
+
+func (m *mongoMFA) toDomain() domain.MFA {
+	if m == nil {
+		return domain.MFA{}
+	}
+	return domain.MFA{
+		Secret:        m.Secret,
+		EnabledAt:     m.EnabledAt,
+		RecoveryCodes: m.RecoveryCodes,
+		LastStep:      m.LastStep,
+	}
 }
 
 func toDomain(mu mongoUser) domain.User {
+	// Accounts created before roles existed are regular users.
+	if len(mu.Roles) == 0 {
+		mu.Roles = []domain.Role{domain.RoleUser}
+	}
 	return domain.User{
-		ID:        mu.ID.Hex(),
-		Name:      mu.Name,
-		Email:     mu.Email,
-		Password:  mu.Password,
-		CreatedAt: mu.CreatedAt,
+		ID:                mu.ID.Hex(),
+		Name:              mu.Name,
+		Email:             mu.Email,
+		Password:          mu.Password,
+		Roles:             mu.Roles,
+		CreatedAt:         mu.CreatedAt,
+		VerifiedAt:        mu.VerifiedAt,
+		PasswordChangedAt: mu.PasswordChangedAt,
+		MFA:               mu.MFA.toDomain(),
+		DeletedAt:         mu.DeletedAt,
+		Version:           mu.Version,
 	}
 }
 
@@ -67,14 +204,36 @@ func fromDomain(u domain.User) mongoUser {
 		}
 	}
 	return mongoUser{
-		ID:        id,
-		Name:      u.Name,
-		Email:     u.Email,
-		Password:  u.Password,
-		CreatedAt: u.CreatedAt,
+		ID:                id,
+		Name:              u.Name,
+		Email:             u.Email,
+		Password:          u.Password,
+		Roles:             u.Roles,
+		CreatedAt:         u.CreatedAt,
+		VerifiedAt:        u.VerifiedAt,
+		PasswordChangedAt: u.PasswordChangedAt,
+		MFA:               toMongoMFA(u.MFA),
+		DeletedAt:         u.DeletedAt,
+		Version:           u.Version,
 	}
 }
 
+// indexNotFound is the server error code for dropping a missing index.
+const indexNotFound = 27
+
+func isIndexNotFound(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(indexNotFound)
+}
+
+// active matches the user with id unless it has been deleted.
+func active(id primitive.ObjectID) bson.M {
+	return bson.M{"_id": id, "deleted_at": nil}
+}
+
+// bumpVersion is the update operator that moves a user to its next version.
+var bumpVersion = bson.M{"version": 1}
+
 func parseID(id string) (primitive.ObjectID, error) {
 	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -85,7 +244,12 @@ func parseID(id string) (primitive.ObjectID, error) {
 
 // Create persists a new user document.
 func (r *UserRepository) Create(ctx context.Context, user domain.User) (domain.User, error) {
+	user.Version = 1
 	doc := fromDomain(user)
+	if doc.VerifiedAt == nil && r.unverifiedTTL > 0 {
+		deadline := user.CreatedAt.Add(r.unverifiedTTL)
+		doc.VerifyBy = &deadline
+	}
 	result, err := r.collection.InsertOne(ctx, doc)
 	if err != nil {
 		if mongo.IsDuplicateKeyError(err) {
@@ -105,7 +269,7 @@ func (r *UserRepository) Create(ctx context.Context, user domain.User) (domain.U
 // GetByEmail retrieves a user by email.
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (domain.User, error) {
 	var mu mongoUser
-	err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&mu)
+	err := r.collection.FindOne(ctx, bson.M{"email": email, "deleted_at": nil}).Decode(&mu)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return domain.User{}, application.ErrNotFound
@@ -123,7 +287,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (domain.User, e
 	}
 
 	var mu mongoUser
-	err = r.collection.FindOne(ctx, bson.M{"_id": oid}).Decode(&mu)
+	err = r.collection.FindOne(ctx, active(oid)).Decode(&mu)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return domain.User{}, application.ErrNotFound
@@ -133,11 +297,78 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (domain.User, e
 	return toDomain(mu), nil
 }
 
-// List returns all users sorted by creation time descending.
-func (r *UserRepository) List(ctx context.Context) ([]domain.User, error) {
-	cursor, err := r.collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+// List returns a page of users in the query's order, with ties broken by id
+// so pages stay stable while users are added. Searches are prefix ranges over
+// the collated name and email indexes. When the query names fields, only the
+// documents' matching fields are fetched.
+func (r *UserRepository) List(ctx context.Context, query domain.UserListQuery) (domain.UserPage, error) {
+	order := query.SortOrDefault()
+	field, ok := sortFields[order.Field]
+	if !ok {
+		return domain.UserPage{}, domain.ErrInvalidSort
+	}
+	dir, op := 1, "$gt"
+	if order.Descending {
+		dir, op = -1, "$lt"
+	}
+
+	filter := bson.A{}
+	if !query.Filter.IncludeDeleted {
+		filter = append(filter, bson.M{"deleted_at": nil})
+	}
+	if search := query.Filter.Search; search != "" {
+		// U+FFFF sorts after every other character under the collation, so
+		// this range holds exactly the values starting with search.
+		prefix := bson.M{"$gte": search, "$lt": search + "\uffff"}
+		filter = append(filter, bson.M{"$or": bson.A{bson.M{"name": prefix}, bson.M{"email": prefix}}})
+	}
+	if after := query.Filter.CreatedAfter; after != nil {
+		filter = append(filter, bson.M{"created_at": bson.M{"$gt": *after}})
+	}
+	if before := query.Filter.CreatedBefore; before != nil {
+		filter = append(filter, bson.M{"created_at": bson.M{"$lt": *before}})
+	}
+	if query.After != nil {
+		oid, err := primitive.ObjectIDFromHex(query.After.ID)
+		if err != nil {
+			return domain.UserPage{}, application.ErrInvalidCursor
+		}
+		var key interface{} = query.After.Key
+		if order.Field == domain.SortByCreatedAt {
+			key = query.After.CreatedAt
+		}
+		filter = append(filter, bson.M{"$or": bson.A{
+			bson.M{field: bson.M{op: key}},
+			bson.M{field: key, "_id": bson.M{op: oid}},
+		}})
+	}
+
+	where := bson.M{}
+	if len(filter) > 0 {
+		where["$and"] = filter
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: field, Value: dir}, {Key: "_id", Value: dir}}).
+		SetCollation(listCollation)
+	if len(query.Fields) > 0 {
+		// The id and sort key are always needed to build the next cursor,
+		// and the version to build ETags.
+		projection := bson.M{"_id": 1, field: 1, "version": 1}
+		for _, f := range query.Fields {
+			if doc, ok := publicFields[f]; ok {
+				projection[doc] = 1
+			}
+		}
+		opts.SetProjection(projection)
+	}
+	if query.Limit > 0 {
+		// Fetch one extra document to learn whether another page follows.
+		opts.SetLimit(int64(query.Limit) + 1)
+	}
+
+	cursor, err := r.collection.Find(ctx, where, opts)
 	if err != nil {
-		return nil, err
+		return domain.UserPage{}, err
 	}
 	defer cursor.Close(ctx)
 
@@ -145,43 +376,71 @@ func (r *UserRepository) List(ctx context.Context) ([]domain.User, error) {
 	for cursor.Next(ctx) {
 		var mu mongoUser
 		if err := cursor.Decode(&mu); err != nil {
-			return nil, err
+			return domain.UserPage{}, err
 		}
 		users = append(users, toDomain(mu))
 	}
 
 	if err := cursor.Err(); err != nil {
-		return nil, err
+		return domain.UserPage{}, err
 	}
 
-	return users, nil
+	var page domain.UserPage
+	if query.Limit > 0 && len(users) > query.Limit {
+		users = users[:query.Limit]
+		next := order.CursorOf(users[len(users)-1])
+		page.Next = &next
+	}
+	page.Users = users
+	return page, nil
 }
 
-// Update modifies email and/or name for a user.
+// Update modifies email and/or name for a user. A new email is unconfirmed:
+// verified_at is cleared and the cleanup deadline starts again. With IfVersion
+// set, the version is part of the filter, so a concurrent change makes it
+// match nothing instead of being overwritten.
 func (r *UserRepository) Update(ctx context.Context, id string, update domain.UpdateUser) (domain.User, error) {
 	oid, err := parseID(id)
 	if err != nil {
 		return domain.User{}, err
 	}
 
-	set := bson.M{}
+	if update.Name == nil && update.Email == nil {
+		return domain.User{}, application.ErrNoFieldsToUpdate
+	}
+
+	// A pipeline update, so the verification fields can depend on whether the
+	// email differs from the stored one. Every expression in the stage sees
+	// the document as it was before the update.
+	set := bson.M{"version": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$version", 0}}, 1}}}
 	if update.Name != nil {
-		set["name"] = *update.Name
+		set["name"] = bson.M{"$literal": *update.Name}
 	}
 	if update.Email != nil {
-		set["email"] = *update.Email
+		email := bson.M{"$literal": *update.Email}
+		unchanged := bson.M{"$eq": bson.A{"$email", email}}
+		set["email"] = email
+		set["verified_at"] = bson.M{"$cond": bson.A{unchanged, "$verified_at", nil}}
+		if r.unverifiedTTL > 0 {
+			deadline := bson.M{"$add": bson.A{"$$NOW", r.unverifiedTTL.Milliseconds()}}
+			set["verify_by"] = bson.M{"$cond": bson.A{unchanged, "$verify_by", deadline}}
+		}
 	}
 
-	if len(set) == 0 {
-		return domain.User{}, application.ErrNoFieldsToUpdate
+	filter := active(oid)
+	if update.IfVersion != nil {
+		filter["version"] = *update.IfVersion
+		if *update.IfVersion == 0 {
+			filter["version"] = nil
+		}
 	}
 
 	var mu mongoUser
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
-	err = r.collection.FindOneAndUpdate(ctx, bson.M{"_id": oid}, bson.M{"$set": set}, opts).Decode(&mu)
+	err = r.collection.FindOneAndUpdate(ctx, filter, mongo.Pipeline{{{Key: "$set", Value: set}}}, opts).Decode(&mu)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return domain.User{}, application.ErrNotFound
+			return domain.User{}, r.missOrMismatch(ctx, oid, update.IfVersion)
 		}
 		if mongo.IsDuplicateKeyError(err) {
 			return domain.User{}, application.ErrDuplicateEmail
@@ -192,24 +451,249 @@ func (r *UserRepository) Update(ctx context.Context, id string, update domain.Up
 	return toDomain(mu), nil
 }
 
-// Delete removes a user.
-func (r *UserRepository) Delete(ctx context.Context, id string) error {
+// missOrMismatch explains why a conditional update matched nothing: the user
+// is gone, or it is at another version.
+func (r *UserRepository) missOrMismatch(ctx context.Context, oid primitive.ObjectID, ifVersion *int64) error {
+	if ifVersion == nil {
+		return application.ErrNotFound
+	}
+	n, err := r.collection.CountDocuments(ctx, active(oid), options.Count().SetLimit(1))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return application.ErrNotFound
+	}
+	return application.ErrVersionMismatch
+}
+
+// UpdatePassword replaces the stored password hash and records when it changed.
+func (r *UserRepository) UpdatePassword(ctx context.Context, id, passwordHash string, changedAt time.Time) error {
 	oid, err := parseID(id)
 	if err != nil {
 		return err
 	}
 
-	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": oid})
+	result, err := r.collection.UpdateOne(ctx, active(oid), bson.M{"$set": bson.M{"password": passwordHash, "password_changed_at": changedAt}})
 	if err != nil {
 		return err
 	}
-	if result.DeletedCount == 0 {
+	if result.MatchedCount == 0 {
 		return application.ErrNotFound
 	}
 	return nil
 }
 
-// Count returns the total number of users.
+// UpdateRoles replaces the user's roles.
+func (r *UserRepository) UpdateRoles(ctx context.Context, id string, roles []domain.Role) error {
+	oid, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.UpdateOne(ctx, active(oid), bson.M{"$set": bson.M{"roles": roles}, "$inc": bumpVersion})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return application.ErrNotFound
+	}
+	return nil
+}
+
+// ReplacePasswordHash swaps in an upgraded hash for the same password. The
+// filter on the old hash keeps it from overwriting a concurrent change.
+func (r *UserRepository) ReplacePasswordHash(ctx context.Context, id, oldHash, newHash string) error {
+	oid, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	filter := active(oid)
+	filter["password"] = oldHash
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"password": newHash}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return application.ErrNotFound
+	}
+	return nil
+}
+
+// MarkVerified records that the user confirmed their email and clears the
+// cleanup deadline. Verifying twice keeps the first timestamp and version.
+func (r *UserRepository) MarkVerified(ctx context.Context, id string, at time.Time) (domain.User, error) {
+	oid, err := parseID(id)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"version": bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{"$verified_at", nil}}, nil}},
+			bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$version", 0}}, 1}},
+			"$version",
+		}}}}},
+		{{Key: "$set", Value: bson.M{"verified_at": bson.M{"$ifNull": bson.A{"$verified_at", at}}}}},
+		{{Key: "$unset", Value: "verify_by"}},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var mu mongoUser
+	if err := r.collection.FindOneAndUpdate(ctx, active(oid), update, opts).Decode(&mu); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.User{}, application.ErrNotFound
+		}
+		return domain.User{}, err
+	}
+	return toDomain(mu), nil
+}
+
+// UpdateMFA replaces the user's two-factor state, removing it entirely when
+// the secret is empty.
+func (r *UserRepository) UpdateMFA(ctx context.Context, id string, mfa domain.MFA) error {
+	oid, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$unset": bson.M{"mfa": ""}, "$inc": bumpVersion}
+	if doc := toMongoMFA(mfa); doc != nil {
+		update = bson.M{"$set": bson.M{"mfa": doc}, "$inc": bumpVersion}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, active(oid), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return application.ErrNotFound
+	}
+	return nil
+}
+
+// UseTOTPStep advances the last accepted TOTP step. The filter only matches
+// while the stored step is older, so two requests racing with the same code
+// cannot both succeed.
+func (r *UserRepository) UseTOTPStep(ctx context.Context, id string, step int64) error {
+	oid, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	filter := active(oid)
+	filter["mfa.last_step"] = bson.M{"$lt": step}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"mfa.last_step": step}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return application.ErrNotFound
+	}
+	return nil
+}
+
+// UseRecoveryCode removes a recovery code hash, failing when it is not present.
+func (r *UserRepository) UseRecoveryCode(ctx context.Context, id, codeHash string) error {
+	oid, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	filter := active(oid)
+	filter["mfa.recovery_codes"] = codeHash
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$pull": bson.M{"mfa.recovery_codes": codeHash}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return application.ErrNotFound
+	}
+	return nil
+}
+
+// Delete marks a user deleted. The document stays until Purge removes it.
+func (r *UserRepository) Delete(ctx context.Context, id string, at time.Time) error {
+	oid, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.UpdateOne(ctx, active(oid), bson.M{"$set": bson.M{"deleted_at": at}, "$inc": bumpVersion})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return application.ErrNotFound
+	}
+	return nil
+}
+
+// Restore clears a user's deletion. The unique email index refuses it when
+// another user has taken the email meanwhile.
+func (r *UserRepository) Restore(ctx context.Context, id string) (domain.User, error) {
+	oid, err := parseID(id)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	filter := bson.M{"_id": oid, "deleted_at": bson.M{"$ne": nil}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var mu mongoUser
+	err = r.collection.FindOneAndUpdate(ctx, filter, bson.M{"$unset": bson.M{"deleted_at": ""}, "$inc": bumpVersion}, opts).Decode(&mu)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.User{}, application.ErrNotFound
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.User{}, application.ErrDuplicateEmail
+		}
+		return domain.User{}, err
+	}
+	return toDomain(mu), nil
+}
+
+// Purge removes users deleted before the given time. Each user is removed
+// with its own filter, so one restored in the meantime is kept and left out
+// of the result.
+func (r *UserRepository) Purge(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	filter := bson.M{"deleted_at": bson.M{"$lt": deletedBefore}}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var candidates []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, doc.ID)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	var purged []string
+	for _, oid := range candidates {
+		result, err := r.collection.DeleteOne(ctx, bson.M{"_id": oid, "deleted_at": bson.M{"$lt": deletedBefore}})
+		if err != nil {
+			return purged, err
+		}
+		if result.DeletedCount == 1 {
+			purged = append(purged, oid.Hex())
+		}
+	}
+	return purged, nil
+}
+
+// Count returns the number of users that are not deleted.
 func (r *UserRepository) Count(ctx context.Context) (int64, error) {
-	return r.collection.CountDocuments(ctx, bson.D{})
+	return r.collection.CountDocuments(ctx, bson.M{"deleted_at": nil})
 }
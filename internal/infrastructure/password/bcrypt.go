@@ -0,0 +1,32 @@
+package password
+
+import (
+	"strings"
+
+	"backend-challenge/internal/application"
+)
+
+// Bcrypt hashes passwords with bcrypt at Cost, using application.BcryptHasher.
+type Bcrypt struct {
+	Cost int
+}
+
+// Hash returns a bcrypt hash in the usual $2a$ modular crypt format.
+func (b Bcrypt) Hash(password string) (string, error) {
+	return application.BcryptHasher{Cost: b.Cost}.Hash(password)
+}
+
+// Verify checks password and asks for a rehash when hash used another cost.
+func (b Bcrypt) Verify(hash, password string) (bool, error) {
+	return application.BcryptHasher{Cost: b.Cost}.Verify(hash, password)
+}
+
+// Recognizes reports whether hash is a bcrypt hash.
+func (Bcrypt) Recognizes(hash string) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		if strings.HasPrefix(hash, prefix) {
+			return true
+		}
+	}
+	return false
+}
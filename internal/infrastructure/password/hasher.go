@@ -0,0 +1,75 @@
+// Package password implements application.PasswordHasher with bcrypt and
+// argon2id. Every hash records its algorithm and parameters, so hashes made
+// under an earlier configuration keep verifying and can be upgraded on login.
+package password
+
+import (
+	"errors"
+	"runtime"
+
+	"backend-challenge/internal/application"
+)
+
+// ErrUnknownFormat indicates a stored hash was not produced by any supported
+// algorithm.
+var ErrUnknownFormat = errors.New("unrecognised password hash format")
+
+// Algorithm is a single hashing scheme.
+type Algorithm interface {
+	application.PasswordHasher
+	// Recognizes reports whether hash was produced by this algorithm.
+	Recognizes(hash string) bool
+}
+
+// Hasher hashes new passwords with a preferred algorithm and verifies hashes
+// from any supported one. Verify asks for a rehash whenever the stored hash
+// was made by a different algorithm or with outdated parameters.
+//
+// Hashing is deliberately expensive, and argon2id holds its memory cost for
+// the whole computation, so at most a fixed number of Hash and Verify calls
+// run at once; the rest wait for a slot.
+type Hasher struct {
+	preferred Algorithm
+	supported []Algorithm
+	slots     chan struct{}
+}
+
+// New builds a Hasher that writes with preferred and also accepts hashes from
+// the other supported algorithms. concurrency bounds how many hashes are
+// computed at once; zero or less means one per CPU.
+func New(concurrency int, preferred Algorithm, supported ...Algorithm) *Hasher {
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	return &Hasher{preferred: preferred, supported: supported, slots: make(chan struct{}, concurrency)}
+}
+
+// Hash hashes password with the preferred algorithm.
+func (h *Hasher) Hash(password string) (string, error) {
+	defer h.acquire()()
+	return h.preferred.Hash(password)
+}
+
+// Verify checks password against hash using whichever algorithm produced it.
+func (h *Hasher) Verify(hash, password string) (bool, error) {
+	defer h.acquire()()
+	if h.preferred.Recognizes(hash) {
+		return h.preferred.Verify(hash, password)
+	}
+	for _, algorithm := range h.supported {
+		if !algorithm.Recognizes(hash) {
+			continue
+		}
+		if _, err := algorithm.Verify(hash, password); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, ErrUnknownFormat
+}
+
+// acquire waits for a free slot and returns the func that gives it back.
+func (h *Hasher) acquire() func() {
+	h.slots <- struct{}{}
+	return func() { <-h.slots }
+}
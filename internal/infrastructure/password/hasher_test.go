@@ -0,0 +1,150 @@
+package password
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend-challenge/internal/application"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fastArgon2id keeps tests quick; the defaults take tens of milliseconds.
+var fastArgon2id = Argon2id{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2idRoundTrip(t *testing.T) {
+	hash, err := fastArgon2id.Hash("password123")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+	require.True(t, fastArgon2id.Recognizes(hash))
+
+	rehash, err := fastArgon2id.Verify(hash, "password123")
+	require.NoError(t, err)
+	require.False(t, rehash)
+
+	_, err = fastArgon2id.Verify(hash, "wrong")
+	require.ErrorIs(t, err, application.ErrPasswordMismatch)
+
+	other, err := fastArgon2id.Hash("password123")
+	require.NoError(t, err)
+	require.NotEqual(t, hash, other, "salts must differ")
+}
+
+func TestArgon2idRehashWhenParametersChange(t *testing.T) {
+	hash, err := fastArgon2id.Hash("password123")
+	require.NoError(t, err)
+
+	stronger := fastArgon2id
+	stronger.Iterations = 2
+	rehash, err := stronger.Verify(hash, "password123")
+	require.NoError(t, err)
+	require.True(t, rehash)
+}
+
+func TestArgon2idRejectsMalformedHash(t *testing.T) {
+	for _, hash := range []string{
+		"$argon2id$v=19$m=1024,t=1,p=1$salt",
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=x,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!$a2V5",
+	} {
+		_, err := fastArgon2id.Verify(hash, "password123")
+		require.Error(t, err, hash)
+	}
+}
+
+func TestHasherUpgradesBcrypt(t *testing.T) {
+	legacy, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	hasher := New(0, fastArgon2id, Bcrypt{Cost: bcrypt.MinCost})
+
+	rehash, err := hasher.Verify(string(legacy), "password123")
+	require.NoError(t, err)
+	require.True(t, rehash, "bcrypt hashes should move to the preferred algorithm")
+
+	_, err = hasher.Verify(string(legacy), "wrong")
+	require.ErrorIs(t, err, application.ErrPasswordMismatch)
+
+	fresh, err := hasher.Hash("password123")
+	require.NoError(t, err)
+	require.True(t, fastArgon2id.Recognizes(fresh))
+	rehash, err = hasher.Verify(fresh, "password123")
+	require.NoError(t, err)
+	require.False(t, rehash)
+
+	_, err = hasher.Verify("plaintext", "plaintext")
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestBcryptRehashWhenCostChanges(t *testing.T) {
+	hash, err := Bcrypt{Cost: bcrypt.MinCost}.Hash("password123")
+	require.NoError(t, err)
+
+	rehash, err := Bcrypt{Cost: bcrypt.MinCost}.Verify(hash, "password123")
+	require.NoError(t, err)
+	require.False(t, rehash)
+
+	rehash, err = Bcrypt{Cost: bcrypt.MinCost + 1}.Verify(hash, "password123")
+	require.NoError(t, err)
+	require.True(t, rehash)
+}
+
+// blockingAlgorithm holds every call until release is closed and records how
+// many ran at once.
+type blockingAlgorithm struct {
+	release chan struct{}
+	active  atomic.Int32
+	peak    atomic.Int32
+}
+
+func (a *blockingAlgorithm) Hash(password string) (string, error) {
+	n := a.active.Add(1)
+	defer a.active.Add(-1)
+	for {
+		peak := a.peak.Load()
+		if n <= peak || a.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	<-a.release
+	return "$blocking$" + password, nil
+}
+
+func (a *blockingAlgorithm) Verify(hash, password string) (bool, error) {
+	_, err := a.Hash(password)
+	return false, err
+}
+
+func (a *blockingAlgorithm) Recognizes(hash string) bool {
+	return strings.HasPrefix(hash, "$blocking$")
+}
+
+func TestHasherBoundsConcurrency(t *testing.T) {
+	algorithm := &blockingAlgorithm{release: make(chan struct{})}
+	hasher := New(2, algorithm)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				_, _ = hasher.Hash("password123")
+			} else {
+				_, _ = hasher.Verify("$blocking$password123", "password123")
+			}
+		}(i)
+	}
+
+	require.Eventually(t, func() bool { return algorithm.active.Load() == 2 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	require.EqualValues(t, 2, algorithm.active.Load(), "waiting calls must not start before a slot frees up")
+	close(algorithm.release)
+	wg.Wait()
+	require.EqualValues(t, 2, algorithm.peak.Load())
+}
@@ -1,13 +1,22 @@
 package authctx
 
-import "context"
+import (
+	"context"
+
+	"backend-challenge/internal/application"
+)
 
 type contextKey string
 
-const userIDKey contextKey = "userID"
+const (
+	userIDKey contextKey = "userID"
+	claimsKey contextKey = "claims"
+)
 
-// WithUserID injects the authenticated user ID into the context.
+// WithUserID injects the authenticated user ID into the context, and makes
+// that user, without any roles, the actor for application use cases.
 func WithUserID(ctx context.Context, userID string) context.Context {
+	ctx = application.WithActor(ctx, application.Actor{UserID: userID})
 	return context.WithValue(ctx, userIDKey, userID)
 }
 
@@ -16,3 +25,18 @@ func UserIDFromContext(ctx context.Context) (string, bool) {
 	val, ok := ctx.Value(userIDKey).(string)
 	return val, ok
 }
+
+// WithClaims injects verified token claims, including the user ID, into the
+// context, and makes the token's user and roles the actor for application use
+// cases.
+func WithClaims(ctx context.Context, claims application.AccessClaims) context.Context {
+	ctx = WithUserID(ctx, claims.UserID)
+	ctx = application.WithActor(ctx, application.ActorFromClaims(claims))
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+// ClaimsFromContext extracts verified token claims from context if present.
+func ClaimsFromContext(ctx context.Context) (application.AccessClaims, bool) {
+	val, ok := ctx.Value(claimsKey).(application.AccessClaims)
+	return val, ok
+}
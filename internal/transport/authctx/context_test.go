@@ -3,6 +3,9 @@ package authctx
 import (
 	"context"
 	"testing"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
 )
 
 func TestWithUserIDAndFromContext(t *testing.T) {
@@ -21,3 +24,24 @@ func TestWithUserIDAndFromContext(t *testing.T) {
 		t.Fatalf("expected no user id in fresh context")
 	}
 }
+
+func TestWithClaims(t *testing.T) {
+	ctx := WithClaims(context.Background(), application.AccessClaims{UserID: "user-123", TokenID: "jti"})
+
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok || claims.TokenID != "jti" {
+		t.Fatalf("expected claims in context got %+v", claims)
+	}
+	if id, ok := UserIDFromContext(ctx); !ok || id != "user-123" {
+		t.Fatalf("expected user id from claims got %s", id)
+	}
+
+	if _, ok := ClaimsFromContext(context.Background()); ok {
+		t.Fatalf("expected no claims in fresh context")
+	}
+
+	actor, ok := application.ActorFromContext(WithClaims(context.Background(), application.AccessClaims{UserID: "user-123", Roles: []domain.Role{domain.RoleAdmin}}))
+	if !ok || actor.UserID != "user-123" || !actor.HasRole(domain.RoleAdmin) {
+		t.Fatalf("expected claims to set the application actor got %+v", actor)
+	}
+}
@@ -2,11 +2,11 @@ package grpcsvc
 
 import (
 	"context"
+	"errors"
 	"strings"
 
-	jwtinfra "backend-challenge/internal/infrastructure/jwt"
+	"backend-challenge/internal/application"
 	"backend-challenge/internal/transport/authctx"
-	"backend-challenge/proto/userpb"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -14,46 +14,106 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-const (
-	fullMethodCreateUser = "/user.v1.UserService/CreateUser"
-)
-
-// AuthUnaryInterceptor enforces JWT authentication for gRPC calls.
-func AuthUnaryInterceptor(jwtManager *jwtinfra.Manager) grpc.UnaryServerInterceptor {
+// AuthUnaryInterceptor enforces the policy registered for each gRPC method.
+// Calls to methods without a policy are refused.
+func AuthUnaryInterceptor(sessions *application.SessionService, policies MethodPolicies) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		if info.FullMethod == fullMethodCreateUser {
+		policy, ok := policies[info.FullMethod]
+		if !ok {
+			return nil, status.Error(codes.PermissionDenied, "no auth policy for method")
+		}
+		if policy.kind == policyPublic {
 			return handler(ctx, req)
 		}
 
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			return nil, status.Error(codes.Unauthenticated, "missing metadata")
+		claims, err := authenticate(ctx, sessions)
+		if err != nil {
+			return nil, err
 		}
-
-		values := md.Get("authorization")
-		if len(values) == 0 {
-			return nil, status.Error(codes.Unauthenticated, "missing authorization metadata")
+		if err := policy.authorize(claims, req); err != nil {
+			return nil, err
 		}
+		ctx = authctx.WithClaims(ctx, claims)
+		return handler(ctx, req)
+	}
+}
 
-		token := values[0]
-		parts := strings.SplitN(token, " ", 2)
-		if len(parts) == 2 && strings.EqualFold(parts[0], "bearer") {
-			token = parts[1]
+// AuthStreamInterceptor enforces the policy registered for each streaming
+// gRPC method. Handlers see the caller through authctx on the stream's
+// context. Self-only policies are checked against every message received.
+func AuthStreamInterceptor(sessions *application.SessionService, policies MethodPolicies) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		policy, ok := policies[info.FullMethod]
+		if !ok {
+			return status.Error(codes.PermissionDenied, "no auth policy for method")
+		}
+		if policy.kind == policyPublic {
+			return handler(srv, stream)
 		}
 
-		userID, err := jwtManager.ValidateToken(token)
+		ctx := stream.Context()
+		claims, err := authenticate(ctx, sessions)
 		if err != nil {
-			return nil, status.Error(codes.Unauthenticated, "invalid token")
+			return err
 		}
-
-		switch info.FullMethod {
-		case "/user.v1.UserService/GetUser":
-			if getReq, ok := req.(*userpb.GetUserRequest); ok && getReq.GetId() != "" && getReq.GetId() != userID {
-				return nil, status.Error(codes.PermissionDenied, "forbidden")
+		wrapped := &authServerStream{ServerStream: stream, ctx: authctx.WithClaims(ctx, claims)}
+		if policy.kind == policySelf {
+			wrapped.authorize = func(req interface{}) error {
+				return policy.authorize(claims, req)
 			}
+		} else if err := policy.authorize(claims, nil); err != nil {
+			return err
 		}
+		return handler(srv, wrapped)
+	}
+}
 
-		ctx = authctx.WithUserID(ctx, userID)
-		return handler(ctx, req)
+// authServerStream carries the authenticated context to stream handlers.
+type authServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	// authorize, when set, is checked against each received message.
+	authorize func(req interface{}) error
+}
+
+func (s *authServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *authServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.authorize != nil {
+		return s.authorize(m)
+	}
+	return nil
+}
+
+// authenticate verifies the bearer token in the incoming metadata.
+func authenticate(ctx context.Context, sessions *application.SessionService) (application.AccessClaims, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return application.AccessClaims{}, status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return application.AccessClaims{}, status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+
+	token := values[0]
+	parts := strings.SplitN(token, " ", 2)
+	if len(parts) == 2 && strings.EqualFold(parts[0], "bearer") {
+		token = parts[1]
+	}
+
+	claims, err := sessions.Verify(ctx, token)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidToken) || errors.Is(err, application.ErrTokenRevoked) {
+			return application.AccessClaims{}, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		return application.AccessClaims{}, status.Error(codes.Internal, "failed to verify token")
 	}
+	return claims, nil
 }
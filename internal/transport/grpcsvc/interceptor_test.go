@@ -0,0 +1,181 @@
+package grpcsvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend-challenge/internal/domain"
+	jwtinfra "backend-challenge/internal/infrastructure/jwt"
+	"backend-challenge/internal/transport/authctx"
+	"backend-challenge/proto/userpb"
+	"backend-challenge/proto/userv2pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func testPolicies(t *testing.T) MethodPolicies {
+	t.Helper()
+	policies, err := DefaultPolicies()
+	if err != nil {
+		t.Fatalf("DefaultPolicies: %v", err)
+	}
+	return policies
+}
+
+func TestDefaultPoliciesFromProtoOptions(t *testing.T) {
+	policies := testPolicies(t)
+
+	expected := map[string]policyKind{
+		"/user.v1.UserService/CreateUser":     policyPublic,
+		"/user.v1.UserService/LoginMFA":       policyPublic,
+		"/user.v1.UserService/GetUser":        policyAuthenticated,
+		"/user.v1.UserService/EnrollMFA":      policyAuthenticated,
+		"/user.v1.UserService/ChangePassword": policySelf,
+	}
+	for method, kind := range expected {
+		if got := policies[method].kind; got != kind {
+			t.Fatalf("%s: expected policy %d got %d", method, kind, got)
+		}
+	}
+
+	for _, desc := range []grpc.ServiceDesc{userpb.UserService_ServiceDesc, userv2pb.UserService_ServiceDesc} {
+		for _, m := range desc.Methods {
+			if _, ok := policies["/"+desc.ServiceName+"/"+m.MethodName]; !ok {
+				t.Fatalf("%s/%s declares no auth policy", desc.ServiceName, m.MethodName)
+			}
+		}
+		for _, st := range desc.Streams {
+			if _, ok := policies["/"+desc.ServiceName+"/"+st.StreamName]; !ok {
+				t.Fatalf("%s/%s declares no auth policy", desc.ServiceName, st.StreamName)
+			}
+		}
+	}
+	if policies["/user.v2.UserService/ListUsers"].role != domain.RoleAdmin {
+		t.Fatal("expected user.v2 ListUsers to require admin")
+	}
+
+	subject := policies["/user.v1.UserService/ChangePassword"].subject(&userpb.ChangePasswordRequest{Id: "42"})
+	if subject != "42" {
+		t.Fatalf("expected subject 42 got %q", subject)
+	}
+}
+
+func TestAuthUnaryInterceptorPolicies(t *testing.T) {
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+	sessions := newSessions(manager)
+	policies := MethodPolicies{
+		"/test/Public": Public(),
+		"/test/Authed": Authenticated(),
+		"/test/Self":   SelfOnly(func(req interface{}) string { return req.(string) }),
+		"/test/Admin":  RequireRole(domain.RoleAdmin),
+	}
+	interceptor := AuthUnaryInterceptor(sessions, policies)
+
+	bearer := func(userID string, roles ...domain.Role) context.Context {
+		token, err := manager.GenerateToken(userID, roles)
+		if err != nil {
+			t.Fatalf("GenerateToken: %v", err)
+		}
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	}
+	call := func(ctx context.Context, method string, req interface{}) (string, codes.Code) {
+		resp, err := interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			id, _ := authctx.UserIDFromContext(ctx)
+			return id, nil
+		})
+		if err != nil {
+			return "", status.Code(err)
+		}
+		return resp.(string), codes.OK
+	}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		req    interface{}
+		code   codes.Code
+		caller string
+	}{
+		{name: "public without token", ctx: context.Background(), method: "/test/Public", code: codes.OK},
+		{name: "authenticated without token", ctx: context.Background(), method: "/test/Authed", code: codes.Unauthenticated},
+		{name: "authenticated", ctx: bearer("1"), method: "/test/Authed", code: codes.OK, caller: "1"},
+		{name: "self", ctx: bearer("1"), method: "/test/Self", req: "1", code: codes.OK, caller: "1"},
+		{name: "someone else", ctx: bearer("1"), method: "/test/Self", req: "2", code: codes.PermissionDenied},
+		{name: "missing role", ctx: bearer("1"), method: "/test/Admin", code: codes.PermissionDenied},
+		{name: "with role", ctx: bearer("1", domain.RoleAdmin), method: "/test/Admin", code: codes.OK, caller: "1"},
+		{name: "unregistered method", ctx: bearer("1", domain.RoleAdmin), method: "/test/Unknown", code: codes.PermissionDenied},
+	}
+	for _, tc := range tests {
+		caller, code := call(tc.ctx, tc.method, tc.req)
+		if code != tc.code || caller != tc.caller {
+			t.Fatalf("%s: expected %s/%q got %s/%q", tc.name, tc.code, tc.caller, code, caller)
+		}
+	}
+}
+
+// fakeServerStream delivers queued requests to RecvMsg.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	requests []string
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeServerStream) RecvMsg(m interface{}) error {
+	*m.(*string) = s.requests[0]
+	s.requests = s.requests[1:]
+	return nil
+}
+
+func TestAuthStreamInterceptor(t *testing.T) {
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+	policies := MethodPolicies{
+		"/test/Authed": Authenticated(),
+		"/test/Self":   SelfOnly(func(req interface{}) string { return *req.(*string) }),
+		"/test/Admin":  RequireRole(domain.RoleAdmin),
+	}
+	interceptor := AuthStreamInterceptor(newSessions(manager), policies)
+
+	token, err := manager.GenerateToken("1", nil)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	authed := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+
+	var caller string
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		caller, _ = authctx.UserIDFromContext(stream.Context())
+		for {
+			var req string
+			if err := stream.RecvMsg(&req); err != nil {
+				return err
+			}
+		}
+	}
+	run := func(ctx context.Context, method string, requests ...string) error {
+		caller = ""
+		stream := &fakeServerStream{ctx: ctx, requests: requests}
+		return interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: method, IsServerStream: true}, handler)
+	}
+
+	if code := status.Code(run(context.Background(), "/test/Authed")); code != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated without token got %s", code)
+	}
+	if code := status.Code(run(authed, "/test/Unknown")); code != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied for unregistered method got %s", code)
+	}
+	if code := status.Code(run(authed, "/test/Admin")); code != codes.PermissionDenied || caller != "" {
+		t.Fatalf("expected PermissionDenied before the handler runs got %s", code)
+	}
+	if code := status.Code(run(authed, "/test/Self", "1", "2")); code != codes.PermissionDenied || caller != "1" {
+		t.Fatalf("expected handler to see caller 1 and be refused a message for 2 got %s/%q", code, caller)
+	}
+}
@@ -0,0 +1,144 @@
+package grpcsvc
+
+import (
+	"fmt"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+	"backend-challenge/proto/userpb"
+	"backend-challenge/proto/userv2pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+type policyKind int
+
+const (
+	policyPublic policyKind = iota + 1
+	policyAuthenticated
+	policySelf
+	policyRole
+)
+
+// SubjectExtractor returns the user ID a request acts on.
+type SubjectExtractor func(req interface{}) string
+
+// MethodPolicy describes who may call a gRPC method.
+type MethodPolicy struct {
+	kind    policyKind
+	subject SubjectExtractor
+	role    domain.Role
+}
+
+// Public lets a method be called without a token.
+func Public() MethodPolicy {
+	return MethodPolicy{kind: policyPublic}
+}
+
+// Authenticated requires a valid access token.
+func Authenticated() MethodPolicy {
+	return MethodPolicy{kind: policyAuthenticated}
+}
+
+// SelfOnly requires a valid access token for the user that subject extracts
+// from the request.
+func SelfOnly(subject SubjectExtractor) MethodPolicy {
+	return MethodPolicy{kind: policySelf, subject: subject}
+}
+
+// RequireRole requires a valid access token carrying role.
+func RequireRole(role domain.Role) MethodPolicy {
+	return MethodPolicy{kind: policyRole, role: role}
+}
+
+// MethodPolicies maps full gRPC method names, such as
+// "/user.v1.UserService/GetUser", to their policy. Methods without an entry
+// are refused.
+type MethodPolicies map[string]MethodPolicy
+
+// PoliciesFromService reads the (user.v1.auth_policy) option declared on each
+// method of a service. Methods that do not declare it are left out.
+func PoliciesFromService(service protoreflect.ServiceDescriptor) (MethodPolicies, error) {
+	policies := make(MethodPolicies)
+	methods := service.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		options := method.Options()
+		if options == nil || !proto.HasExtension(options, userpb.E_AuthPolicy) {
+			continue
+		}
+
+		fullMethod := fmt.Sprintf("/%s/%s", service.FullName(), method.Name())
+		switch declared := proto.GetExtension(options, userpb.E_AuthPolicy).(userpb.AuthPolicy); declared {
+		case userpb.AuthPolicy_AUTH_POLICY_PUBLIC:
+			policies[fullMethod] = Public()
+		case userpb.AuthPolicy_AUTH_POLICY_AUTHENTICATED:
+			policies[fullMethod] = Authenticated()
+		case userpb.AuthPolicy_AUTH_POLICY_SELF:
+			if method.Input().Fields().ByName("id") == nil {
+				return nil, fmt.Errorf("%s: self-only method needs an id field on %s", fullMethod, method.Input().FullName())
+			}
+			policies[fullMethod] = SelfOnly(stringField("id"))
+		case userpb.AuthPolicy_AUTH_POLICY_ADMIN:
+			policies[fullMethod] = RequireRole(domain.RoleAdmin)
+		default:
+			return nil, fmt.Errorf("%s: unsupported auth policy %s", fullMethod, declared)
+		}
+	}
+	return policies, nil
+}
+
+// DefaultPolicies returns the policies declared for the user.v1 and user.v2
+// UserService.
+func DefaultPolicies() (MethodPolicies, error) {
+	policies := make(MethodPolicies)
+	for _, service := range []protoreflect.ServiceDescriptor{
+		userpb.File_proto_user_proto.Services().ByName("UserService"),
+		userv2pb.File_proto_v2_user_proto.Services().ByName("UserService"),
+	} {
+		declared, err := PoliciesFromService(service)
+		if err != nil {
+			return nil, err
+		}
+		for method, policy := range declared {
+			policies[method] = policy
+		}
+	}
+	return policies, nil
+}
+
+// stringField extracts a string field from a protobuf request by name.
+func stringField(name protoreflect.Name) SubjectExtractor {
+	return func(req interface{}) string {
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return ""
+		}
+		m := msg.ProtoReflect()
+		field := m.Descriptor().Fields().ByName(name)
+		if field == nil || field.Kind() != protoreflect.StringKind {
+			return ""
+		}
+		return m.Get(field).String()
+	}
+}
+
+// authorize checks verified claims against the policy for req.
+func (p MethodPolicy) authorize(claims application.AccessClaims, req interface{}) error {
+	switch p.kind {
+	case policyAuthenticated:
+		return nil
+	case policySelf:
+		if subject := p.subject(req); subject != "" && subject == claims.UserID {
+			return nil
+		}
+	case policyRole:
+		if domain.HasRole(claims.Roles, p.role) {
+			return nil
+		}
+	}
+	return status.Error(codes.PermissionDenied, "forbidden")
+}
@@ -3,110 +3,641 @@ package grpcsvc
 import (
 	"context"
 	"errors"
+	"log"
+	"net"
 	"strings"
 	"time"
 
 	"backend-challenge/internal/application"
 	"backend-challenge/internal/domain"
-	jwtinfra "backend-challenge/internal/infrastructure/jwt"
 	"backend-challenge/internal/transport/authctx"
 	"backend-challenge/proto/userpb"
+	"backend-challenge/proto/userv2pb"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 // UserServer implements the gRPC UserService.
 type UserServer struct {
 	userpb.UnimplementedUserServiceServer
-	userService *application.UserService
-	jwtManager  *jwtinfra.Manager
+	userService   *application.UserService
+	sessions      *application.SessionService
+	resets        *application.PasswordResetService
+	verifications *application.EmailVerificationService
+	mfa           *application.MFAService
 }
 
-// NewUserServer constructs a gRPC server wrapper.
-func NewUserServer(userService *application.UserService, jwtManager *jwtinfra.Manager) *UserServer {
+// NewUserServer constructs a gRPC server wrapper. verifications may be nil, in
+// which case no verification emails are sent on registration.
+func NewUserServer(userService *application.UserService, sessions *application.SessionService, resets *application.PasswordResetService, verifications *application.EmailVerificationService, mfa *application.MFAService) *UserServer {
 	return &UserServer{
-		userService: userService,
-		jwtManager:  jwtManager,
+		userService:   userService,
+		sessions:      sessions,
+		resets:        resets,
+		verifications: verifications,
+		mfa:           mfa,
 	}
 }
 
-// Register registers the server with a gRPC registrar.
+// Register registers user.v1 and user.v2 with a gRPC registrar. Both are
+// served by the same logic.
 func (s *UserServer) Register(server grpc.ServiceRegistrar) {
 	userpb.RegisterUserServiceServer(server, s)
+	userv2pb.RegisterUserServiceServer(server, &UserServerV2{core: s})
 }
 
-// CreateUser registers a new user and returns a JWT token.
+// CreateUser registers a new user and returns an access and refresh token.
+// The tokens are left empty when the verification mode refuses unverified
+// sign-ins.
 func (s *UserServer) CreateUser(ctx context.Context, req *userpb.CreateUserRequest) (*userpb.CreateUserResponse, error) {
-	user, err := s.userService.Register(ctx, application.RegisterInput{
-		Name:     strings.TrimSpace(req.GetName()),
-		Email:    strings.TrimSpace(strings.ToLower(req.GetEmail())),
-		Password: req.GetPassword(),
-	})
+	user, tokens, err := s.register(ctx, req.GetName(), req.GetEmail(), req.GetPassword())
 	if err != nil {
-		return nil, toGRPCError(err)
+		return nil, err
 	}
 
-	token, err := s.jwtManager.GenerateToken(user.ID)
+	return &userpb.CreateUserResponse{
+		User:         toProtoUser(user),
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	}, nil
+}
+
+// Login authenticates a user and returns an access and refresh token. Accounts with
+// two-factor authentication get an MFA challenge instead, to be completed
+// with LoginMFA.
+func (s *UserServer) Login(ctx context.Context, req *userpb.LoginRequest) (*userpb.LoginResponse, error) {
+	result, err := s.login(ctx, req.GetEmail(), req.GetPassword())
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to generate token: %v", err)
+		return nil, err
 	}
 
-	return &userpb.CreateUserResponse{
-		User:  toProtoUser(user),
-		Token: token,
-	}, nil
+	if result.challenge != nil {
+		return &userpb.LoginResponse{
+			MfaRequired:        true,
+			ChallengeToken:     result.challenge.Token,
+			ChallengeExpiresAt: result.challenge.ExpiresAt.Format(time.RFC3339),
+		}, nil
+	}
+	return &userpb.LoginResponse{User: toProtoUser(result.user), Token: result.tokens.AccessToken, RefreshToken: result.tokens.RefreshToken}, nil
 }
 
 // GetUser retrieves a user by ID. Requires token metadata.
 func (s *UserServer) GetUser(ctx context.Context, req *userpb.GetUserRequest) (*userpb.GetUserResponse, error) {
+	if _, err := readMask(req.GetReadMask(), &userpb.User{}); err != nil {
+		return nil, err
+	}
+	user, err := s.userService.Get(ctx, req.GetId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+
+	resp := &userpb.GetUserResponse{
+		User: toProtoUser(user),
+	}
+	applyReadMask(resp.User, req.GetReadMask())
+	return resp, nil
+}
+
+// ListUsers returns one page of users. Only admins may list.
+func (s *UserServer) ListUsers(ctx context.Context, req *userpb.ListUsersRequest) (*userpb.ListUsersResponse, error) {
+	fields, err := readMask(req.GetReadMask(), &userpb.User{})
+	if err != nil {
+		return nil, err
+	}
+	page, err := s.userService.List(ctx, application.ListInput{
+		Filter: domain.UserFilter{IncludeDeleted: req.GetIncludeDeleted()},
+		Page:   application.PageRequest{Limit: int(req.GetPageSize()), Cursor: req.GetPageToken()},
+		Fields: fields,
+	})
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+
+	resp := &userpb.ListUsersResponse{
+		Users:         make([]*userpb.User, 0, len(page.Users)),
+		NextPageToken: page.NextCursor,
+	}
+	for _, user := range page.Users {
+		u := toProtoUser(user)
+		applyReadMask(u, req.GetReadMask())
+		resp.Users = append(resp.Users, u)
+	}
+	return resp, nil
+}
+
+// UpdateUser changes the fields named in the update mask. Without a mask,
+// every non-empty field of the request's user is applied.
+func (s *UserServer) UpdateUser(ctx context.Context, req *userpb.UpdateUserRequest) (*userpb.UpdateUserResponse, error) {
+	name, email := optionalString(req.GetUser().GetName()), optionalString(req.GetUser().GetEmail())
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) > 0 {
+		// v1 cannot tell an empty field from an unset one, so a masked field is
+		// always applied.
+		name, email = stringPtr(req.GetUser().GetName()), stringPtr(req.GetUser().GetEmail())
+	}
+	input, err := updateInput(name, email, paths)
+	if err != nil {
+		return nil, err
+	}
+	input.IfMatch = req.GetEtag()
+
+	updated, err := s.update(ctx, req.GetId(), input)
+	if err != nil {
+		return nil, err
+	}
+	return &userpb.UpdateUserResponse{User: toProtoUser(updated)}, nil
+}
+
+// update applies input and, when it changed the email, sends a verification
+// link to the new address.
+func (s *UserServer) update(ctx context.Context, id string, input application.UpdateInput) (domain.User, error) {
+	updated, err := s.userService.Update(ctx, id, input)
+	if err != nil {
+		return domain.User{}, toGRPCError(err)
+	}
+	if input.Email != nil && s.verifications != nil {
+		if err := s.verifications.Send(ctx, updated); err != nil {
+			log.Printf("verification email failed: %v", err)
+		}
+	}
+	return updated, nil
+}
+
+// DeleteUser removes a user.
+func (s *UserServer) DeleteUser(ctx context.Context, req *userpb.DeleteUserRequest) (*userpb.DeleteUserResponse, error) {
+	if err := s.userService.Delete(ctx, req.GetId()); err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &userpb.DeleteUserResponse{}, nil
+}
+
+// RestoreUser brings back a deleted user. Only admins may restore.
+func (s *UserServer) RestoreUser(ctx context.Context, req *userpb.RestoreUserRequest) (*userpb.RestoreUserResponse, error) {
+	user, err := s.userService.Restore(ctx, req.GetId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &userpb.RestoreUserResponse{User: toProtoUser(user)}, nil
+}
+
+// ForgotPassword emails a reset token. It succeeds for unknown addresses.
+func (s *UserServer) ForgotPassword(ctx context.Context, req *userpb.ForgotPasswordRequest) (*userpb.ForgotPasswordResponse, error) {
+	if err := s.forgotPassword(ctx, req.GetEmail()); err != nil {
+		return nil, err
+	}
+	return &userpb.ForgotPasswordResponse{}, nil
+}
+
+// ResetPassword redeems a reset token and sets a new password.
+func (s *UserServer) ResetPassword(ctx context.Context, req *userpb.ResetPasswordRequest) (*userpb.ResetPasswordResponse, error) {
+	if err := s.resets.Reset(ctx, strings.TrimSpace(req.GetToken()), req.GetPassword()); err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &userpb.ResetPasswordResponse{}, nil
+}
+
+// VerifyEmail redeems the token from a verification email.
+func (s *UserServer) VerifyEmail(ctx context.Context, req *userpb.VerifyEmailRequest) (*userpb.VerifyEmailResponse, error) {
+	user, err := s.verifications.Verify(ctx, strings.TrimSpace(req.GetToken()))
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &userpb.VerifyEmailResponse{User: toProtoUser(user)}, nil
+}
+
+// ChangePassword sets a new password for the caller. Tokens issued before the
+// change stop working.
+func (s *UserServer) ChangePassword(ctx context.Context, req *userpb.ChangePasswordRequest) (*userpb.ChangePasswordResponse, error) {
+	if err := s.userService.ChangePassword(ctx, req.GetId(), req.GetCurrentPassword(), req.GetNewPassword()); err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &userpb.ChangePasswordResponse{}, nil
+}
+
+// EnrollMFA starts TOTP enrollment for the caller.
+func (s *UserServer) EnrollMFA(ctx context.Context, req *userpb.EnrollMFARequest) (*userpb.EnrollMFAResponse, error) {
+	authID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment, err := s.mfa.Enroll(ctx, authID)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &userpb.EnrollMFAResponse{Secret: enrollment.Secret, Uri: enrollment.URI}, nil
+}
+
+// ConfirmMFA activates the caller's pending enrollment and returns their
+// recovery codes.
+func (s *UserServer) ConfirmMFA(ctx context.Context, req *userpb.ConfirmMFARequest) (*userpb.ConfirmMFAResponse, error) {
+	authID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := s.mfa.Confirm(ctx, authID, strings.TrimSpace(req.GetCode()))
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &userpb.ConfirmMFAResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// DisableMFA turns two-factor authentication off for the caller.
+func (s *UserServer) DisableMFA(ctx context.Context, req *userpb.DisableMFARequest) (*userpb.DisableMFAResponse, error) {
+	authID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mfa.Disable(ctx, authID, req.GetCode()); err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &userpb.DisableMFAResponse{}, nil
+}
+
+// LoginMFA exchanges an mfa_required challenge and a TOTP or recovery code
+// for an access and refresh token.
+func (s *UserServer) LoginMFA(ctx context.Context, req *userpb.LoginMFARequest) (*userpb.LoginMFAResponse, error) {
+	user, tokens, err := s.completeMFA(ctx, req.GetChallengeToken(), req.GetCode())
+	if err != nil {
+		return nil, err
+	}
+	return &userpb.LoginMFAResponse{User: toProtoUser(user), Token: tokens.AccessToken, RefreshToken: tokens.RefreshToken}, nil
+}
+
+// WatchUsers streams user changes until the client goes away. Admins only.
+func (s *UserServer) WatchUsers(req *userpb.WatchUsersRequest, stream userpb.UserService_WatchUsersServer) error {
+	return s.watch(stream.Context(), req.GetResumeToken(), func(change application.UserChange) error {
+		event := change.Event
+		resp := &userpb.WatchUsersResponse{
+			Type:        protoEventType[event.Type],
+			UserId:      event.UserID,
+			OccurredAt:  event.OccurredAt.Format(time.RFC3339),
+			ResumeToken: change.ResumeToken,
+		}
+		if event.Type != domain.UserDeleted {
+			resp.User = toProtoUser(event.User)
+		}
+		return stream.Send(resp)
+	})
+}
+
+// register creates the user and sends the verification email. The tokens are
+// empty when the verification mode refuses unverified sign-ins.
+func (s *UserServer) register(ctx context.Context, name, email, password string) (domain.User, application.TokenPair, error) {
+	user, err := s.userService.Register(ctx, application.RegisterInput{
+		Name:     strings.TrimSpace(name),
+		Email:    strings.TrimSpace(strings.ToLower(email)),
+		Password: password,
+	})
+	if err != nil {
+		return domain.User{}, application.TokenPair{}, toGRPCError(err)
+	}
+
+	if s.verifications != nil {
+		if err := s.verifications.Send(ctx, user); err != nil {
+			log.Printf("verification email failed: %v", err)
+		}
+	}
+
+	if !s.userService.CanSignIn(user) {
+		return user, application.TokenPair{}, nil
+	}
+	tokens, err := s.issueTokens(ctx, user)
+	if err != nil {
+		return domain.User{}, application.TokenPair{}, err
+	}
+	return user, tokens, nil
+}
+
+// loginResult holds either a signed-in user and tokens, or the MFA challenge
+// to complete first.
+type loginResult struct {
+	user      domain.User
+	tokens    application.TokenPair
+	challenge *application.MFAChallenge
+}
+
+// login checks the password, throttled by the caller's address, and signs the
+// user in unless a second factor is needed.
+func (s *UserServer) login(ctx context.Context, email, password string) (loginResult, error) {
+	ctx = application.WithClientIP(ctx, peerIP(ctx))
+	user, err := s.userService.Authenticate(ctx, email, password)
+	if err != nil {
+		return loginResult{}, toGRPCError(err)
+	}
+
+	if user.MFA.Enabled() {
+		challenge, err := s.mfa.Challenge(ctx, user)
+		if err != nil {
+			log.Printf("grpc: create mfa challenge: %v", err)
+			return loginResult{}, status.Error(codes.Internal, "failed to create mfa challenge")
+		}
+		return loginResult{challenge: &challenge}, nil
+	}
+
+	tokens, err := s.issueTokens(ctx, user)
+	if err != nil {
+		return loginResult{}, err
+	}
+	return loginResult{user: user, tokens: tokens}, nil
+}
+
+// completeMFA redeems a login challenge and signs the user in.
+func (s *UserServer) completeMFA(ctx context.Context, challenge, code string) (domain.User, application.TokenPair, error) {
+	user, err := s.mfa.Complete(ctx, strings.TrimSpace(challenge), code)
+	if err != nil {
+		return domain.User{}, application.TokenPair{}, toGRPCError(err)
+	}
+	tokens, err := s.issueTokens(ctx, user)
+	if err != nil {
+		return domain.User{}, application.TokenPair{}, err
+	}
+	return user, tokens, nil
+}
+
+// forgotPassword emails a reset token. Like the HTTP endpoint it succeeds for
+// unknown addresses and logs delivery failures instead of returning them.
+func (s *UserServer) forgotPassword(ctx context.Context, email string) error {
+	if err := s.resets.Forgot(ctx, email); err != nil {
+		if errors.Is(err, domain.ErrInvalidEmail) {
+			return toGRPCError(err)
+		}
+		log.Printf("password reset request failed: %v", err)
+	}
+	return nil
+}
+
+// issueTokens starts a session for the user, as the HTTP login does, so gRPC
+// sign-ins get a refresh token and are revoked by logout and password changes.
+func (s *UserServer) issueTokens(ctx context.Context, user domain.User) (application.TokenPair, error) {
+	tokens, err := s.sessions.Issue(ctx, user)
+	if err != nil {
+		log.Printf("grpc: issue tokens: %v", err)
+		return application.TokenPair{}, status.Error(codes.Internal, "failed to issue tokens")
+	}
+	return tokens, nil
+}
+
+// callerID returns the authenticated user the interceptor put in ctx.
+func callerID(ctx context.Context) (string, error) {
 	authID, ok := authctx.UserIDFromContext(ctx)
 	if !ok {
-		return nil, status.Error(codes.Unauthenticated, "missing authentication context")
+		return "", status.Error(codes.Unauthenticated, "missing authentication context")
 	}
+	return authID, nil
+}
 
-	if authID != req.GetId() {
-		return nil, status.Error(codes.PermissionDenied, "forbidden")
+// updateInput builds an update from the fields that are set, normalized like
+// the HTTP handler does. paths, when given, restricts the update to the named
+// fields, which must then be set.
+func updateInput(name, email *string, paths []string) (application.UpdateInput, error) {
+	if len(paths) == 0 {
+		if name != nil {
+			paths = append(paths, "name")
+		}
+		if email != nil {
+			paths = append(paths, "email")
+		}
 	}
 
-	user, err := s.userService.Get(ctx, req.GetId())
+	var input application.UpdateInput
+	for _, path := range paths {
+		switch path {
+		case "name":
+			if name == nil {
+				return application.UpdateInput{}, status.Errorf(codes.InvalidArgument, "update_mask names unset field %q", path)
+			}
+			input.Name = stringPtr(strings.TrimSpace(*name))
+		case "email":
+			if email == nil {
+				return application.UpdateInput{}, status.Errorf(codes.InvalidArgument, "update_mask names unset field %q", path)
+			}
+			input.Email = stringPtr(strings.TrimSpace(strings.ToLower(*email)))
+		default:
+			return application.UpdateInput{}, status.Errorf(codes.InvalidArgument, "cannot update field %q", path)
+		}
+	}
+	return input, nil
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+// readMaskFields maps read_mask paths of both API versions to user fields.
+// etag has no user field of its own; the version it is built from is always
+// read.
+var readMaskFields = map[string]domain.UserField{
+	"id":             domain.FieldID,
+	"name":           domain.FieldName,
+	"email":          domain.FieldEmail,
+	"roles":          domain.FieldRoles,
+	"email_verified": domain.FieldEmailVerified,
+	"mfa_enabled":    domain.FieldMFAEnabled,
+	"created_at":     domain.FieldCreatedAt,
+	"create_time":    domain.FieldCreatedAt,
+	"deleted_at":     domain.FieldDeletedAt,
+	"delete_time":    domain.FieldDeletedAt,
+}
+
+// readMask checks that mask only names fields of the user message and
+// returns them as user fields. An empty mask selects every field.
+func readMask(mask *fieldmaskpb.FieldMask, user proto.Message) ([]domain.UserField, error) {
+	if len(mask.GetPaths()) == 0 {
+		return nil, nil
+	}
+	if !mask.IsValid(user) {
+		return nil, status.Errorf(codes.InvalidArgument, "read_mask names unknown user fields: %v", mask.GetPaths())
+	}
+	fields := make([]domain.UserField, 0, len(mask.GetPaths()))
+	for _, path := range mask.GetPaths() {
+		if field, ok := readMaskFields[path]; ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// applyReadMask clears the fields of user that a non-empty mask does not
+// name.
+func applyReadMask(user proto.Message, mask *fieldmaskpb.FieldMask) {
+	if len(mask.GetPaths()) == 0 {
+		return
+	}
+	keep := make(map[protoreflect.Name]bool, len(mask.GetPaths()))
+	for _, path := range mask.GetPaths() {
+		keep[protoreflect.Name(path)] = true
+	}
+	msg := user.ProtoReflect()
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if field := fields.Get(i); !keep[field.Name()] {
+			msg.Clear(field)
+		}
+	}
+}
+
+// optionalString treats the empty string as unset.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// peerIP returns the address the call came from, without its port.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
 	if err != nil {
-		return nil, toGRPCError(err)
+		return p.Addr.String()
 	}
+	return host
+}
 
-	return &userpb.GetUserResponse{
-		User: toProtoUser(user),
-	}, nil
+// watch runs the service's Watch and maps the error that ends it. A client
+// that cancels or whose deadline passes ends the stream with the matching code.
+func (s *UserServer) watch(ctx context.Context, resumeToken string, send func(application.UserChange) error) error {
+	err := s.userService.Watch(ctx, strings.TrimSpace(resumeToken), send)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return status.FromContextError(ctxErr).Err()
+	}
+	if _, ok := status.FromError(err); ok {
+		// Nil, or already a status from a failed Send.
+		return err
+	}
+	return toGRPCError(err)
+}
+
+var protoEventType = map[domain.UserEventType]userpb.UserEventType{
+	domain.UserCreated: userpb.UserEventType_USER_EVENT_TYPE_CREATED,
+	domain.UserUpdated: userpb.UserEventType_USER_EVENT_TYPE_UPDATED,
+	domain.UserDeleted: userpb.UserEventType_USER_EVENT_TYPE_DELETED,
 }
 
 func toProtoUser(user domain.User) *userpb.User {
-	createdAt := ""
+	createdAt, deletedAt := "", ""
 	if !user.CreatedAt.IsZero() {
 		createdAt = user.CreatedAt.Format(time.RFC3339)
 	}
+	if user.Deleted() {
+		deletedAt = user.DeletedAt.Format(time.RFC3339)
+	}
+	roles := make([]string, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		roles = append(roles, string(role))
+	}
 	return &userpb.User{
-		Id:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedAt: createdAt,
+		Id:            user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		CreatedAt:     createdAt,
+		EmailVerified: user.Verified(),
+		MfaEnabled:    user.MFA.Enabled(),
+		Roles:         roles,
+		DeletedAt:     deletedAt,
+		Etag:          application.ETag(user),
 	}
 }
 
+// internalMessage replaces the text of unexpected errors.
+const internalMessage = "an unexpected error occurred"
+
+// errorInfoDomain names this service in ErrorInfo details. Together with the
+// reason it identifies an error independently of the message text.
+const errorInfoDomain = "backend-challenge"
+
+// Stable ErrorInfo reasons.
+const (
+	reasonDuplicateEmail     = "DUPLICATE_EMAIL"
+	reasonInvalidCredentials = "INVALID_CREDENTIALS"
+	reasonWrongPassword      = "CURRENT_PASSWORD_MISMATCH"
+)
+
 func toGRPCError(err error) error {
+	var (
+		throttled *application.ThrottleError
+		invalid   domain.ValidationErrors
+	)
 	switch {
+	case errors.As(err, &invalid):
+		return statusWithDetails(codes.InvalidArgument, err, badRequest(invalid))
+	case errors.As(err, &throttled):
+		return statusWithDetails(codes.ResourceExhausted, err, &errdetails.RetryInfo{RetryDelay: durationpb.New(throttled.RetryAfter)})
 	case errors.Is(err, application.ErrDuplicateEmail):
-		return status.Error(codes.AlreadyExists, err.Error())
+		return statusWithDetails(codes.AlreadyExists, err, &errdetails.ErrorInfo{Reason: reasonDuplicateEmail, Domain: errorInfoDomain})
 	case errors.Is(err, application.ErrInvalidCredentials):
+		return statusWithDetails(codes.Unauthenticated, err, &errdetails.ErrorInfo{Reason: reasonInvalidCredentials, Domain: errorInfoDomain})
+	case errors.Is(err, application.ErrInvalidMFACode),
+		errors.Is(err, application.ErrInvalidMFAChallenge):
 		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, application.ErrMFAAlreadyEnabled),
+		errors.Is(err, application.ErrMFANotEnrolled):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, application.ErrCurrentPasswordMismatch):
+		return statusWithDetails(codes.PermissionDenied, err, &errdetails.ErrorInfo{Reason: reasonWrongPassword, Domain: errorInfoDomain})
+	case errors.Is(err, application.ErrEmailNotVerified),
+		errors.Is(err, application.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
 	case errors.Is(err, application.ErrNotFound):
 		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, application.ErrVersionMismatch):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, application.ErrEventsUnavailable):
+		return status.Error(codes.Unimplemented, err.Error())
 	case errors.Is(err, application.ErrNoFieldsToUpdate),
 		errors.Is(err, domain.ErrInvalidEmail),
 		errors.Is(err, domain.ErrInvalidName),
-		errors.Is(err, domain.ErrInvalidPassword):
+		errors.Is(err, domain.ErrInvalidPassword),
+		errors.Is(err, application.ErrInvalidResumeToken),
+		errors.Is(err, application.ErrInvalidCursor),
+		errors.Is(err, application.ErrInvalidPageSize),
+		errors.Is(err, application.ErrInvalidResetToken),
+		errors.Is(err, application.ErrInvalidVerificationToken):
 		return status.Error(codes.InvalidArgument, err.Error())
 	default:
-		return status.Error(codes.Internal, err.Error())
+		// Unexpected errors may carry database or other internal details, so
+		// they are logged and answered with a fixed message.
+		log.Printf("grpc: unexpected error: %v", err)
+		return status.Error(codes.Internal, internalMessage)
+	}
+}
+
+// badRequest lists a field violation for every invalid field. The
+// description starts with the stable code, since this version of BadRequest
+// has no field for it.
+func badRequest(invalid domain.ValidationErrors) *errdetails.BadRequest {
+	details := &errdetails.BadRequest{}
+	for _, fe := range invalid {
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fe.Field,
+			Description: fe.Code + ": " + fe.Message,
+		})
+	}
+	return details
+}
+
+// statusWithDetails builds a status carrying details, falling back to the bare
+// status if they cannot be attached.
+func statusWithDetails(code codes.Code, err error, details ...protoiface.MessageV1) error {
+	st := status.New(code, err.Error())
+	detailed, detailErr := st.WithDetails(details...)
+	if detailErr != nil {
+		return st.Err()
 	}
+	return detailed.Err()
 }
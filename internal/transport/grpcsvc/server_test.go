@@ -1,20 +1,31 @@
 package grpcsvc
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"net"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"backend-challenge/internal/application"
 	"backend-challenge/internal/domain"
 	jwtinfra "backend-challenge/internal/infrastructure/jwt"
+	"backend-challenge/internal/infrastructure/mailer"
+	"backend-challenge/internal/infrastructure/memory"
 	"backend-challenge/proto/userpb"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 const bufSize = 1024 * 1024
@@ -56,14 +67,19 @@ func (r *repoStub) Count(ctx context.Context) (int64, error) {
 	return int64(len(r.users)), nil
 }
 
+func newSessions(manager *jwtinfra.Manager) *application.SessionService {
+	return application.NewSessionService(manager, memory.NewRefreshTokenRepository(), memory.NewTokenRevocationStore(), time.Hour)
+}
+
 func TestUserServerCreateAndGet(t *testing.T) {
 	repo := newRepoStub()
 	service := application.NewUserService(repo)
 	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
 
 	listener := bufconn.Listen(bufSize)
-	server := grpc.NewServer(grpc.ChainUnaryInterceptor(AuthUnaryInterceptor(manager)))
-	userServer := NewUserServer(service, manager)
+	sessions := newSessions(manager)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(AuthUnaryInterceptor(sessions, testPolicies(t))))
+	userServer := NewUserServer(service, sessions, nil, nil, nil)
 	userServer.Register(server)
 
 	go func() {
@@ -92,7 +108,7 @@ func TestUserServerCreateAndGet(t *testing.T) {
 		t.Fatalf("expected user id")
 	}
 
-	token, err := manager.GenerateToken(createResp.User.GetId())
+	token, err := manager.GenerateToken(createResp.User.GetId(), nil)
 	if err != nil {
 		t.Fatalf("generate token: %v", err)
 	}
@@ -105,6 +121,21 @@ func TestUserServerCreateAndGet(t *testing.T) {
 	if getResp.User.GetEmail() != "test@example.com" {
 		t.Fatalf("unexpected user: %+v", getResp.User)
 	}
+
+	_, err = client.GetUser(metadata.NewOutgoingContext(ctx, md), &userpb.GetUserRequest{Id: "someone-else"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied for another user got %v", err)
+	}
+
+	adminToken, err := manager.GenerateToken("admin", []domain.Role{domain.RoleAdmin})
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	adminMD := metadata.New(map[string]string{"authorization": "Bearer " + adminToken})
+	getResp, err = client.GetUser(metadata.NewOutgoingContext(ctx, adminMD), &userpb.GetUserRequest{Id: createResp.User.GetId()})
+	if err != nil || getResp.User.GetId() != createResp.User.GetId() {
+		t.Fatalf("expected admin to read any user got %v", err)
+	}
 }
 
 func TestUserServerGetUnauthorized(t *testing.T) {
@@ -113,8 +144,9 @@ func TestUserServerGetUnauthorized(t *testing.T) {
 	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
 
 	listener := bufconn.Listen(bufSize)
-	server := grpc.NewServer(grpc.ChainUnaryInterceptor(AuthUnaryInterceptor(manager)))
-	NewUserServer(service, manager).Register(server)
+	sessions := newSessions(manager)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(AuthUnaryInterceptor(sessions, testPolicies(t))))
+	NewUserServer(service, sessions, nil, nil, nil).Register(server)
 	go server.Serve(listener)
 	t.Cleanup(server.Stop)
 
@@ -132,3 +164,675 @@ func TestUserServerGetUnauthorized(t *testing.T) {
 		t.Fatal("expected unauthorized error")
 	}
 }
+
+func TestUserServerPasswordReset(t *testing.T) {
+	repo := memory.NewUserRepository()
+	service := application.NewUserService(repo)
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+	sessions := newSessions(manager)
+	var outbox bytes.Buffer
+	logMailer, err := mailer.NewLogMailer(&outbox, "no-reply@example.com")
+	if err != nil {
+		t.Fatalf("new mailer: %v", err)
+	}
+	resets := application.NewPasswordResetService(repo, nil, memory.NewOneTimeTokenRepository(), sessions, logMailer, time.Hour, "")
+
+	listener := bufconn.Listen(bufSize)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(AuthUnaryInterceptor(sessions, testPolicies(t))))
+	NewUserServer(service, sessions, resets, nil, nil).Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	ctx := context.Background()
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := userpb.NewUserServiceClient(conn)
+
+	if _, err := client.CreateUser(ctx, &userpb.CreateUserRequest{Name: "Test", Email: "reset@example.com", Password: "pass12345"}); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	if _, err := client.ForgotPassword(ctx, &userpb.ForgotPasswordRequest{Email: "nobody@example.com"}); err != nil {
+		t.Fatalf("ForgotPassword unknown email: %v", err)
+	}
+	if _, err := client.ForgotPassword(ctx, &userpb.ForgotPasswordRequest{Email: "reset@example.com"}); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+
+	match := regexp.MustCompile(`\n([A-Za-z0-9_-]{43})\r\n`).FindStringSubmatch(outbox.String())
+	if match == nil {
+		t.Fatalf("expected reset token in mail:\n%s", outbox.String())
+	}
+
+	if _, err := client.ResetPassword(ctx, &userpb.ResetPasswordRequest{Token: match[1], Password: "newpass123"}); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	_, err = client.ResetPassword(ctx, &userpb.ResetPasswordRequest{Token: match[1], Password: "newpass123"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for reused token got %v", err)
+	}
+
+	if _, err := service.Authenticate(ctx, "reset@example.com", "newpass123"); err != nil {
+		t.Fatalf("authenticate with new password: %v", err)
+	}
+}
+
+func TestUserServerEmailVerification(t *testing.T) {
+	repo := memory.NewUserRepository()
+	service := application.NewUserService(repo, application.WithVerificationMode(application.VerificationRequired))
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+	sessions := newSessions(manager)
+	var outbox bytes.Buffer
+	logMailer, err := mailer.NewLogMailer(&outbox, "no-reply@example.com")
+	if err != nil {
+		t.Fatalf("new mailer: %v", err)
+	}
+	verifications := application.NewEmailVerificationService(repo, memory.NewOneTimeTokenRepository(), logMailer, time.Hour, "https://app.example.com/verify")
+
+	listener := bufconn.Listen(bufSize)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(AuthUnaryInterceptor(sessions, testPolicies(t))))
+	NewUserServer(service, sessions, nil, verifications, nil).Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	ctx := context.Background()
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := userpb.NewUserServiceClient(conn)
+
+	created, err := client.CreateUser(ctx, &userpb.CreateUserRequest{Name: "Test", Email: "verify@example.com", Password: "pass12345"})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if created.GetToken() != "" || created.GetUser().GetEmailVerified() {
+		t.Fatalf("expected unverified user without token got %+v", created)
+	}
+
+	match := regexp.MustCompile(`token=([A-Za-z0-9_-]+)`).FindStringSubmatch(outbox.String())
+	if match == nil {
+		t.Fatalf("expected verification link in mail:\n%s", outbox.String())
+	}
+
+	verified, err := client.VerifyEmail(ctx, &userpb.VerifyEmailRequest{Token: match[1]})
+	if err != nil {
+		t.Fatalf("VerifyEmail: %v", err)
+	}
+	if !verified.GetUser().GetEmailVerified() {
+		t.Fatalf("expected verified user got %+v", verified.GetUser())
+	}
+	_, err = client.VerifyEmail(ctx, &userpb.VerifyEmailRequest{Token: match[1]})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for reused token got %v", err)
+	}
+}
+
+func TestUserServerChangePassword(t *testing.T) {
+	var mu sync.Mutex
+	now := time.Now()
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	repo := memory.NewUserRepository()
+	service := application.NewUserService(repo, application.WithClock(clock))
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer", jwtinfra.WithClock(clock))
+	sessions := application.NewSessionService(manager, memory.NewRefreshTokenRepository(), memory.NewTokenRevocationStore(), time.Hour,
+		application.WithPasswordChangeCheck(repo), application.WithSessionClock(clock))
+
+	listener := bufconn.Listen(bufSize)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(AuthUnaryInterceptor(sessions, testPolicies(t))))
+	NewUserServer(service, sessions, nil, nil, nil).Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	ctx := context.Background()
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := userpb.NewUserServiceClient(conn)
+
+	created, err := client.CreateUser(ctx, &userpb.CreateUserRequest{Name: "Test", Email: "change@example.com", Password: "pass12345"})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	id := created.GetUser().GetId()
+	authed := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+created.GetToken())
+	mu.Lock()
+	now = now.Add(time.Second)
+	mu.Unlock()
+
+	_, err = client.ChangePassword(authed, &userpb.ChangePasswordRequest{Id: id, CurrentPassword: "wrongpass", NewPassword: "newpass123"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied for wrong password got %v", err)
+	}
+	_, err = client.ChangePassword(authed, &userpb.ChangePasswordRequest{Id: "other", CurrentPassword: "pass12345", NewPassword: "newpass123"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied got %v", err)
+	}
+	if _, err := client.ChangePassword(authed, &userpb.ChangePasswordRequest{Id: id, CurrentPassword: "pass12345", NewPassword: "newpass123"}); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+
+	_, err = client.GetUser(authed, &userpb.GetUserRequest{Id: id})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected token issued before the change to be rejected got %v", err)
+	}
+}
+
+func TestUserServerMFA(t *testing.T) {
+	repo := memory.NewUserRepository()
+	service := application.NewUserService(repo)
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+	sessions := newSessions(manager)
+	mfa := application.NewMFAService(repo, memory.NewOneTimeTokenRepository(), "Backend", time.Minute)
+
+	listener := bufconn.Listen(bufSize)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(AuthUnaryInterceptor(sessions, testPolicies(t))))
+	NewUserServer(service, sessions, nil, nil, mfa).Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	ctx := context.Background()
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := userpb.NewUserServiceClient(conn)
+
+	created, err := client.CreateUser(ctx, &userpb.CreateUserRequest{Name: "Admin", Email: "admin@example.com", Password: "pass12345"})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	authed := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+created.GetToken())
+
+	if _, err := client.EnrollMFA(ctx, &userpb.EnrollMFARequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated without token got %v", err)
+	}
+	enrollment, err := client.EnrollMFA(authed, &userpb.EnrollMFARequest{})
+	if err != nil {
+		t.Fatalf("EnrollMFA: %v", err)
+	}
+	code, err := domain.TOTPCode(enrollment.GetSecret(), domain.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatalf("totp: %v", err)
+	}
+	confirmed, err := client.ConfirmMFA(authed, &userpb.ConfirmMFARequest{Code: code})
+	if err != nil {
+		t.Fatalf("ConfirmMFA: %v", err)
+	}
+	if _, err := client.EnrollMFA(authed, &userpb.EnrollMFARequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition for second enrollment got %v", err)
+	}
+
+	user, err := service.Authenticate(ctx, "admin@example.com", "pass12345")
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	challenge, err := mfa.Challenge(ctx, user)
+	if err != nil {
+		t.Fatalf("challenge: %v", err)
+	}
+
+	resp, err := client.LoginMFA(ctx, &userpb.LoginMFARequest{ChallengeToken: challenge.Token, Code: confirmed.GetRecoveryCodes()[0]})
+	if err != nil {
+		t.Fatalf("LoginMFA: %v", err)
+	}
+	if resp.GetToken() == "" || !resp.GetUser().GetMfaEnabled() {
+		t.Fatalf("unexpected login response %+v", resp)
+	}
+	if _, err := client.LoginMFA(ctx, &userpb.LoginMFARequest{ChallengeToken: challenge.Token, Code: confirmed.GetRecoveryCodes()[1]}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated for reused challenge got %v", err)
+	}
+}
+
+func TestToGRPCErrorThrottled(t *testing.T) {
+	err := toGRPCError(&application.ThrottleError{Err: application.ErrAccountLocked, RetryAfter: 90 * time.Second})
+
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted got %v", st.Code())
+	}
+	details := st.Details()
+	if len(details) != 1 {
+		t.Fatalf("expected one detail got %v", details)
+	}
+	info, ok := details[0].(*errdetails.RetryInfo)
+	if !ok || info.GetRetryDelay().AsDuration() != 90*time.Second {
+		t.Fatalf("unexpected retry info %v", details[0])
+	}
+}
+
+func TestToGRPCErrorHidesInternalErrors(t *testing.T) {
+	st := status.Convert(toGRPCError(errors.New("connection(mongo:27017) closed: dial tcp 10.0.0.5:27017")))
+	if st.Code() != codes.Internal || st.Message() != internalMessage {
+		t.Fatalf("expected a generic internal error got %v %q", st.Code(), st.Message())
+	}
+}
+
+// failingRefreshTokens fails every write, like an unreachable database.
+type failingRefreshTokens struct {
+	application.RefreshTokenRepository
+}
+
+func (failingRefreshTokens) Create(context.Context, domain.RefreshToken) (domain.RefreshToken, error) {
+	return domain.RefreshToken{}, errors.New("connection(mongo:27017) closed")
+}
+
+func TestIssueTokensHidesInternalErrors(t *testing.T) {
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+	sessions := application.NewSessionService(manager, failingRefreshTokens{}, memory.NewTokenRevocationStore(), time.Hour)
+	userServer := NewUserServer(application.NewUserService(newRepoStub()), sessions, nil, nil, nil)
+
+	_, err := userServer.issueTokens(context.Background(), domain.User{ID: "user-1", Email: "jane@example.com"})
+	st := status.Convert(err)
+	if st.Code() != codes.Internal || st.Message() != "failed to issue tokens" {
+		t.Fatalf("expected a fixed internal error got %v %q", st.Code(), st.Message())
+	}
+}
+
+func TestToGRPCErrorDetails(t *testing.T) {
+	st := status.Convert(toGRPCError(domain.ValidateNewUser(" ", "nope", "short", domain.DefaultPasswordPolicy)))
+	if st.Code() != codes.InvalidArgument || len(st.Details()) != 1 {
+		t.Fatalf("expected InvalidArgument with one detail got %v %v", st.Code(), st.Details())
+	}
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	if !ok {
+		t.Fatalf("expected BadRequest got %T", st.Details()[0])
+	}
+	var fields []string
+	for _, violation := range badRequest.GetFieldViolations() {
+		fields = append(fields, violation.GetField()+" "+violation.GetDescription())
+	}
+	if strings.Join(fields, ",") != "name required: name must not be empty,email invalid_format: email must be valid,password too_short: password must be at least 8 characters" {
+		t.Fatalf("expected a violation per invalid field got %v", fields)
+	}
+
+	for err, want := range map[error]string{
+		application.ErrDuplicateEmail:          "DUPLICATE_EMAIL",
+		application.ErrInvalidCredentials:      "INVALID_CREDENTIALS",
+		application.ErrCurrentPasswordMismatch: "CURRENT_PASSWORD_MISMATCH",
+	} {
+		details := status.Convert(toGRPCError(err)).Details()
+		if len(details) != 1 {
+			t.Fatalf("%v: expected one detail got %v", err, details)
+		}
+		info, ok := details[0].(*errdetails.ErrorInfo)
+		if !ok || info.GetReason() != want || info.GetDomain() != errorInfoDomain {
+			t.Fatalf("%v: unexpected error info %v", err, details[0])
+		}
+	}
+}
+
+func TestUserServerLogin(t *testing.T) {
+	repo := memory.NewUserRepository()
+	throttle := application.NewLoginThrottle(memory.NewLoginAttemptStore(), application.LoginThrottlePolicy{
+		FreeAttempts:   100,
+		IPFreeAttempts: 1,
+		BaseDelay:      time.Minute,
+		MaxDelay:       time.Minute,
+		Window:         time.Hour,
+	})
+	service := application.NewUserService(repo, application.WithLoginThrottle(throttle))
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+	sessions := newSessions(manager)
+	mfa := application.NewMFAService(repo, memory.NewOneTimeTokenRepository(), "Backend", time.Minute)
+
+	listener := bufconn.Listen(bufSize)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(AuthUnaryInterceptor(sessions, testPolicies(t))))
+	NewUserServer(service, sessions, nil, nil, mfa).Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	ctx := context.Background()
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := userpb.NewUserServiceClient(conn)
+
+	created, err := client.CreateUser(ctx, &userpb.CreateUserRequest{Name: "Test", Email: "login@example.com", Password: "pass12345"})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	resp, err := client.Login(ctx, &userpb.LoginRequest{Email: " Login@Example.com ", Password: "pass12345"})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if resp.GetToken() == "" || resp.GetMfaRequired() || resp.GetUser().GetId() != created.GetUser().GetId() {
+		t.Fatalf("unexpected login response %+v", resp)
+	}
+	claims, err := manager.VerifyToken(resp.GetToken())
+	if err != nil || claims.UserID != created.GetUser().GetId() {
+		t.Fatalf("expected token for the user got %+v, %v", claims, err)
+	}
+	// Logins start sessions like the HTTP API, so the refresh token works.
+	if created.GetRefreshToken() == "" || resp.GetRefreshToken() == "" {
+		t.Fatalf("expected refresh tokens got %q and %q", created.GetRefreshToken(), resp.GetRefreshToken())
+	}
+	if _, err := sessions.Refresh(ctx, resp.GetRefreshToken()); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	authed := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+resp.GetToken())
+	enrollment, err := client.EnrollMFA(authed, &userpb.EnrollMFARequest{})
+	if err != nil {
+		t.Fatalf("EnrollMFA: %v", err)
+	}
+	code, err := domain.TOTPCode(enrollment.GetSecret(), domain.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatalf("totp: %v", err)
+	}
+	confirmed, err := client.ConfirmMFA(authed, &userpb.ConfirmMFARequest{Code: code})
+	if err != nil {
+		t.Fatalf("ConfirmMFA: %v", err)
+	}
+
+	resp, err = client.Login(ctx, &userpb.LoginRequest{Email: "login@example.com", Password: "pass12345"})
+	if err != nil {
+		t.Fatalf("Login with mfa: %v", err)
+	}
+	if !resp.GetMfaRequired() || resp.GetChallengeToken() == "" || resp.GetToken() != "" || resp.GetUser() != nil {
+		t.Fatalf("expected an mfa challenge got %+v", resp)
+	}
+	if _, err := time.Parse(time.RFC3339, resp.GetChallengeExpiresAt()); err != nil {
+		t.Fatalf("expected RFC 3339 challenge expiry got %q", resp.GetChallengeExpiresAt())
+	}
+	completed, err := client.LoginMFA(ctx, &userpb.LoginMFARequest{ChallengeToken: resp.GetChallengeToken(), Code: confirmed.GetRecoveryCodes()[0]})
+	if err != nil || completed.GetToken() == "" || completed.GetRefreshToken() == "" {
+		t.Fatalf("LoginMFA: %+v, %v", completed, err)
+	}
+
+	// The client address comes from the connection, so failures for different
+	// accounts add up against it.
+	if _, err := client.Login(ctx, &userpb.LoginRequest{Email: "login@example.com", Password: "wrongpass"}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated for wrong password got %v", err)
+	}
+	if _, err := client.Login(ctx, &userpb.LoginRequest{Email: "other@example.com", Password: "wrongpass"}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated for unknown account got %v", err)
+	}
+	if _, err := client.Login(ctx, &userpb.LoginRequest{Email: "third@example.com", Password: "wrongpass"}); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted once the client is throttled got %v", err)
+	}
+}
+
+func TestUserServerManageUsers(t *testing.T) {
+	repo := memory.NewUserRepository()
+	service := application.NewUserService(repo)
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+
+	listener := bufconn.Listen(bufSize)
+	sessions := newSessions(manager)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(AuthUnaryInterceptor(sessions, testPolicies(t))))
+	NewUserServer(service, sessions, nil, nil, nil).Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	ctx := context.Background()
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := userpb.NewUserServiceClient(conn)
+
+	alice, err := client.CreateUser(ctx, &userpb.CreateUserRequest{Name: "Alice", Email: "alice@example.com", Password: "pass12345"})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	bob, err := client.CreateUser(ctx, &userpb.CreateUserRequest{Name: "Bob", Email: "bob@example.com", Password: "pass12345"})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	aliceID := alice.GetUser().GetId()
+	asAlice := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+alice.GetToken())
+	adminToken, err := manager.GenerateToken("admin", []domain.Role{domain.RoleAdmin})
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	asAdmin := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+adminToken)
+
+	if _, err := client.ListUsers(asAlice, &userpb.ListUsersRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied listing as a user got %v", err)
+	}
+	listed, err := client.ListUsers(asAdmin, &userpb.ListUsersRequest{})
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+	if len(listed.GetUsers()) != 2 || listed.GetNextPageToken() != "" {
+		t.Fatalf("expected a single page of 2 users got %+v", listed)
+	}
+	first, err := client.ListUsers(asAdmin, &userpb.ListUsersRequest{PageSize: 1})
+	if err != nil || len(first.GetUsers()) != 1 || first.GetNextPageToken() == "" {
+		t.Fatalf("expected one user and a next page token got %+v, %v", first, err)
+	}
+	second, err := client.ListUsers(asAdmin, &userpb.ListUsersRequest{PageSize: 1, PageToken: first.GetNextPageToken()})
+	if err != nil || len(second.GetUsers()) != 1 || second.GetNextPageToken() != "" {
+		t.Fatalf("expected the last page to hold one user got %+v, %v", second, err)
+	}
+	if second.GetUsers()[0].GetId() == first.GetUsers()[0].GetId() {
+		t.Fatal("expected the second page to continue after the first")
+	}
+	if _, err := client.ListUsers(asAdmin, &userpb.ListUsersRequest{PageToken: "bogus"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for a bad page token got %v", err)
+	}
+	masked, err := client.ListUsers(asAdmin, &userpb.ListUsersRequest{ReadMask: &fieldmaskpb.FieldMask{Paths: []string{"id", "name"}}})
+	if err != nil {
+		t.Fatalf("ListUsers with read mask: %v", err)
+	}
+	for _, user := range masked.GetUsers() {
+		if user.GetId() == "" || user.GetName() == "" || user.GetEmail() != "" || user.GetCreatedAt() != "" || len(user.GetRoles()) != 0 {
+			t.Fatalf("expected only id and name got %+v", user)
+		}
+	}
+	if _, err := client.ListUsers(asAdmin, &userpb.ListUsersRequest{ReadMask: &fieldmaskpb.FieldMask{Paths: []string{"password"}}}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for an unknown read mask field got %v", err)
+	}
+
+	updated, err := client.UpdateUser(asAlice, &userpb.UpdateUserRequest{
+		Id:         aliceID,
+		User:       &userpb.User{Name: " Alicia ", Email: "ignored@example.com"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if updated.GetUser().GetName() != "Alicia" || updated.GetUser().GetEmail() != "alice@example.com" {
+		t.Fatalf("expected only the masked field to change got %+v", updated.GetUser())
+	}
+
+	updated, err = client.UpdateUser(asAlice, &userpb.UpdateUserRequest{Id: aliceID, User: &userpb.User{Email: "ALICIA@example.com"}})
+	if err != nil {
+		t.Fatalf("UpdateUser without mask: %v", err)
+	}
+	if updated.GetUser().GetName() != "Alicia" || updated.GetUser().GetEmail() != "alicia@example.com" {
+		t.Fatalf("expected the non-empty field to change got %+v", updated.GetUser())
+	}
+
+	read, err := client.GetUser(asAlice, &userpb.GetUserRequest{Id: aliceID})
+	if err != nil || read.GetUser().GetEtag() != updated.GetUser().GetEtag() || read.GetUser().GetEtag() == "" {
+		t.Fatalf("expected GetUser to return the current etag got %+v, %v", read.GetUser(), err)
+	}
+	updated, err = client.UpdateUser(asAlice, &userpb.UpdateUserRequest{Id: aliceID, User: &userpb.User{Name: "Alice"}, Etag: read.GetUser().GetEtag()})
+	if err != nil || updated.GetUser().GetEtag() == read.GetUser().GetEtag() {
+		t.Fatalf("expected a matching etag to update and change got %+v, %v", updated.GetUser(), err)
+	}
+	if _, err := client.UpdateUser(asAlice, &userpb.UpdateUserRequest{Id: aliceID, User: &userpb.User{Name: "Ally"}, Etag: read.GetUser().GetEtag()}); status.Code(err) != codes.Aborted {
+		t.Fatalf("expected Aborted for a stale etag got %v", err)
+	}
+
+	tests := []struct {
+		name string
+		req  *userpb.UpdateUserRequest
+		code codes.Code
+	}{
+		{name: "unknown path", req: &userpb.UpdateUserRequest{Id: aliceID, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"roles"}}}, code: codes.InvalidArgument},
+		{name: "nothing to update", req: &userpb.UpdateUserRequest{Id: aliceID}, code: codes.InvalidArgument},
+		{name: "invalid email", req: &userpb.UpdateUserRequest{Id: aliceID, User: &userpb.User{Email: "nope"}, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"email"}}}, code: codes.InvalidArgument},
+		{name: "duplicate email", req: &userpb.UpdateUserRequest{Id: aliceID, User: &userpb.User{Email: "bob@example.com"}}, code: codes.AlreadyExists},
+		{name: "someone else", req: &userpb.UpdateUserRequest{Id: bob.GetUser().GetId(), User: &userpb.User{Name: "Robert"}}, code: codes.PermissionDenied},
+	}
+	for _, tc := range tests {
+		if _, err := client.UpdateUser(asAlice, tc.req); status.Code(err) != tc.code {
+			t.Fatalf("%s: expected %s got %v", tc.name, tc.code, err)
+		}
+	}
+
+	if _, err := client.DeleteUser(asAlice, &userpb.DeleteUserRequest{Id: bob.GetUser().GetId()}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied deleting someone else got %v", err)
+	}
+	if _, err := client.DeleteUser(asAdmin, &userpb.DeleteUserRequest{Id: bob.GetUser().GetId()}); err != nil {
+		t.Fatalf("DeleteUser as admin: %v", err)
+	}
+	if _, err := client.DeleteUser(asAlice, &userpb.DeleteUserRequest{Id: aliceID}); err != nil {
+		t.Fatalf("DeleteUser own account: %v", err)
+	}
+	if _, err := client.DeleteUser(asAdmin, &userpb.DeleteUserRequest{Id: aliceID}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound for a deleted user got %v", err)
+	}
+
+	listed, err = client.ListUsers(asAdmin, &userpb.ListUsersRequest{})
+	if err != nil || len(listed.GetUsers()) != 0 {
+		t.Fatalf("expected deleted users to be hidden got %+v, %v", listed, err)
+	}
+	listed, err = client.ListUsers(asAdmin, &userpb.ListUsersRequest{IncludeDeleted: true})
+	if err != nil || len(listed.GetUsers()) != 2 || listed.GetUsers()[0].GetDeletedAt() == "" {
+		t.Fatalf("expected deleted users on request got %+v, %v", listed, err)
+	}
+	if _, err := client.RestoreUser(asAlice, &userpb.RestoreUserRequest{Id: aliceID}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied restoring as a user got %v", err)
+	}
+	restored, err := client.RestoreUser(asAdmin, &userpb.RestoreUserRequest{Id: aliceID})
+	if err != nil {
+		t.Fatalf("RestoreUser: %v", err)
+	}
+	if restored.GetUser().GetId() != aliceID || restored.GetUser().GetDeletedAt() != "" {
+		t.Fatalf("expected the restored user got %+v", restored.GetUser())
+	}
+	if _, err := client.RestoreUser(asAdmin, &userpb.RestoreUserRequest{Id: aliceID}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound restoring an active user got %v", err)
+	}
+}
+
+func TestUserServerWatchUsers(t *testing.T) {
+	broker := memory.NewUserEventBroker(100)
+	service := application.NewUserService(memory.NewUserRepository(), application.WithEventPublisher(broker), application.WithEventSource(broker))
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+	sessions := newSessions(manager)
+
+	listener := bufconn.Listen(bufSize)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(AuthUnaryInterceptor(sessions, testPolicies(t))),
+		grpc.ChainStreamInterceptor(AuthStreamInterceptor(sessions, testPolicies(t))),
+	)
+	NewUserServer(service, sessions, nil, nil, nil).Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := userpb.NewUserServiceClient(conn)
+
+	adminToken, err := manager.GenerateToken("admin", []domain.Role{domain.RoleAdmin})
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	asAdmin := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+adminToken)
+
+	// Each stream below stops after one change and the next resumes from its
+	// token, so together they see every change in order.
+	next := func(token string, during func()) *userpb.WatchUsersResponse {
+		t.Helper()
+		streamCtx, stop := context.WithCancel(asAdmin)
+		defer stop()
+		stream, err := client.WatchUsers(streamCtx, &userpb.WatchUsersRequest{ResumeToken: token})
+		if err != nil {
+			t.Fatalf("WatchUsers: %v", err)
+		}
+		if during != nil {
+			// Give the stream a moment to subscribe before changing anything.
+			time.Sleep(20 * time.Millisecond)
+			during()
+		}
+		change, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("WatchUsers recv: %v", err)
+		}
+		return change
+	}
+
+	var created *userpb.CreateUserResponse
+	live := next("", func() {
+		created, err = client.CreateUser(ctx, &userpb.CreateUserRequest{Name: "Alice", Email: "alice@example.com", Password: "pass12345"})
+		if err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+	})
+	id := created.GetUser().GetId()
+	if live.GetType() != userpb.UserEventType_USER_EVENT_TYPE_CREATED || live.GetUserId() != id || live.GetUser().GetEmail() != "alice@example.com" {
+		t.Fatalf("unexpected live change %+v", live)
+	}
+
+	asAlice := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+created.GetToken())
+	denied, err := client.WatchUsers(asAlice, &userpb.WatchUsersRequest{})
+	if err == nil {
+		_, err = denied.Recv()
+	}
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied watching as a user got %v", err)
+	}
+
+	if _, err := client.UpdateUser(asAlice, &userpb.UpdateUserRequest{Id: id, User: &userpb.User{Name: "Alicia"}}); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if _, err := client.DeleteUser(asAlice, &userpb.DeleteUserRequest{Id: id}); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+
+	first := next(live.GetResumeToken(), nil)
+	if first.GetType() != userpb.UserEventType_USER_EVENT_TYPE_UPDATED || first.GetUser().GetName() != "Alicia" || first.GetOccurredAt() == "" {
+		t.Fatalf("unexpected first change %+v", first)
+	}
+	second := next(first.GetResumeToken(), nil)
+	if second.GetType() != userpb.UserEventType_USER_EVENT_TYPE_DELETED || second.GetUserId() != id || second.GetUser() != nil {
+		t.Fatalf("unexpected second change %+v", second)
+	}
+
+	stream, err := client.WatchUsers(asAdmin, &userpb.WatchUsersRequest{ResumeToken: "bogus"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for a bad resume token got %v", err)
+	}
+}
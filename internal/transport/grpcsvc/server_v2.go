@@ -0,0 +1,265 @@
+package grpcsvc
+
+import (
+	"context"
+	"strings"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+	"backend-challenge/proto/userv2pb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// UserServerV2 implements the gRPC user.v2 UserService on top of the same
+// logic as UserServer. Only the messages differ: v2 uses well-known types for
+// timestamps and optional fields.
+type UserServerV2 struct {
+	userv2pb.UnimplementedUserServiceServer
+	core *UserServer
+}
+
+// CreateUser registers a new user and returns an access and refresh token.
+// The tokens are left empty when the verification mode refuses unverified
+// sign-ins.
+func (s *UserServerV2) CreateUser(ctx context.Context, req *userv2pb.CreateUserRequest) (*userv2pb.CreateUserResponse, error) {
+	user, tokens, err := s.core.register(ctx, req.GetName(), req.GetEmail(), req.GetPassword())
+	if err != nil {
+		return nil, err
+	}
+	return &userv2pb.CreateUserResponse{User: toProtoUserV2(user), Token: tokens.AccessToken, RefreshToken: tokens.RefreshToken}, nil
+}
+
+// Login authenticates a user and returns an access and refresh token, or an MFA challenge for
+// accounts with two-factor authentication.
+func (s *UserServerV2) Login(ctx context.Context, req *userv2pb.LoginRequest) (*userv2pb.LoginResponse, error) {
+	result, err := s.core.login(ctx, req.GetEmail(), req.GetPassword())
+	if err != nil {
+		return nil, err
+	}
+
+	if result.challenge != nil {
+		return &userv2pb.LoginResponse{
+			MfaRequired:         true,
+			ChallengeToken:      result.challenge.Token,
+			ChallengeExpireTime: timestamppb.New(result.challenge.ExpiresAt),
+		}, nil
+	}
+	return &userv2pb.LoginResponse{User: toProtoUserV2(result.user), Token: result.tokens.AccessToken, RefreshToken: result.tokens.RefreshToken}, nil
+}
+
+// GetUser retrieves a user by ID.
+func (s *UserServerV2) GetUser(ctx context.Context, req *userv2pb.GetUserRequest) (*userv2pb.GetUserResponse, error) {
+	if _, err := readMask(req.GetReadMask(), &userv2pb.User{}); err != nil {
+		return nil, err
+	}
+	user, err := s.core.userService.Get(ctx, req.GetId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	resp := &userv2pb.GetUserResponse{User: toProtoUserV2(user)}
+	applyReadMask(resp.User, req.GetReadMask())
+	return resp, nil
+}
+
+// ListUsers returns one page of users. Only admins may list.
+func (s *UserServerV2) ListUsers(ctx context.Context, req *userv2pb.ListUsersRequest) (*userv2pb.ListUsersResponse, error) {
+	fields, err := readMask(req.GetReadMask(), &userv2pb.User{})
+	if err != nil {
+		return nil, err
+	}
+	page, err := s.core.userService.List(ctx, application.ListInput{
+		Filter: domain.UserFilter{IncludeDeleted: req.GetIncludeDeleted()},
+		Page:   application.PageRequest{Limit: int(req.GetPageSize()), Cursor: req.GetPageToken()},
+		Fields: fields,
+	})
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+
+	resp := &userv2pb.ListUsersResponse{
+		Users:         make([]*userv2pb.User, 0, len(page.Users)),
+		NextPageToken: page.NextCursor,
+	}
+	for _, user := range page.Users {
+		u := toProtoUserV2(user)
+		applyReadMask(u, req.GetReadMask())
+		resp.Users = append(resp.Users, u)
+	}
+	return resp, nil
+}
+
+// UpdateUser changes the fields that are set, restricted to the update mask
+// when one is given.
+func (s *UserServerV2) UpdateUser(ctx context.Context, req *userv2pb.UpdateUserRequest) (*userv2pb.UpdateUserResponse, error) {
+	input, err := updateInput(wrappedString(req.GetName()), wrappedString(req.GetEmail()), req.GetUpdateMask().GetPaths())
+	if err != nil {
+		return nil, err
+	}
+	input.IfMatch = req.GetEtag()
+
+	updated, err := s.core.update(ctx, req.GetId(), input)
+	if err != nil {
+		return nil, err
+	}
+	return &userv2pb.UpdateUserResponse{User: toProtoUserV2(updated)}, nil
+}
+
+// DeleteUser removes a user.
+func (s *UserServerV2) DeleteUser(ctx context.Context, req *userv2pb.DeleteUserRequest) (*userv2pb.DeleteUserResponse, error) {
+	if err := s.core.userService.Delete(ctx, req.GetId()); err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &userv2pb.DeleteUserResponse{}, nil
+}
+
+// RestoreUser brings back a deleted user. Only admins may restore.
+func (s *UserServerV2) RestoreUser(ctx context.Context, req *userv2pb.RestoreUserRequest) (*userv2pb.RestoreUserResponse, error) {
+	user, err := s.core.userService.Restore(ctx, req.GetId())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &userv2pb.RestoreUserResponse{User: toProtoUserV2(user)}, nil
+}
+
+// ForgotPassword emails a reset token.
+func (s *UserServerV2) ForgotPassword(ctx context.Context, req *userv2pb.ForgotPasswordRequest) (*userv2pb.ForgotPasswordResponse, error) {
+	if err := s.core.forgotPassword(ctx, req.GetEmail()); err != nil {
+		return nil, err
+	}
+	return &userv2pb.ForgotPasswordResponse{}, nil
+}
+
+// ResetPassword redeems a reset token and sets a new password.
+func (s *UserServerV2) ResetPassword(ctx context.Context, req *userv2pb.ResetPasswordRequest) (*userv2pb.ResetPasswordResponse, error) {
+	if err := s.core.resets.Reset(ctx, strings.TrimSpace(req.GetToken()), req.GetPassword()); err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &userv2pb.ResetPasswordResponse{}, nil
+}
+
+// VerifyEmail redeems the token from a verification email.
+func (s *UserServerV2) VerifyEmail(ctx context.Context, req *userv2pb.VerifyEmailRequest) (*userv2pb.VerifyEmailResponse, error) {
+	user, err := s.core.verifications.Verify(ctx, strings.TrimSpace(req.GetToken()))
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &userv2pb.VerifyEmailResponse{User: toProtoUserV2(user)}, nil
+}
+
+// ChangePassword sets a new password for the caller.
+func (s *UserServerV2) ChangePassword(ctx context.Context, req *userv2pb.ChangePasswordRequest) (*userv2pb.ChangePasswordResponse, error) {
+	if err := s.core.userService.ChangePassword(ctx, req.GetId(), req.GetCurrentPassword(), req.GetNewPassword()); err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &userv2pb.ChangePasswordResponse{}, nil
+}
+
+// EnrollMFA starts TOTP enrollment for the caller.
+func (s *UserServerV2) EnrollMFA(ctx context.Context, req *userv2pb.EnrollMFARequest) (*userv2pb.EnrollMFAResponse, error) {
+	authID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment, err := s.core.mfa.Enroll(ctx, authID)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &userv2pb.EnrollMFAResponse{Secret: enrollment.Secret, Uri: enrollment.URI}, nil
+}
+
+// ConfirmMFA activates the caller's pending enrollment and returns their
+// recovery codes.
+func (s *UserServerV2) ConfirmMFA(ctx context.Context, req *userv2pb.ConfirmMFARequest) (*userv2pb.ConfirmMFAResponse, error) {
+	authID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := s.core.mfa.Confirm(ctx, authID, strings.TrimSpace(req.GetCode()))
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &userv2pb.ConfirmMFAResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// DisableMFA turns two-factor authentication off for the caller.
+func (s *UserServerV2) DisableMFA(ctx context.Context, req *userv2pb.DisableMFARequest) (*userv2pb.DisableMFAResponse, error) {
+	authID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.core.mfa.Disable(ctx, authID, req.GetCode()); err != nil {
+		return nil, toGRPCError(err)
+	}
+	return &userv2pb.DisableMFAResponse{}, nil
+}
+
+// LoginMFA exchanges an mfa_required challenge and a TOTP or recovery code
+// for an access and refresh token.
+func (s *UserServerV2) LoginMFA(ctx context.Context, req *userv2pb.LoginMFARequest) (*userv2pb.LoginMFAResponse, error) {
+	user, tokens, err := s.core.completeMFA(ctx, req.GetChallengeToken(), req.GetCode())
+	if err != nil {
+		return nil, err
+	}
+	return &userv2pb.LoginMFAResponse{User: toProtoUserV2(user), Token: tokens.AccessToken, RefreshToken: tokens.RefreshToken}, nil
+}
+
+// WatchUsers streams user changes until the client goes away. Admins only.
+func (s *UserServerV2) WatchUsers(req *userv2pb.WatchUsersRequest, stream userv2pb.UserService_WatchUsersServer) error {
+	return s.core.watch(stream.Context(), req.GetResumeToken(), func(change application.UserChange) error {
+		event := change.Event
+		resp := &userv2pb.WatchUsersResponse{
+			Type:        protoEventTypeV2[event.Type],
+			UserId:      event.UserID,
+			OccurTime:   timestamppb.New(event.OccurredAt),
+			ResumeToken: change.ResumeToken,
+		}
+		if event.Type != domain.UserDeleted {
+			resp.User = toProtoUserV2(event.User)
+		}
+		return stream.Send(resp)
+	})
+}
+
+var protoEventTypeV2 = map[domain.UserEventType]userv2pb.UserEventType{
+	domain.UserCreated: userv2pb.UserEventType_USER_EVENT_TYPE_CREATED,
+	domain.UserUpdated: userv2pb.UserEventType_USER_EVENT_TYPE_UPDATED,
+	domain.UserDeleted: userv2pb.UserEventType_USER_EVENT_TYPE_DELETED,
+}
+
+// wrappedString returns nil for an unset wrapper.
+func wrappedString(value *wrapperspb.StringValue) *string {
+	if value == nil {
+		return nil
+	}
+	return stringPtr(value.GetValue())
+}
+
+func toProtoUserV2(user domain.User) *userv2pb.User {
+	var createTime, deleteTime *timestamppb.Timestamp
+	if !user.CreatedAt.IsZero() {
+		createTime = timestamppb.New(user.CreatedAt)
+	}
+	if user.Deleted() {
+		deleteTime = timestamppb.New(*user.DeletedAt)
+	}
+	roles := make([]string, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		roles = append(roles, string(role))
+	}
+	return &userv2pb.User{
+		Id:            user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		CreateTime:    createTime,
+		EmailVerified: user.Verified(),
+		MfaEnabled:    user.MFA.Enabled(),
+		Roles:         roles,
+		DeleteTime:    deleteTime,
+		Etag:          application.ETag(user),
+	}
+}
@@ -0,0 +1,207 @@
+package grpcsvc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+	jwtinfra "backend-challenge/internal/infrastructure/jwt"
+	"backend-challenge/internal/infrastructure/memory"
+	"backend-challenge/proto/userpb"
+	"backend-challenge/proto/userv2pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestUserServerV2ServedAlongsideV1(t *testing.T) {
+	repo := memory.NewUserRepository()
+	service := application.NewUserService(repo)
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+
+	listener := bufconn.Listen(bufSize)
+	sessions := newSessions(manager)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(AuthUnaryInterceptor(sessions, testPolicies(t))))
+	NewUserServer(service, sessions, nil, nil, nil).Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	ctx := context.Background()
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	v1 := userpb.NewUserServiceClient(conn)
+	v2 := userv2pb.NewUserServiceClient(conn)
+
+	created, err := v1.CreateUser(ctx, &userpb.CreateUserRequest{Name: "Test", Email: "v2@example.com", Password: "pass12345"})
+	if err != nil {
+		t.Fatalf("v1 CreateUser: %v", err)
+	}
+	id := created.GetUser().GetId()
+
+	login, err := v2.Login(ctx, &userv2pb.LoginRequest{Email: "v2@example.com", Password: "pass12345"})
+	if err != nil {
+		t.Fatalf("v2 Login: %v", err)
+	}
+	authed := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+login.GetToken())
+
+	got, err := v2.GetUser(authed, &userv2pb.GetUserRequest{Id: id})
+	if err != nil {
+		t.Fatalf("v2 GetUser: %v", err)
+	}
+	stored, err := repo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if !got.GetUser().GetCreateTime().AsTime().Equal(stored.CreatedAt) {
+		t.Fatalf("expected create_time %v got %v", stored.CreatedAt, got.GetUser().GetCreateTime().AsTime())
+	}
+
+	updated, err := v2.UpdateUser(authed, &userv2pb.UpdateUserRequest{Id: id, Name: wrapperspb.String(" Renamed ")})
+	if err != nil {
+		t.Fatalf("v2 UpdateUser: %v", err)
+	}
+	if updated.GetUser().GetName() != "Renamed" || updated.GetUser().GetEmail() != "v2@example.com" {
+		t.Fatalf("expected only the set field to change got %+v", updated.GetUser())
+	}
+
+	_, err = v2.UpdateUser(authed, &userv2pb.UpdateUserRequest{Id: id, Name: wrapperspb.String(""), UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for an empty name got %v", err)
+	}
+	_, err = v2.UpdateUser(authed, &userv2pb.UpdateUserRequest{Id: id, Name: wrapperspb.String("Other"), UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"email"}}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for a masked field that is unset got %v", err)
+	}
+
+	fromV1, err := v1.GetUser(authed, &userpb.GetUserRequest{Id: id})
+	if err != nil || fromV1.GetUser().GetName() != "Renamed" {
+		t.Fatalf("expected v1 to see the v2 update got %+v, %v", fromV1, err)
+	}
+	if fromV1.GetUser().GetEtag() != updated.GetUser().GetEtag() {
+		t.Fatalf("expected both versions to share etags got %q and %q", fromV1.GetUser().GetEtag(), updated.GetUser().GetEtag())
+	}
+	stale := &userv2pb.UpdateUserRequest{Id: id, Name: wrapperspb.String("Other"), Etag: `"1"`}
+	if _, err := v2.UpdateUser(authed, stale); status.Code(err) != codes.Aborted {
+		t.Fatalf("expected Aborted for a stale etag got %v", err)
+	}
+
+	masked, err := v2.GetUser(authed, &userv2pb.GetUserRequest{Id: id, ReadMask: &fieldmaskpb.FieldMask{Paths: []string{"id", "create_time"}}})
+	if err != nil {
+		t.Fatalf("v2 GetUser with read mask: %v", err)
+	}
+	if masked.GetUser().GetId() != id || masked.GetUser().GetCreateTime() == nil || masked.GetUser().GetName() != "" || masked.GetUser().GetEmail() != "" {
+		t.Fatalf("expected only id and create_time got %+v", masked.GetUser())
+	}
+	_, err = v2.GetUser(authed, &userv2pb.GetUserRequest{Id: id, ReadMask: &fieldmaskpb.FieldMask{Paths: []string{"created_at"}}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for a v1 field name in a v2 read mask got %v", err)
+	}
+
+	if _, err := v2.ListUsers(authed, &userv2pb.ListUsersRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied listing as a user got %v", err)
+	}
+	if _, err := v2.DeleteUser(ctx, &userv2pb.DeleteUserRequest{Id: id}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated without token got %v", err)
+	}
+	if _, err := v2.DeleteUser(authed, &userv2pb.DeleteUserRequest{Id: id}); err != nil {
+		t.Fatalf("v2 DeleteUser: %v", err)
+	}
+}
+
+func TestUserServerLimitedVerification(t *testing.T) {
+	repo := memory.NewUserRepository()
+	service := application.NewUserService(repo, application.WithVerificationMode(application.VerificationLimited))
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+
+	listener := bufconn.Listen(bufSize)
+	sessions := newSessions(manager)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(AuthUnaryInterceptor(sessions, testPolicies(t))))
+	NewUserServer(service, sessions, nil, nil, nil).Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	ctx := context.Background()
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	v1 := userpb.NewUserServiceClient(conn)
+	v2 := userv2pb.NewUserServiceClient(conn)
+
+	created, err := v1.CreateUser(ctx, &userpb.CreateUserRequest{Name: "Test", Email: "limited@example.com", Password: "pass12345"})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if created.GetToken() == "" {
+		t.Fatal("expected a token for an unverified user in limited mode")
+	}
+	id := created.GetUser().GetId()
+	authed := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+created.GetToken())
+	adminToken, err := manager.GenerateToken(id, []domain.Role{domain.RoleAdmin})
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	asAdmin := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+adminToken)
+
+	if _, err := v1.GetUser(authed, &userpb.GetUserRequest{Id: id}); err != nil {
+		t.Fatalf("expected an unverified user to read their own account got %v", err)
+	}
+	calls := map[string]func() error{
+		"v1 UpdateUser": func() error {
+			_, err := v1.UpdateUser(authed, &userpb.UpdateUserRequest{Id: id, User: &userpb.User{Name: "Renamed"}})
+			return err
+		},
+		"v1 DeleteUser": func() error {
+			_, err := v1.DeleteUser(authed, &userpb.DeleteUserRequest{Id: id})
+			return err
+		},
+		"v1 ListUsers": func() error {
+			_, err := v1.ListUsers(asAdmin, &userpb.ListUsersRequest{})
+			return err
+		},
+		"v2 UpdateUser": func() error {
+			_, err := v2.UpdateUser(authed, &userv2pb.UpdateUserRequest{Id: id, Name: wrapperspb.String("Renamed")})
+			return err
+		},
+		"v2 DeleteUser": func() error {
+			_, err := v2.DeleteUser(authed, &userv2pb.DeleteUserRequest{Id: id})
+			return err
+		},
+		"v2 ListUsers": func() error {
+			_, err := v2.ListUsers(asAdmin, &userv2pb.ListUsersRequest{})
+			return err
+		},
+	}
+	for name, call := range calls {
+		if err := call(); status.Code(err) != codes.PermissionDenied {
+			t.Fatalf("%s: expected PermissionDenied for an unverified user got %v", name, err)
+		}
+	}
+
+	if _, err := repo.MarkVerified(ctx, id, time.Now()); err != nil {
+		t.Fatalf("MarkVerified: %v", err)
+	}
+	if _, err := v2.ListUsers(asAdmin, &userv2pb.ListUsersRequest{}); err != nil {
+		t.Fatalf("expected ListUsers to succeed once verified got %v", err)
+	}
+	if _, err := v1.DeleteUser(authed, &userpb.DeleteUserRequest{Id: id}); err != nil {
+		t.Fatalf("expected DeleteUser to succeed once verified got %v", err)
+	}
+}
@@ -3,12 +3,15 @@ package http
 import (
 	"encoding/json"
 	"errors"
+	"io"
+	"log"
+	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	"backend-challenge/internal/application"
 	"backend-challenge/internal/domain"
-	jwtinfra "backend-challenge/internal/infrastructure/jwt"
 	"backend-challenge/internal/transport/authctx"
 
 	"github.com/go-chi/chi/v5"
@@ -16,15 +19,22 @@ import (
 
 // Handler bundles HTTP handlers for user operations.
 type Handler struct {
-	service    *application.UserService
-	jwtManager *jwtinfra.Manager
+	service       *application.UserService
+	sessions      *application.SessionService
+	resets        *application.PasswordResetService
+	verifications *application.EmailVerificationService
+	mfa           *application.MFAService
 }
 
-// NewHandler builds a handler.
-func NewHandler(service *application.UserService, manager *jwtinfra.Manager) *Handler {
+// NewHandler builds a handler. verifications may be nil, in which case no
+// verification emails are sent on registration.
+func NewHandler(service *application.UserService, sessions *application.SessionService, resets *application.PasswordResetService, verifications *application.EmailVerificationService, mfa *application.MFAService) *Handler {
 	return &Handler{
-		service:    service,
-		jwtManager: manager,
+		service:       service,
+		sessions:      sessions,
+		resets:        resets,
+		verifications: verifications,
+		mfa:           mfa,
 	}
 }
 
@@ -39,21 +49,80 @@ type loginRequest struct {
 	Password string `json:"password"`
 }
 
+type mfaLoginRequest struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+}
+
+type mfaCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type logoutRequest struct {
+	RefreshToken string `json:"refreshToken,omitempty"`
+}
+
+type forgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
 type updateRequest struct {
 	Name  *string `json:"name,omitempty"`
 	Email *string `json:"email,omitempty"`
 }
 
 type authResponse struct {
-	Token string            `json:"token"`
-	User  domain.UserPublic `json:"user"`
+	Token        string            `json:"token,omitempty"`
+	RefreshToken string            `json:"refreshToken,omitempty"`
+	User         domain.UserPublic `json:"user"`
+}
+
+type mfaChallengeResponse struct {
+	Status         string    `json:"status"`
+	ChallengeToken string    `json:"challengeToken"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+}
+
+type mfaEnrollResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type tokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
 }
 
-// Register handles account creation.
+type listUsersResponse struct {
+	Users      []any  `json:"users"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Register handles account creation. A verification email is sent to the new
+// address; when the verification mode refuses unverified sign-ins the response
+// carries the user but no tokens.
 func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 	var payload registerRequest
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, "invalid payload", http.StatusBadRequest)
+		writeProblem(w, r, problemInvalidPayload, "request body must be valid JSON")
 		return
 	}
 
@@ -66,87 +135,330 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		Password: payload.Password,
 	})
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	token, err := h.jwtManager.GenerateToken(user.ID)
+	if h.verifications != nil {
+		if err := h.verifications.Send(r.Context(), user); err != nil {
+			log.Printf("verification email failed: %v", err)
+		}
+	}
+
+	if !h.service.CanSignIn(user) {
+		writeJSON(w, http.StatusCreated, authResponse{User: user.Sanitize()})
+		return
+	}
+
+	tokens, err := h.sessions.Issue(r.Context(), user)
 	if err != nil {
-		http.Error(w, "failed to create token", http.StatusInternalServerError)
+		writeInternalError(w, r, err)
 		return
 	}
 
 	writeJSON(w, http.StatusCreated, authResponse{
-		Token: token,
-		User:  user.Sanitize(),
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		User:         user.Sanitize(),
 	})
 }
 
-// Login authenticates a user and returns JWT.
+// Login authenticates a user and returns JWT. Accounts with two-factor
+// authentication get an mfa_required challenge instead, to be completed at
+// /auth/login/mfa.
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	var payload loginRequest
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, "invalid payload", http.StatusBadRequest)
+		writeProblem(w, r, problemInvalidPayload, "request body must be valid JSON")
 		return
 	}
 
 	payload.Email = strings.TrimSpace(strings.ToLower(payload.Email))
 
-	user, err := h.service.Authenticate(r.Context(), payload.Email, payload.Password)
+	ctx := application.WithClientIP(r.Context(), clientIP(r))
+	user, err := h.service.Authenticate(ctx, payload.Email, payload.Password)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if user.MFA.Enabled() {
+		challenge, err := h.mfa.Challenge(r.Context(), user)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, mfaChallengeResponse{
+			Status:         "mfa_required",
+			ChallengeToken: challenge.Token,
+			ExpiresAt:      challenge.ExpiresAt,
+		})
+		return
+	}
+
+	h.writeSession(w, r, user)
+}
+
+// LoginMFA exchanges an mfa_required challenge and a TOTP or recovery code
+// for tokens.
+func (h *Handler) LoginMFA(w http.ResponseWriter, r *http.Request) {
+	var payload mfaLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeProblem(w, r, problemInvalidPayload, "request body must be valid JSON")
+		return
+	}
+
+	user, err := h.mfa.Complete(r.Context(), strings.TrimSpace(payload.ChallengeToken), payload.Code)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	h.writeSession(w, r, user)
+}
+
+// EnrollMFA starts TOTP enrollment for the caller.
+func (h *Handler) EnrollMFA(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authctx.UserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, problemUnauthorized, "missing or invalid access token")
+		return
+	}
+
+	enrollment, err := h.mfa.Enroll(r.Context(), userID)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
+	writeJSON(w, http.StatusOK, mfaEnrollResponse{Secret: enrollment.Secret, URI: enrollment.URI})
+}
 
-	token, err := h.jwtManager.GenerateToken(user.ID)
+// ConfirmMFA activates the caller's pending enrollment and returns their
+// recovery codes.
+func (h *Handler) ConfirmMFA(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authctx.UserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, problemUnauthorized, "missing or invalid access token")
+		return
+	}
+	var payload mfaCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeProblem(w, r, problemInvalidPayload, "request body must be valid JSON")
+		return
+	}
+
+	codes, err := h.mfa.Confirm(r.Context(), userID, strings.TrimSpace(payload.Code))
 	if err != nil {
-		http.Error(w, "failed to create token", http.StatusInternalServerError)
+		handleError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableMFA turns two-factor authentication off for the caller.
+func (h *Handler) DisableMFA(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authctx.UserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, problemUnauthorized, "missing or invalid access token")
+		return
+	}
+	var payload mfaCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeProblem(w, r, problemInvalidPayload, "request body must be valid JSON")
+		return
+	}
+
+	if err := h.mfa.Disable(r.Context(), userID, payload.Code); err != nil {
+		handleError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) writeSession(w http.ResponseWriter, r *http.Request, user domain.User) {
+	tokens, err := h.sessions.Issue(r.Context(), user)
+	if err != nil {
+		writeInternalError(w, r, err)
 		return
 	}
 
 	writeJSON(w, http.StatusOK, authResponse{
-		Token: token,
-		User:  user.Sanitize(),
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		User:         user.Sanitize(),
+	})
+}
+
+// Refresh rotates a refresh token and returns a new token pair.
+func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var payload refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeProblem(w, r, problemInvalidPayload, "request body must be valid JSON")
+		return
+	}
+
+	tokens, err := h.sessions.Refresh(r.Context(), strings.TrimSpace(payload.RefreshToken))
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 	})
 }
 
-// ListUsers returns all users (sans passwords).
+// Logout revokes the caller's access token and, optionally, its refresh token.
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := authctx.ClaimsFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, problemUnauthorized, "missing or invalid access token")
+		return
+	}
+
+	var payload logoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+		writeProblem(w, r, problemInvalidPayload, "request body must be valid JSON")
+		return
+	}
+
+	if err := h.sessions.Logout(r.Context(), claims, strings.TrimSpace(payload.RefreshToken)); err != nil {
+		handleError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LogoutAll revokes every token issued to the caller before now.
+func (h *Handler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authctx.UserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, problemUnauthorized, "missing or invalid access token")
+		return
+	}
+
+	if err := h.sessions.LogoutAll(r.Context(), userID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ForgotPassword emails a reset token. It answers 202 whether or not the
+// address is registered, and delivery failures are logged rather than
+// reported, so the response never reveals which accounts exist.
+func (h *Handler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var payload forgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeProblem(w, r, problemInvalidPayload, "request body must be valid JSON")
+		return
+	}
+
+	if err := h.resets.Forgot(r.Context(), payload.Email); err != nil {
+		if errors.Is(err, domain.ErrInvalidEmail) {
+			handleError(w, r, err)
+			return
+		}
+		log.Printf("password reset request failed: %v", err)
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ResetPassword redeems a reset token and sets a new password.
+func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var payload resetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeProblem(w, r, problemInvalidPayload, "request body must be valid JSON")
+		return
+	}
+
+	if err := h.resets.Reset(r.Context(), strings.TrimSpace(payload.Token), payload.Password); err != nil {
+		handleError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VerifyEmail redeems the token from a verification email.
+func (h *Handler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	user, err := h.verifications.Verify(r.Context(), strings.TrimSpace(r.URL.Query().Get("token")))
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, user.Sanitize())
+}
+
+// ResendVerification emails the caller a new verification link.
+func (h *Handler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authctx.UserIDFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, problemUnauthorized, "missing or invalid access token")
+		return
+	}
+
+	if err := h.verifications.Resend(r.Context(), userID); err != nil {
+		handleError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ListUsers returns one page of users (sans passwords), newest first unless
+// sorted otherwise. The query parameters filter, sort, and page the list; see
+// parseListQuery. Only admins may list.
 func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := h.service.List(r.Context())
+	input, invalid := parseListQuery(r.URL.Query())
+	if len(invalid) > 0 {
+		writeProblem(w, r, problemInvalidQuery, "one or more query parameters are invalid", invalid...)
+		return
+	}
+
+	result, err := h.service.List(r.Context(), input)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	public := make([]domain.UserPublic, 0, len(users))
-	for _, u := range users {
-		public = append(public, u.Sanitize())
+	resp := listUsersResponse{
+		Users:      make([]any, 0, len(result.Users)),
+		NextCursor: result.NextCursor,
+	}
+	for _, u := range result.Users {
+		resp.Users = append(resp.Users, publicUser(u, input.Fields))
 	}
 
-	writeJSON(w, http.StatusOK, public)
+	writeJSON(w, http.StatusOK, resp)
 }
 
-// GetUser returns single user info.
+// GetUser returns single user info, restricted to the fields query parameter
+// when it is given.
 func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {
+	fields, invalid := parseFields(r.URL.Query().Get("fields"))
+	if len(invalid) > 0 {
+		writeProblem(w, r, problemInvalidQuery, "one or more query parameters are invalid", invalid...)
+		return
+	}
+
 	id := chi.URLParam(r, "id")
 	user, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, user.Sanitize())
+	w.Header().Set("ETag", application.ProjectedETag(user, fields))
+	writeJSON(w, http.StatusOK, publicUser(user, fields))
 }
 
-// UpdateUser updates allowed fields.
+// UpdateUser updates allowed fields. With an If-Match header naming the ETag
+// from GetUser, with or without fields, or a list of ETags, the update only
+// applies if the user is still at one of those versions.
 func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	if authID, ok := authctx.UserIDFromContext(r.Context()); ok && authID != id {
-		http.Error(w, "forbidden", http.StatusForbidden)
-		return
-	}
 	var payload updateRequest
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, "invalid payload", http.StatusBadRequest)
+		writeProblem(w, r, problemInvalidPayload, "request body must be valid JSON")
 		return
 	}
 
@@ -160,52 +472,123 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		payload.Email = &trimmed
 	}
 
+	ifMatch, err := h.resolveIfMatch(r, id)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
 	updated, err := h.service.Update(r.Context(), id, application.UpdateInput{
-		Name:  payload.Name,
-		Email: payload.Email,
+		Name:    payload.Name,
+		Email:   payload.Email,
+		IfMatch: ifMatch,
 	})
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
+	if payload.Email != nil && h.verifications != nil {
+		if err := h.verifications.Send(r.Context(), updated); err != nil {
+			log.Printf("verification email failed: %v", err)
+		}
+	}
+
+	w.Header().Set("ETag", application.ETag(updated))
 	writeJSON(w, http.StatusOK, updated.Sanitize())
 }
 
-// DeleteUser removes a user.
-func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+// resolveIfMatch reduces the If-Match header to the single ETag Update checks
+// against, or "" when there is no precondition. "*" matches any existing
+// user, as an update without a precondition does. Weak tags never match, as
+// If-Match uses strong comparison. When several tags are listed, the one
+// naming the user's current version is used, and the repository still
+// refuses the update if the user changes before it applies.
+func (h *Handler) resolveIfMatch(r *http.Request, id string) (string, error) {
+	header := strings.TrimSpace(strings.Join(r.Header.Values("If-Match"), ","))
+	if header == "" || header == "*" {
+		return "", nil
+	}
+
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !strings.HasPrefix(tag, "W/") {
+			tags = append(tags, tag)
+		}
+	}
+	switch len(tags) {
+	case 0:
+		return "", application.ErrVersionMismatch
+	case 1:
+		return tags[0], nil
+	}
+
+	current, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		return "", err
+	}
+	for _, tag := range tags {
+		if application.ETagMatches(current, tag) {
+			return tag, nil
+		}
+	}
+	return "", application.ErrVersionMismatch
+}
+
+// ChangePassword sets a new password for the caller. Tokens issued before the
+// change, including the one used for this request, stop working.
+func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	if authID, ok := authctx.UserIDFromContext(r.Context()); ok && authID != id {
-		http.Error(w, "forbidden", http.StatusForbidden)
+	var payload changePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeProblem(w, r, problemInvalidPayload, "request body must be valid JSON")
 		return
 	}
+
+	if err := h.service.ChangePassword(r.Context(), id, payload.CurrentPassword, payload.NewPassword); err != nil {
+		handleError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteUser removes a user.
+func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
 	if err := h.service.Delete(r.Context(), id); err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// RestoreUser brings back a deleted user that has not been purged yet. Only
+// admins may restore.
+func (h *Handler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	user, err := h.service.Restore(r.Context(), id)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", application.ETag(user))
+	writeJSON(w, http.StatusOK, user.Sanitize())
+}
+
 func writeJSON(w http.ResponseWriter, status int, value any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	_ = json.NewEncoder(w).Encode(value)
 }
 
-func handleError(w http.ResponseWriter, err error) {
-	switch {
-	case errors.Is(err, application.ErrDuplicateEmail):
-		http.Error(w, err.Error(), http.StatusConflict)
-	case errors.Is(err, application.ErrInvalidCredentials):
-		http.Error(w, err.Error(), http.StatusUnauthorized)
-	case errors.Is(err, application.ErrNotFound):
-		http.Error(w, err.Error(), http.StatusNotFound)
-	case errors.Is(err, application.ErrNoFieldsToUpdate),
-		errors.Is(err, domain.ErrInvalidEmail),
-		errors.Is(err, domain.ErrInvalidName),
-		errors.Is(err, domain.ErrInvalidPassword):
-		http.Error(w, err.Error(), http.StatusBadRequest)
-	default:
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+// clientIP returns the caller's address. The router's RealIP middleware has
+// already replaced RemoteAddr with the forwarded address when there is one,
+// which is only trustworthy behind a proxy that overwrites those headers.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
 	}
+	return host
 }
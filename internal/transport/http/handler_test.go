@@ -7,12 +7,15 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"backend-challenge/internal/application"
 	"backend-challenge/internal/domain"
 	jwtinfra "backend-challenge/internal/infrastructure/jwt"
+	"backend-challenge/internal/infrastructure/mailer"
 	"backend-challenge/internal/transport/authctx"
 	transport "backend-challenge/internal/transport/http"
 
@@ -26,9 +29,13 @@ type fakeRepo struct {
 	createFn   func(context.Context, domain.User) (domain.User, error)
 	getByEmail func(context.Context, string) (domain.User, error)
 	getByID    func(context.Context, string) (domain.User, error)
-	listFn     func(context.Context) ([]domain.User, error)
+	listFn     func(context.Context, domain.UserListQuery) (domain.UserPage, error)
 	updateFn   func(context.Context, string, domain.UpdateUser) (domain.User, error)
+	passwordFn func(context.Context, string, string, time.Time) error
+	rehashFn   func(context.Context, string, string, string) error
+	verifyFn   func(context.Context, string, time.Time) (domain.User, error)
 	deleteFn   func(context.Context, string) error
+	restoreFn  func(context.Context, string) (domain.User, error)
 	countFn    func(context.Context) (int64, error)
 }
 
@@ -53,11 +60,11 @@ func (f *fakeRepo) GetByID(ctx context.Context, id string) (domain.User, error)
 	return domain.User{}, application.ErrNotFound
 }
 
-func (f *fakeRepo) List(ctx context.Context) ([]domain.User, error) {
+func (f *fakeRepo) List(ctx context.Context, query domain.UserListQuery) (domain.UserPage, error) {
 	if f.listFn != nil {
-		return f.listFn(ctx)
+		return f.listFn(ctx, query)
 	}
-	return nil, nil
+	return domain.UserPage{}, nil
 }
 
 func (f *fakeRepo) Update(ctx context.Context, id string, update domain.UpdateUser) (domain.User, error) {
@@ -67,13 +74,61 @@ func (f *fakeRepo) Update(ctx context.Context, id string, update domain.UpdateUs
 	return domain.User{}, nil
 }
 
-func (f *fakeRepo) Delete(ctx context.Context, id string) error {
+func (f *fakeRepo) UpdatePassword(ctx context.Context, id, passwordHash string, changedAt time.Time) error {
+	if f.passwordFn != nil {
+		return f.passwordFn(ctx, id, passwordHash, changedAt)
+	}
+	return nil
+}
+
+func (f *fakeRepo) UpdateRoles(ctx context.Context, id string, roles []domain.Role) error {
+	return nil
+}
+
+func (f *fakeRepo) ReplacePasswordHash(ctx context.Context, id, oldHash, newHash string) error {
+	if f.rehashFn != nil {
+		return f.rehashFn(ctx, id, oldHash, newHash)
+	}
+	return nil
+}
+
+func (f *fakeRepo) MarkVerified(ctx context.Context, id string, at time.Time) (domain.User, error) {
+	if f.verifyFn != nil {
+		return f.verifyFn(ctx, id, at)
+	}
+	return domain.User{ID: id, VerifiedAt: &at}, nil
+}
+
+func (f *fakeRepo) UpdateMFA(ctx context.Context, id string, mfa domain.MFA) error {
+	return nil
+}
+
+func (f *fakeRepo) UseTOTPStep(ctx context.Context, id string, step int64) error {
+	return nil
+}
+
+func (f *fakeRepo) UseRecoveryCode(ctx context.Context, id, codeHash string) error {
+	return application.ErrNotFound
+}
+
+func (f *fakeRepo) Delete(ctx context.Context, id string, at time.Time) error {
 	if f.deleteFn != nil {
 		return f.deleteFn(ctx, id)
 	}
 	return nil
 }
 
+func (f *fakeRepo) Restore(ctx context.Context, id string) (domain.User, error) {
+	if f.restoreFn != nil {
+		return f.restoreFn(ctx, id)
+	}
+	return domain.User{}, application.ErrNotFound
+}
+
+func (f *fakeRepo) Purge(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	return nil, nil
+}
+
 func (f *fakeRepo) Count(ctx context.Context) (int64, error) {
 	if f.countFn != nil {
 		return f.countFn(ctx)
@@ -91,8 +146,7 @@ func TestRegisterHandlerSuccess(t *testing.T) {
 		},
 	}
 	service := application.NewUserService(repo)
-	jwtManager := jwtinfra.NewManager("secret", time.Hour, "issuer")
-	handler := transport.NewHandler(service, jwtManager)
+	handler := transport.NewHandler(service, newSessions(), nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewBufferString(`{"name":"Test","email":"test@example.com","password":"strongpass"}`))
 	req.Header.Set("Content-Type", "application/json")
@@ -123,8 +177,7 @@ func TestRegisterHandlerError(t *testing.T) {
 		},
 	}
 	service := application.NewUserService(repo)
-	jwtManager := jwtinfra.NewManager("secret", time.Hour, "issuer")
-	handler := transport.NewHandler(service, jwtManager)
+	handler := transport.NewHandler(service, newSessions(), nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewBufferString(`{"name":"Test","email":"test@example.com","password":"strongpass"}`))
 	req.Header.Set("Content-Type", "application/json")
@@ -139,8 +192,7 @@ func TestRegisterHandlerError(t *testing.T) {
 func TestRegisterHandlerInvalidPayload(t *testing.T) {
 	repo := &fakeRepo{}
 	service := application.NewUserService(repo)
-	jwtManager := jwtinfra.NewManager("secret", time.Hour, "issuer")
-	handler := transport.NewHandler(service, jwtManager)
+	handler := transport.NewHandler(service, newSessions(), nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewBufferString(`{`))
 	req.Header.Set("Content-Type", "application/json")
@@ -158,8 +210,7 @@ func TestLoginHandler(t *testing.T) {
 		},
 	}
 	service := application.NewUserService(repo)
-	jwtManager := jwtinfra.NewManager("secret", time.Hour, "issuer")
-	handler := transport.NewHandler(service, jwtManager)
+	handler := transport.NewHandler(service, newSessions(), nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBufferString(`{"email":"test@example.com","password":"pass"}`))
 	req.Header.Set("Content-Type", "application/json")
@@ -174,8 +225,7 @@ func TestLoginHandler(t *testing.T) {
 func TestLoginHandlerError(t *testing.T) {
 	repo := &fakeRepo{}
 	service := application.NewUserService(repo)
-	jwtManager := jwtinfra.NewManager("secret", time.Hour, "issuer")
-	handler := transport.NewHandler(service, jwtManager)
+	handler := transport.NewHandler(service, newSessions(), nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBufferString(`{"email":"test@example.com","password":"wrong"}`))
 	req.Header.Set("Content-Type", "application/json")
@@ -189,19 +239,19 @@ func TestLoginHandlerError(t *testing.T) {
 
 func TestListAndGetHandlers(t *testing.T) {
 	repo := &fakeRepo{
-		listFn: func(context.Context) ([]domain.User, error) {
-			return []domain.User{{ID: "1", Name: "A", Email: "a@example.com"}}, nil
+		listFn: func(context.Context, domain.UserListQuery) (domain.UserPage, error) {
+			return domain.UserPage{Users: []domain.User{{ID: "1", Name: "A", Email: "a@example.com"}}}, nil
 		},
 		getByID: func(context.Context, string) (domain.User, error) {
 			return domain.User{ID: "1", Name: "A", Email: "a@example.com"}, nil
 		},
 	}
 	service := application.NewUserService(repo)
-	jwtManager := jwtinfra.NewManager("secret", time.Hour, "issuer")
-	handler := transport.NewHandler(service, jwtManager)
+	handler := transport.NewHandler(service, newSessions(), nil, nil, nil)
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req = req.WithContext(withAdmin(req.Context()))
 	handler.ListUsers(rr, req)
 	if rr.Code != http.StatusOK {
 		t.Fatalf("expected 200 got %d", rr.Code)
@@ -217,22 +267,161 @@ func TestListAndGetHandlers(t *testing.T) {
 	}
 }
 
+func TestListHandlerPagination(t *testing.T) {
+	var query domain.UserListQuery
+	repo := &fakeRepo{
+		listFn: func(_ context.Context, q domain.UserListQuery) (domain.UserPage, error) {
+			query = q
+			next := domain.UserCursor{CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), ID: "1"}
+			return domain.UserPage{Users: []domain.User{{ID: "1", Name: "A", Email: "a@example.com"}}, Next: &next}, nil
+		},
+	}
+	handler := transport.NewHandler(application.NewUserService(repo), newSessions(), nil, nil, nil)
+
+	list := func(target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = req.WithContext(withAdmin(req.Context()))
+		handler.ListUsers(rr, req)
+		return rr
+	}
+
+	rr := list("/users?limit=1")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", rr.Code)
+	}
+	var body struct {
+		Users      []domain.UserPublic `json:"users"`
+		NextCursor string              `json:"nextCursor"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if query.Limit != 1 || len(body.Users) != 1 || body.NextCursor == "" {
+		t.Fatalf("expected one user and a next cursor for limit 1 got %+v", body)
+	}
+
+	if rr := list("/users?limit=1&cursor=" + body.NextCursor); rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 following the cursor got %d", rr.Code)
+	}
+	if query.After == nil || query.After.ID != "1" {
+		t.Fatalf("expected the cursor to be passed to the repository got %+v", query.After)
+	}
+
+	for _, target := range []string{"/users?limit=abc", "/users?limit=-1", "/users?cursor=bogus"} {
+		rr := list(target)
+		if rr.Code != http.StatusBadRequest || decodeProblem(t, rr).Code != "INVALID_QUERY" {
+			t.Fatalf("%s: expected an INVALID_QUERY problem got %d %s", target, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestListHandlerFilters(t *testing.T) {
+	var query domain.UserListQuery
+	repo := &fakeRepo{
+		listFn: func(_ context.Context, q domain.UserListQuery) (domain.UserPage, error) {
+			query = q
+			return domain.UserPage{}, nil
+		},
+	}
+	handler := transport.NewHandler(application.NewUserService(repo), newSessions(), nil, nil, nil)
+
+	list := func(target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = req.WithContext(withAdmin(req.Context()))
+		handler.ListUsers(rr, req)
+		return rr
+	}
+
+	rr := list("/users?q=%20jan%20&created_after=2024-01-01T00:00:00Z&created_before=2024-02-01T00:00:00Z&sort=-name")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d %s", rr.Code, rr.Body.String())
+	}
+	after, before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	if query.Filter.Search != "jan" ||
+		query.Filter.CreatedAfter == nil || !query.Filter.CreatedAfter.Equal(after) ||
+		query.Filter.CreatedBefore == nil || !query.Filter.CreatedBefore.Equal(before) ||
+		query.Sort != (domain.UserSort{Field: domain.SortByName, Descending: true}) {
+		t.Fatalf("expected the filter and sort to reach the repository got %+v", query)
+	}
+
+	if rr := list("/users"); rr.Code != http.StatusOK || query.Sort != domain.DefaultUserSort || query.Filter.IncludeDeleted {
+		t.Fatalf("expected active users, newest first, by default got %d %+v", rr.Code, query)
+	}
+	if rr := list("/users?include_deleted=true"); rr.Code != http.StatusOK || !query.Filter.IncludeDeleted {
+		t.Fatalf("expected deleted users on request got %d %+v", rr.Code, query.Filter)
+	}
+
+	rr = list("/users?sort=password&created_after=yesterday&include_deleted=maybe&role=admin")
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 got %d", rr.Code)
+	}
+	if got := problemFields(decodeProblem(t, rr)); got != "role:unknown_parameter,created_after:invalid_format,include_deleted:invalid_format,sort:unknown_field" {
+		t.Fatalf("expected every invalid parameter to be reported got %s", got)
+	}
+}
+
+func TestListAndGetHandlersSelectFields(t *testing.T) {
+	var query domain.UserListQuery
+	user := domain.User{ID: "1", Name: "A", Email: "a@example.com", Roles: []domain.Role{domain.RoleUser}}
+	repo := &fakeRepo{
+		listFn: func(_ context.Context, q domain.UserListQuery) (domain.UserPage, error) {
+			query = q
+			return domain.UserPage{Users: []domain.User{user}}, nil
+		},
+		getByID: func(context.Context, string) (domain.User, error) {
+			return user, nil
+		},
+	}
+	handler := transport.NewHandler(application.NewUserService(repo), newSessions(), nil, nil, nil)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users?fields=id,name", nil)
+	req = req.WithContext(withAdmin(req.Context()))
+	handler.ListUsers(rr, req)
+	if rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != `{"users":[{"id":"1","name":"A"}]}` {
+		t.Fatalf("expected only id and name got %d %s", rr.Code, rr.Body.String())
+	}
+	if len(query.Fields) != 2 || query.Fields[0] != domain.FieldID || query.Fields[1] != domain.FieldName {
+		t.Fatalf("expected the fields to reach the repository got %v", query.Fields)
+	}
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = withRouteParam(req, "id", "1")
+		req = req.WithContext(authctx.WithUserID(req.Context(), "1"))
+		handler.GetUser(rr, req)
+		return rr
+	}
+	if rr := get("/users/1?fields=email"); rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != `{"email":"a@example.com"}` {
+		t.Fatalf("expected only email got %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := get("/users/1?fields=id,password"); rr.Code != http.StatusBadRequest || problemFields(decodeProblem(t, rr)) != "fields:unknown_field" {
+		t.Fatalf("expected an unknown field to be rejected got %d %s", rr.Code, rr.Body.String())
+	}
+}
+
 func TestListHandlerError(t *testing.T) {
 	repo := &fakeRepo{
-		listFn: func(context.Context) ([]domain.User, error) {
-			return nil, errors.New("list failed")
+		listFn: func(context.Context, domain.UserListQuery) (domain.UserPage, error) {
+			return domain.UserPage{}, errors.New("list failed")
 		},
 	}
 	service := application.NewUserService(repo)
-	jwtManager := jwtinfra.NewManager("secret", time.Hour, "issuer")
-	handler := transport.NewHandler(service, jwtManager)
+	handler := transport.NewHandler(service, newSessions(), nil, nil, nil)
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req = req.WithContext(withAdmin(req.Context()))
 	handler.ListUsers(rr, req)
 	if rr.Code != http.StatusInternalServerError {
 		t.Fatalf("expected 500 got %d", rr.Code)
 	}
+	if problem := decodeProblem(t, rr); problem.Code != "INTERNAL" || strings.Contains(rr.Body.String(), "list failed") {
+		t.Fatalf("expected an opaque internal error got %s", rr.Body.String())
+	}
 }
 
 func TestGetHandlerNotFound(t *testing.T) {
@@ -242,11 +431,11 @@ func TestGetHandlerNotFound(t *testing.T) {
 		},
 	}
 	service := application.NewUserService(repo)
-	jwtManager := jwtinfra.NewManager("secret", time.Hour, "issuer")
-	handler := transport.NewHandler(service, jwtManager)
+	handler := transport.NewHandler(service, newSessions(), nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
 	req = withRouteParam(req, "id", "1")
+	req = req.WithContext(authctx.WithUserID(req.Context(), "1"))
 	rr := httptest.NewRecorder()
 	handler.GetUser(rr, req)
 	if rr.Code != http.StatusNotFound {
@@ -264,8 +453,7 @@ func TestUpdateAndDeleteHandlers(t *testing.T) {
 		},
 	}
 	service := application.NewUserService(repo)
-	jwtManager := jwtinfra.NewManager("secret", time.Hour, "issuer")
-	handler := transport.NewHandler(service, jwtManager)
+	handler := transport.NewHandler(service, newSessions(), nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodPatch, "/users/1", bytes.NewBufferString(`{"name":"Updated"}`))
 	req = withRouteParam(req, "id", "1")
@@ -287,10 +475,89 @@ func TestUpdateAndDeleteHandlers(t *testing.T) {
 	}
 }
 
+func TestUpdateHandlerIfMatch(t *testing.T) {
+	service := application.NewUserService(memory.NewUserRepository())
+	handler := transport.NewHandler(service, newSessions(), nil, nil, nil)
+	user, err := service.Register(context.Background(), application.RegisterInput{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users/"+user.ID, nil)
+	req = withRouteParam(req, "id", user.ID)
+	req = req.WithContext(withAdmin(req.Context()))
+	rr := httptest.NewRecorder()
+	handler.GetUser(rr, req)
+	etag := rr.Header().Get("ETag")
+	if rr.Code != http.StatusOK || etag != `"1"` {
+		t.Fatalf("expected 200 with an ETag got %d %q", rr.Code, etag)
+	}
+
+	update := func(name, ifMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/users/"+user.ID, bytes.NewBufferString(`{"name":"`+name+`"}`))
+		req = withRouteParam(req, "id", user.ID)
+		req = req.WithContext(withAdmin(req.Context()))
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rr := httptest.NewRecorder()
+		handler.UpdateUser(rr, req)
+		return rr
+	}
+
+	rr = update("Alicia", etag)
+	if rr.Code != http.StatusOK || rr.Header().Get("ETag") != `"2"` {
+		t.Fatalf("expected 200 with the next ETag got %d %q", rr.Code, rr.Header().Get("ETag"))
+	}
+	rr = update("Ally", etag)
+	if rr.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 for a stale ETag got %d", rr.Code)
+	}
+	if p := decodeProblem(t, rr); p.Code != "PRECONDITION_FAILED" {
+		t.Fatalf("expected PRECONDITION_FAILED got %s", p.Code)
+	}
+	if rr := update("Ally", "*"); rr.Code != http.StatusOK || rr.Header().Get("ETag") != `"3"` {
+		t.Fatalf("expected * to match any version got %d %q", rr.Code, rr.Header().Get("ETag"))
+	}
+	if rr := update("Al", ""); rr.Code != http.StatusOK || rr.Header().Get("ETag") != `"4"` {
+		t.Fatalf("expected an update without If-Match to apply got %d", rr.Code)
+	}
+	if rr := update("Alice", `W/"4"`); rr.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected a weak ETag never to match got %d", rr.Code)
+	}
+	if rr := update("Alice", `"1", "2"`); rr.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 when no listed ETag is current got %d", rr.Code)
+	}
+	if rr := update("Alice", `"1", W/"2", "4"`); rr.Code != http.StatusOK || rr.Header().Get("ETag") != `"5"` {
+		t.Fatalf("expected a list naming the current ETag to match got %d %q", rr.Code, rr.Header().Get("ETag"))
+	}
+	if rr := update("Alicia", `W/"1", "5"`); rr.Code != http.StatusOK || rr.Header().Get("ETag") != `"6"` {
+		t.Fatalf("expected the only strong ETag to be checked got %d %q", rr.Code, rr.Header().Get("ETag"))
+	}
+
+	// A projected response has its own tag, which still works as a
+	// precondition.
+	req = httptest.NewRequest(http.MethodGet, "/users/"+user.ID+"?fields=name,email,name", nil)
+	req = withRouteParam(req, "id", user.ID)
+	req = req.WithContext(withAdmin(req.Context()))
+	rr = httptest.NewRecorder()
+	handler.GetUser(rr, req)
+	projected := rr.Header().Get("ETag")
+	if rr.Code != http.StatusOK || projected != `"6;email.name"` {
+		t.Fatalf("expected a projected ETag got %d %q", rr.Code, projected)
+	}
+	if rr := update("Ali", `"5", `+projected); rr.Code != http.StatusOK || rr.Header().Get("ETag") != `"7"` {
+		t.Fatalf("expected the projected ETag to match got %d %q", rr.Code, rr.Header().Get("ETag"))
+	}
+	if rr := update("Alice", projected); rr.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 for a stale projected ETag got %d", rr.Code)
+	}
+}
+
 func TestUpdateForbidden(t *testing.T) {
 	repo := &fakeRepo{}
 	service := application.NewUserService(repo)
-	handler := transport.NewHandler(service, jwtinfra.NewManager("secret", time.Hour, "issuer"))
+	handler := transport.NewHandler(service, newSessions(), nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodPatch, "/users/1", bytes.NewBufferString(`{"name":"Updated"}`))
 	req = withRouteParam(req, "id", "1")
@@ -309,7 +576,7 @@ func TestUpdateHandlerError(t *testing.T) {
 		},
 	}
 	service := application.NewUserService(repo)
-	handler := transport.NewHandler(service, jwtinfra.NewManager("secret", time.Hour, "issuer"))
+	handler := transport.NewHandler(service, newSessions(), nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodPatch, "/users/1", bytes.NewBufferString(`{"email":"dup@example.com"}`))
 	req = withRouteParam(req, "id", "1")
@@ -329,7 +596,7 @@ func TestDeleteHandlerError(t *testing.T) {
 		},
 	}
 	service := application.NewUserService(repo)
-	handler := transport.NewHandler(service, jwtinfra.NewManager("secret", time.Hour, "issuer"))
+	handler := transport.NewHandler(service, newSessions(), nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodDelete, "/users/1", nil)
 	req = withRouteParam(req, "id", "1")
@@ -343,12 +610,12 @@ func TestDeleteHandlerError(t *testing.T) {
 
 func TestAuthMiddleware(t *testing.T) {
 	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
-	token, err := manager.GenerateToken("abc")
+	token, err := manager.GenerateToken("abc", nil)
 	if err != nil {
 		t.Fatalf("generate token: %v", err)
 	}
 
-	middleware := transport.AuthMiddleware(manager)
+	middleware := transport.AuthMiddleware(newSessions())
 	called := false
 	h := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		called = true
@@ -371,8 +638,7 @@ func TestAuthMiddleware(t *testing.T) {
 }
 
 func TestAuthMiddlewareInvalidHeader(t *testing.T) {
-	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
-	middleware := transport.AuthMiddleware(manager)
+	middleware := transport.AuthMiddleware(newSessions())
 	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
 	req.Header.Set("Authorization", "Token invalid")
 	rr := httptest.NewRecorder()
@@ -390,6 +656,56 @@ func TestAuthMiddlewareInvalidHeader(t *testing.T) {
 	}
 }
 
+func TestLogoutHandlers(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+	repo := memory.NewUserRepository()
+	service := application.NewUserService(repo)
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer", jwtinfra.WithClock(clock))
+	sessions := application.NewSessionService(manager, memory.NewRefreshTokenRepository(), memory.NewTokenRevocationStore(), time.Hour, application.WithSessionClock(clock))
+	router := transport.NewRouter(transport.NewHandler(service, sessions, nil, nil, nil), sessions, manager)
+
+	register := func(email string) string {
+		req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewBufferString(`{"name":"Test","email":"`+email+`","password":"pass12345"}`))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("expected 201 got %d", rr.Code)
+		}
+		var body struct {
+			Token string `json:"token"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+			t.Fatalf("parse response: %v", err)
+		}
+		return body.Token
+	}
+	call := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	token := register("logout@example.com")
+	if code := call(http.MethodPost, "/auth/logout", token); code != http.StatusNoContent {
+		t.Fatalf("expected 204 got %d", code)
+	}
+	if code := call(http.MethodGet, "/users", token); code != http.StatusUnauthorized {
+		t.Fatalf("expected revoked token to be rejected got %d", code)
+	}
+
+	token = register("logout-all@example.com")
+	now = now.Add(time.Second)
+	if code := call(http.MethodPost, "/auth/logout-all", token); code != http.StatusNoContent {
+		t.Fatalf("expected 204 got %d", code)
+	}
+	if code := call(http.MethodGet, "/users", token); code != http.StatusUnauthorized {
+		t.Fatalf("expected token issued before logout-all to be rejected got %d", code)
+	}
+}
+
 func TestLoggingMiddleware(t *testing.T) {
 	handler := transport.LoggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusTeapot)
@@ -406,8 +722,8 @@ func TestNewRouterIntegration(t *testing.T) {
 	repo := memory.NewUserRepository()
 	service := application.NewUserService(repo)
 	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
-	handler := transport.NewHandler(service, manager)
-	router := transport.NewRouter(handler, manager)
+	sessions := application.NewSessionService(manager, memory.NewRefreshTokenRepository(), memory.NewTokenRevocationStore(), time.Hour)
+	router := transport.NewRouter(transport.NewHandler(service, sessions, nil, nil, nil), sessions, manager)
 
 	req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewBufferString(`{"name":"Test","email":"route@example.com","password":"pass12345"}`))
 	req.Header.Set("Content-Type", "application/json")
@@ -418,6 +734,183 @@ func TestNewRouterIntegration(t *testing.T) {
 	}
 }
 
+type problemBody struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail"`
+	Instance  string `json:"instance"`
+	Code      string `json:"code"`
+	RequestID string `json:"requestId"`
+	Errors    []struct {
+		Field   string `json:"field"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func decodeProblem(t *testing.T, rr *httptest.ResponseRecorder) problemBody {
+	t.Helper()
+	if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("expected application/problem+json got %q", ct)
+	}
+	var problem problemBody
+	if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("parse problem: %v", err)
+	}
+	if problem.Status != rr.Code {
+		t.Fatalf("expected status %d in body got %d", rr.Code, problem.Status)
+	}
+	return problem
+}
+
+// problemFields lists a problem's invalid fields as field:code pairs.
+func problemFields(problem problemBody) string {
+	fields := make([]string, 0, len(problem.Errors))
+	for _, fe := range problem.Errors {
+		fields = append(fields, fe.Field+":"+fe.Code)
+	}
+	return strings.Join(fields, ",")
+}
+
+func TestRouterProblemResponses(t *testing.T) {
+	service := application.NewUserService(memory.NewUserRepository())
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+	sessions := application.NewSessionService(manager, memory.NewRefreshTokenRepository(), memory.NewTokenRevocationStore(), time.Hour)
+	router := transport.NewRouter(transport.NewHandler(service, sessions, nil, nil, nil), sessions, manager)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send(http.MethodPost, "/auth/register", `{"name":" ","email":"nope","password":"short"}`)
+	problem := decodeProblem(t, rr)
+	if rr.Code != http.StatusBadRequest || problem.Type != "/problems/validation" || problem.Code != "VALIDATION_FAILED" {
+		t.Fatalf("unexpected validation problem %+v", problem)
+	}
+	if problem.RequestID == "" || problem.Instance != "/auth/register" {
+		t.Fatalf("expected request id and instance got %+v", problem)
+	}
+	if fields := problemFields(problem); fields != "name:required,email:invalid_format,password:too_short" {
+		t.Fatalf("expected every invalid field got %v", fields)
+	}
+
+	if rr := send(http.MethodPost, "/auth/register", `{"name":"Test","email":"dup@example.com","password":"pass12345"}`); rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201 got %d", rr.Code)
+	}
+	rr = send(http.MethodPost, "/auth/register", `{"name":"Test","email":"dup@example.com","password":"pass12345"}`)
+	if problem := decodeProblem(t, rr); rr.Code != http.StatusConflict || problem.Code != "DUPLICATE_EMAIL" {
+		t.Fatalf("unexpected duplicate problem %d %+v", rr.Code, problem)
+	}
+
+	rr = send(http.MethodPost, "/auth/login", `{`)
+	if problem := decodeProblem(t, rr); rr.Code != http.StatusBadRequest || problem.Code != "INVALID_PAYLOAD" {
+		t.Fatalf("unexpected payload problem %d %+v", rr.Code, problem)
+	}
+
+	rr = send(http.MethodGet, "/users/1", "")
+	if problem := decodeProblem(t, rr); rr.Code != http.StatusUnauthorized || problem.Code != "UNAUTHORIZED" {
+		t.Fatalf("unexpected auth problem %d %+v", rr.Code, problem)
+	}
+
+	rr = send(http.MethodGet, "/nowhere", "")
+	if problem := decodeProblem(t, rr); rr.Code != http.StatusNotFound || problem.Code != "NOT_FOUND" {
+		t.Fatalf("unexpected not found problem %d %+v", rr.Code, problem)
+	}
+}
+
+func TestRefreshHandler(t *testing.T) {
+	repo := memory.NewUserRepository()
+	service := application.NewUserService(repo)
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+	sessions := application.NewSessionService(manager, memory.NewRefreshTokenRepository(), memory.NewTokenRevocationStore(), time.Hour)
+	router := transport.NewRouter(transport.NewHandler(service, sessions, nil, nil, nil), sessions, manager)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewBufferString(`{"name":"Test","email":"refresh@example.com","password":"pass12345"}`))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201 got %d", rr.Code)
+	}
+
+	var registered struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &registered); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if registered.RefreshToken == "" {
+		t.Fatalf("expected refresh token in response")
+	}
+
+	body := `{"refreshToken":"` + registered.RefreshToken + `"}`
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBufferString(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", rr.Code)
+	}
+
+	var refreshed struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &refreshed); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if refreshed.Token == "" || refreshed.RefreshToken == "" || refreshed.RefreshToken == registered.RefreshToken {
+		t.Fatalf("expected rotated token pair got %+v", refreshed)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBufferString(body)))
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 on reuse got %d", rr.Code)
+	}
+}
+
+func TestRefreshHandlerInvalidPayload(t *testing.T) {
+	handler := transport.NewHandler(application.NewUserService(&fakeRepo{}), newSessions(), nil, nil, nil)
+
+	rr := httptest.NewRecorder()
+	handler.Refresh(rr, httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBufferString(`{`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.Refresh(rr, httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBufferString(`{"refreshToken":"unknown"}`)))
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 got %d", rr.Code)
+	}
+}
+
+func newSessions() *application.SessionService {
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+	return application.NewSessionService(manager, memory.NewRefreshTokenRepository(), memory.NewTokenRevocationStore(), time.Hour)
+}
+
+func TestJWKSHandler(t *testing.T) {
+	rr := httptest.NewRecorder()
+	transport.JWKSHandler(jwtinfra.NewManager("secret", time.Hour, "issuer")).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", rr.Code)
+	}
+
+	var body struct {
+		Keys []map[string]any `json:"keys"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if body.Keys == nil || len(body.Keys) != 0 {
+		t.Fatalf("expected empty key set for HMAC manager got %+v", body.Keys)
+	}
+}
+
 func hashPassword(t *testing.T, password string) string {
 	t.Helper()
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -427,8 +920,482 @@ func hashPassword(t *testing.T, password string) string {
 	return string(hash)
 }
 
+func withAdmin(ctx context.Context) context.Context {
+	return authctx.WithClaims(ctx, application.AccessClaims{UserID: "admin", Roles: []domain.Role{domain.RoleAdmin}})
+}
+
 func withRouteParam(r *http.Request, key, value string) *http.Request {
 	rctx := chi.NewRouteContext()
 	rctx.URLParams.Add(key, value)
 	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
 }
+
+func TestPasswordResetHandlers(t *testing.T) {
+	repo := memory.NewUserRepository()
+	service := application.NewUserService(repo)
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+	sessions := application.NewSessionService(manager, memory.NewRefreshTokenRepository(), memory.NewTokenRevocationStore(), time.Hour)
+	var outbox bytes.Buffer
+	logMailer, err := mailer.NewLogMailer(&outbox, "no-reply@example.com")
+	if err != nil {
+		t.Fatalf("new mailer: %v", err)
+	}
+	resets := application.NewPasswordResetService(repo, nil, memory.NewOneTimeTokenRepository(), sessions, logMailer, time.Hour, "https://app.example.com/reset")
+	router := transport.NewRouter(transport.NewHandler(service, sessions, resets, nil, nil), sessions, manager)
+
+	post := func(path, body string) int {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body)))
+		return rr.Code
+	}
+
+	if code := post("/auth/register", `{"name":"Test","email":"reset@example.com","password":"pass12345"}`); code != http.StatusCreated {
+		t.Fatalf("expected 201 got %d", code)
+	}
+
+	if code := post("/auth/password/forgot", `{"email":"nobody@example.com"}`); code != http.StatusAccepted {
+		t.Fatalf("expected 202 for unknown email got %d", code)
+	}
+	if outbox.Len() != 0 {
+		t.Fatalf("expected no mail for unknown email")
+	}
+	if code := post("/auth/password/forgot", `{"email":"invalid"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid email got %d", code)
+	}
+	if code := post("/auth/password/forgot", `{"email":"reset@example.com"}`); code != http.StatusAccepted {
+		t.Fatalf("expected 202 got %d", code)
+	}
+
+	match := regexp.MustCompile(`token=([A-Za-z0-9_-]+)`).FindStringSubmatch(outbox.String())
+	if match == nil {
+		t.Fatalf("expected reset link in mail:\n%s", outbox.String())
+	}
+	reset := `{"token":"` + match[1] + `","password":"newpass123"}`
+
+	if code := post("/auth/password/reset", `{"token":"`+match[1]+`","password":"short"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for weak password got %d", code)
+	}
+	if code := post("/auth/password/reset", reset); code != http.StatusNoContent {
+		t.Fatalf("expected 204 got %d", code)
+	}
+	if code := post("/auth/password/reset", reset); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for reused token got %d", code)
+	}
+	if code := post("/auth/login", `{"email":"reset@example.com","password":"newpass123"}`); code != http.StatusOK {
+		t.Fatalf("expected login with new password to succeed got %d", code)
+	}
+}
+
+func TestEmailVerificationHandlers(t *testing.T) {
+	repo := memory.NewUserRepository()
+	service := application.NewUserService(repo, application.WithVerificationMode(application.VerificationLimited))
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+	sessions := application.NewSessionService(manager, memory.NewRefreshTokenRepository(), memory.NewTokenRevocationStore(), time.Hour)
+	var outbox bytes.Buffer
+	logMailer, err := mailer.NewLogMailer(&outbox, "no-reply@example.com")
+	if err != nil {
+		t.Fatalf("new mailer: %v", err)
+	}
+	verifications := application.NewEmailVerificationService(repo, memory.NewOneTimeTokenRepository(), logMailer, time.Hour, "https://app.example.com/verify")
+	router := transport.NewRouter(transport.NewHandler(service, sessions, nil, verifications, nil), sessions, manager)
+
+	do := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewBufferString(`{"name":"Test","email":"verify@example.com","password":"pass12345"}`))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201 got %d", rr.Code)
+	}
+	var registered struct {
+		Token string            `json:"token"`
+		User  domain.UserPublic `json:"user"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &registered); err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if registered.Token == "" || registered.User.EmailVerified {
+		t.Fatalf("expected token for unverified user in limited mode got %+v", registered)
+	}
+
+	if rr := do(http.MethodGet, "/users", registered.Token); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for unverified user got %d", rr.Code)
+	}
+	if rr := do(http.MethodGet, "/users/"+registered.User.ID, registered.Token); rr.Code != http.StatusOK {
+		t.Fatalf("expected own profile to stay readable got %d", rr.Code)
+	}
+	if rr := do(http.MethodPost, "/auth/verify/resend", registered.Token); rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 got %d", rr.Code)
+	}
+
+	matches := regexp.MustCompile(`token=([A-Za-z0-9_-]+)`).FindAllStringSubmatch(outbox.String(), -1)
+	if len(matches) != 2 {
+		t.Fatalf("expected registration and resend mails got:\n%s", outbox.String())
+	}
+	if rr := do(http.MethodGet, "/auth/verify?token="+matches[0][1], ""); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected superseded token to be rejected got %d", rr.Code)
+	}
+	rr = do(http.MethodGet, "/auth/verify?token="+matches[1][1], "")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"emailVerified":true`) {
+		t.Fatalf("expected verified user got %d %s", rr.Code, rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPatch, "/users/"+registered.User.ID, bytes.NewBufferString(`{"email":"moved@example.com"}`))
+	req.Header.Set("Authorization", "Bearer "+registered.Token)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"emailVerified":false`) {
+		t.Fatalf("expected a new email to need verification got %d %s", rr.Code, rr.Body.String())
+	}
+	if !strings.Contains(outbox.String(), "To: <moved@example.com>") {
+		t.Fatalf("expected a verification mail to the new address got:\n%s", outbox.String())
+	}
+	if rr := do(http.MethodDelete, "/users/"+registered.User.ID, registered.Token); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 until the new email is verified got %d", rr.Code)
+	}
+	matches = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`).FindAllStringSubmatch(outbox.String(), -1)
+	if rr := do(http.MethodGet, "/auth/verify?token="+matches[len(matches)-1][1], ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected the new email to verify got %d", rr.Code)
+	}
+
+	if rr := do(http.MethodDelete, "/users/"+registered.User.ID, registered.Token); rr.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 once verified got %d", rr.Code)
+	}
+}
+
+func TestRegisterWithholdsTokensWhenVerificationRequired(t *testing.T) {
+	service := application.NewUserService(memory.NewUserRepository(), application.WithVerificationMode(application.VerificationRequired))
+	handler := transport.NewHandler(service, newSessions(), nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewBufferString(`{"name":"Test","email":"test@example.com","password":"pass12345"}`))
+	rr := httptest.NewRecorder()
+	handler.Register(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201 got %d", rr.Code)
+	}
+	if strings.Contains(rr.Body.String(), `"token"`) {
+		t.Fatalf("expected no tokens before verification got %s", rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBufferString(`{"email":"test@example.com","password":"pass12345"}`))
+	rr = httptest.NewRecorder()
+	handler.Login(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 got %d", rr.Code)
+	}
+}
+
+func TestChangePasswordHandler(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+	repo := memory.NewUserRepository()
+	service := application.NewUserService(repo, application.WithClock(clock))
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer", jwtinfra.WithClock(clock))
+	sessions := application.NewSessionService(manager, memory.NewRefreshTokenRepository(), memory.NewTokenRevocationStore(), time.Hour,
+		application.WithPasswordChangeCheck(repo), application.WithSessionClock(clock))
+	router := transport.NewRouter(transport.NewHandler(service, sessions, nil, nil, nil), sessions, manager)
+
+	do := func(method, path, token, body string) int {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	user, err := service.Register(context.Background(), application.RegisterInput{Name: "Test", Email: "change@example.com", Password: "pass12345"})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	other, err := service.Register(context.Background(), application.RegisterInput{Name: "Other", Email: "other@example.com", Password: "pass12345"})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	pair, err := sessions.Issue(context.Background(), user)
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	now = now.Add(time.Second)
+
+	path := "/users/" + user.ID + "/password"
+	if code := do(http.MethodPost, path, "", `{}`); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token got %d", code)
+	}
+	if code := do(http.MethodPost, "/users/"+other.ID+"/password", pair.AccessToken, `{"currentPassword":"pass12345","newPassword":"newpass123"}`); code != http.StatusForbidden {
+		t.Fatalf("expected 403 for another user got %d", code)
+	}
+	if code := do(http.MethodPost, path, pair.AccessToken, `{"currentPassword":"wrongpass","newPassword":"newpass123"}`); code != http.StatusForbidden {
+		t.Fatalf("expected 403 for wrong current password got %d", code)
+	}
+	if code := do(http.MethodPost, path, pair.AccessToken, `{"currentPassword":"pass12345","newPassword":"short"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for weak password got %d", code)
+	}
+	if code := do(http.MethodPost, path, pair.AccessToken, `{"currentPassword":"pass12345","newPassword":"newpass123"}`); code != http.StatusNoContent {
+		t.Fatalf("expected 204 got %d", code)
+	}
+
+	if code := do(http.MethodGet, "/users/"+user.ID, pair.AccessToken, ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected token issued before the change to be rejected got %d", code)
+	}
+	if code := do(http.MethodPost, "/auth/login", "", `{"email":"change@example.com","password":"newpass123"}`); code != http.StatusOK {
+		t.Fatalf("expected login with new password to succeed got %d", code)
+	}
+}
+
+func TestMFAHandlers(t *testing.T) {
+	repo := memory.NewUserRepository()
+	service := application.NewUserService(repo)
+	manager := jwtinfra.NewManager("secret", time.Hour, "issuer")
+	sessions := application.NewSessionService(manager, memory.NewRefreshTokenRepository(), memory.NewTokenRevocationStore(), time.Hour)
+	mfa := application.NewMFAService(repo, memory.NewOneTimeTokenRepository(), "Backend", time.Minute)
+	router := transport.NewRouter(transport.NewHandler(service, sessions, nil, nil, mfa), sessions, manager)
+
+	do := func(path, token, body string, out any) int {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if out != nil && rr.Code < 300 {
+			if err := json.Unmarshal(rr.Body.Bytes(), out); err != nil {
+				t.Fatalf("parse %s response: %v", path, err)
+			}
+		}
+		return rr.Code
+	}
+
+	var registered struct {
+		Token string `json:"token"`
+	}
+	if code := do("/auth/register", "", `{"name":"Admin","email":"admin@example.com","password":"pass12345"}`, &registered); code != http.StatusCreated {
+		t.Fatalf("expected 201 got %d", code)
+	}
+
+	var enrollment struct {
+		Secret string `json:"secret"`
+		URI    string `json:"uri"`
+	}
+	if code := do("/auth/mfa/enroll", registered.Token, "", &enrollment); code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", code)
+	}
+	if !strings.HasPrefix(enrollment.URI, "otpauth://totp/") {
+		t.Fatalf("unexpected enrollment %+v", enrollment)
+	}
+
+	previous, err := domain.TOTPCode(enrollment.Secret, domain.TOTPStep(time.Now().Add(-domain.TOTPPeriod)))
+	if err != nil {
+		t.Fatalf("totp: %v", err)
+	}
+	var confirmed struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}
+	if code := do("/auth/mfa/confirm", registered.Token, `{"code":"`+previous+`"}`, &confirmed); code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", code)
+	}
+	if len(confirmed.RecoveryCodes) == 0 {
+		t.Fatal("expected recovery codes")
+	}
+
+	var challenge struct {
+		Status         string `json:"status"`
+		ChallengeToken string `json:"challengeToken"`
+		Token          string `json:"token"`
+	}
+	if code := do("/auth/login", "", `{"email":"admin@example.com","password":"pass12345"}`, &challenge); code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", code)
+	}
+	if challenge.Status != "mfa_required" || challenge.ChallengeToken == "" || challenge.Token != "" {
+		t.Fatalf("expected mfa challenge got %+v", challenge)
+	}
+
+	if code := do("/auth/login/mfa", "", `{"challengeToken":"`+challenge.ChallengeToken+`","code":"000000"}`, nil); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for wrong code got %d", code)
+	}
+	do("/auth/login", "", `{"email":"admin@example.com","password":"pass12345"}`, &challenge)
+
+	var session struct {
+		Token string            `json:"token"`
+		User  domain.UserPublic `json:"user"`
+	}
+	body := `{"challengeToken":"` + challenge.ChallengeToken + `","code":"` + confirmed.RecoveryCodes[0] + `"}`
+	if code := do("/auth/login/mfa", "", body, &session); code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", code)
+	}
+	if session.Token == "" || !session.User.MFAEnabled {
+		t.Fatalf("expected signed-in mfa user got %+v", session)
+	}
+}
+
+func TestLoginThrottledReturns429(t *testing.T) {
+	repo := memory.NewUserRepository()
+	throttle := application.NewLoginThrottle(memory.NewLoginAttemptStore(), application.LoginThrottlePolicy{
+		FreeAttempts:   100,
+		IPFreeAttempts: 1,
+		BaseDelay:      90 * time.Second,
+		MaxDelay:       time.Hour,
+		Window:         time.Hour,
+	})
+	service := application.NewUserService(repo, application.WithLoginThrottle(throttle))
+	sessions := newSessions()
+	router := transport.NewRouter(transport.NewHandler(service, sessions, nil, nil, nil), sessions, jwtinfra.NewManager("secret", time.Hour, "issuer"))
+
+	login := func(ip, email string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBufferString(`{"email":"`+email+`","password":"wrong-password"}`))
+		req.Header.Set("X-Forwarded-For", ip)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		if rr := login("203.0.113.7", email); rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401 got %d", rr.Code)
+		}
+	}
+
+	rr := login("203.0.113.7", "c@example.com")
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 got %d", rr.Code)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "90" {
+		t.Fatalf("expected Retry-After 90 got %q", got)
+	}
+
+	if rr := login("198.51.100.1", "c@example.com"); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected other clients to be unaffected got %d", rr.Code)
+	}
+}
+
+func TestListUsersRequiresAdmin(t *testing.T) {
+	repo := &fakeRepo{
+		listFn: func(context.Context, domain.UserListQuery) (domain.UserPage, error) {
+			return domain.UserPage{Users: []domain.User{{ID: "1", Name: "A", Email: "a@example.com"}}}, nil
+		},
+	}
+	handler := transport.NewHandler(application.NewUserService(repo), newSessions(), nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req = req.WithContext(authctx.WithClaims(req.Context(), application.AccessClaims{UserID: "1", Roles: []domain.Role{domain.RoleUser}}))
+	rr := httptest.NewRecorder()
+	handler.ListUsers(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for regular user got %d", rr.Code)
+	}
+}
+
+func TestAdminCanUpdateAndDeleteOthers(t *testing.T) {
+	var updated, deleted string
+	repo := &fakeRepo{
+		updateFn: func(_ context.Context, id string, _ domain.UpdateUser) (domain.User, error) {
+			updated = id
+			return domain.User{ID: id, Name: "Updated"}, nil
+		},
+		deleteFn: func(_ context.Context, id string) error {
+			deleted = id
+			return nil
+		},
+	}
+	handler := transport.NewHandler(application.NewUserService(repo), newSessions(), nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPatch, "/users/1", bytes.NewBufferString(`{"name":"Updated"}`))
+	req = withRouteParam(req, "id", "1")
+	req = req.WithContext(withAdmin(req.Context()))
+	rr := httptest.NewRecorder()
+	handler.UpdateUser(rr, req)
+	if rr.Code != http.StatusOK || updated != "1" {
+		t.Fatalf("expected admin update to succeed got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/users/1", nil)
+	req = withRouteParam(req, "id", "1")
+	req = req.WithContext(withAdmin(req.Context()))
+	rr = httptest.NewRecorder()
+	handler.DeleteUser(rr, req)
+	if rr.Code != http.StatusNoContent || deleted != "1" {
+		t.Fatalf("expected admin delete to succeed got %d", rr.Code)
+	}
+}
+
+func TestRestoreUserHandler(t *testing.T) {
+	repo := &fakeRepo{
+		restoreFn: func(_ context.Context, id string) (domain.User, error) {
+			switch id {
+			case "taken":
+				return domain.User{}, application.ErrDuplicateEmail
+			case "active":
+				return domain.User{}, application.ErrNotFound
+			}
+			return domain.User{ID: id, Name: "A", Email: "a@example.com", Password: "hash"}, nil
+		},
+	}
+	handler := transport.NewHandler(application.NewUserService(repo), newSessions(), nil, nil, nil)
+
+	restore := func(id string, ctx func(context.Context) context.Context) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/users/"+id+"/restore", nil)
+		req = withRouteParam(req, "id", id)
+		req = req.WithContext(ctx(req.Context()))
+		rr := httptest.NewRecorder()
+		handler.RestoreUser(rr, req)
+		return rr
+	}
+
+	rr := restore("1", withAdmin)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d %s", rr.Code, rr.Body.String())
+	}
+	var body map[string]any
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body["id"] != "1" || body["password"] != nil || body["deletedAt"] != nil {
+		t.Fatalf("expected the restored public user got %v", body)
+	}
+
+	asOwner := func(ctx context.Context) context.Context {
+		return authctx.WithClaims(ctx, application.AccessClaims{UserID: "1", Roles: []domain.Role{domain.RoleUser}})
+	}
+	if rr := restore("1", asOwner); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a regular user got %d", rr.Code)
+	}
+	if rr := restore("taken", withAdmin); rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409 when the email was reused got %d", rr.Code)
+	}
+	if rr := restore("active", withAdmin); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a user that is not deleted got %d", rr.Code)
+	}
+}
+
+func TestGetUserForbiddenForOtherUsers(t *testing.T) {
+	repo := &fakeRepo{
+		getByID: func(_ context.Context, id string) (domain.User, error) {
+			return domain.User{ID: id, Name: "A", Email: "a@example.com"}, nil
+		},
+	}
+	handler := transport.NewHandler(application.NewUserService(repo), newSessions(), nil, nil, nil)
+
+	get := func(ctx context.Context) int {
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		req = withRouteParam(req, "id", "1")
+		rr := httptest.NewRecorder()
+		handler.GetUser(rr, req.WithContext(ctx))
+		return rr.Code
+	}
+
+	if code := get(authctx.WithUserID(context.Background(), "other")); code != http.StatusForbidden {
+		t.Fatalf("expected 403 got %d", code)
+	}
+	if code := get(withAdmin(context.Background())); code != http.StatusOK {
+		t.Fatalf("expected admin to read any profile got %d", code)
+	}
+}
@@ -0,0 +1,15 @@
+package http
+
+import (
+	stdhttp "net/http"
+
+	jwtinfra "backend-challenge/internal/infrastructure/jwt"
+)
+
+// JWKSHandler serves the public signing keys as a JSON Web Key Set.
+func JWKSHandler(manager *jwtinfra.Manager) stdhttp.HandlerFunc {
+	return func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		writeJSON(w, stdhttp.StatusOK, manager.JWKS())
+	}
+}
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+)
+
+// Codes for query parameters that are not covered by the domain validation
+// codes.
+const (
+	codeUnknownParameter = "unknown_parameter"
+	codeUnknownField     = "unknown_field"
+)
+
+// listParams are the query parameters GET /users understands. Any other
+// parameter is rejected so that misspelt filters do not silently match
+// every user.
+var listParams = map[string]bool{
+	"limit":           true,
+	"cursor":          true,
+	"q":               true,
+	"created_after":   true,
+	"created_before":  true,
+	"sort":            true,
+	"fields":          true,
+	"include_deleted": true,
+}
+
+// parseListQuery reads the filter, sort, page, and fields of GET /users. It reports
+// every invalid parameter rather than only the first.
+func parseListQuery(query url.Values) (application.ListInput, []fieldError) {
+	var (
+		input   = application.ListInput{Page: application.PageRequest{Cursor: query.Get("cursor")}}
+		invalid []fieldError
+	)
+
+	unknown := make([]string, 0)
+	for name := range query {
+		if !listParams[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		invalid = append(invalid, fieldError{Field: name, Code: codeUnknownParameter, Message: name + " is not a supported query parameter"})
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			invalid = append(invalid, fieldError{Field: "limit", Code: domain.CodeInvalidFormat, Message: "limit must be an integer"})
+		}
+		input.Page.Limit = limit
+	}
+	input.Filter.Search = strings.TrimSpace(query.Get("q"))
+	for _, bound := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"created_after", &input.Filter.CreatedAfter},
+		{"created_before", &input.Filter.CreatedBefore},
+	} {
+		name, raw := bound.name, query.Get(bound.name)
+		if raw == "" {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			invalid = append(invalid, fieldError{Field: name, Code: domain.CodeInvalidFormat, Message: name + " must be an RFC 3339 timestamp"})
+			continue
+		}
+		*bound.dst = &at
+	}
+	if raw := query.Get("include_deleted"); raw != "" {
+		include, err := strconv.ParseBool(raw)
+		if err != nil {
+			invalid = append(invalid, fieldError{Field: "include_deleted", Code: domain.CodeInvalidFormat, Message: "include_deleted must be true or false"})
+		}
+		input.Filter.IncludeDeleted = include
+	}
+	if raw := query.Get("sort"); raw != "" {
+		order, err := domain.ParseUserSort(raw)
+		if err != nil {
+			invalid = append(invalid, fieldError{Field: "sort", Code: codeUnknownField, Message: "sort must be createdAt, name, or email, optionally prefixed with -"})
+		}
+		input.Sort = order
+	}
+	fields, fieldErrs := parseFields(query.Get("fields"))
+	input.Fields = fields
+	invalid = append(invalid, fieldErrs...)
+	return input, invalid
+}
+
+// parseFields reads a comma-separated list of user fields, as in
+// ?fields=id,name. An empty list selects every field.
+func parseFields(raw string) ([]domain.UserField, []fieldError) {
+	var (
+		fields  []domain.UserField
+		invalid []fieldError
+	)
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		field, err := domain.ParseUserField(name)
+		if err != nil {
+			invalid = append(invalid, fieldError{Field: "fields", Code: codeUnknownField, Message: name + " is not a user field"})
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields, invalid
+}
+
+// publicUser is the response body for user, restricted to fields when any
+// are given.
+func publicUser(user domain.User, fields []domain.UserField) any {
+	if len(fields) == 0 {
+		return user.Sanitize()
+	}
+	return user.Sanitize().Select(fields)
+}
@@ -1,12 +1,13 @@
 package http
 
 import (
+	"errors"
 	"log"
 	stdhttp "net/http"
 	"strings"
 	"time"
 
-	jwtinfra "backend-challenge/internal/infrastructure/jwt"
+	"backend-challenge/internal/application"
 	"backend-challenge/internal/transport/authctx"
 )
 
@@ -21,29 +22,33 @@ func LoggingMiddleware(next stdhttp.Handler) stdhttp.Handler {
 	})
 }
 
-// AuthMiddleware ensures requests have a valid JWT.
-func AuthMiddleware(manager *jwtinfra.Manager) func(stdhttp.Handler) stdhttp.Handler {
+// AuthMiddleware ensures requests have a valid, unrevoked JWT.
+func AuthMiddleware(sessions *application.SessionService) func(stdhttp.Handler) stdhttp.Handler {
 	return func(next stdhttp.Handler) stdhttp.Handler {
 		return stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				stdhttp.Error(w, "missing authorization header", stdhttp.StatusUnauthorized)
+				writeProblem(w, r, problemUnauthorized, "missing authorization header")
 				return
 			}
 
 			parts := strings.SplitN(authHeader, " ", 2)
 			if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-				stdhttp.Error(w, "invalid authorization header", stdhttp.StatusUnauthorized)
+				writeProblem(w, r, problemUnauthorized, "invalid authorization header")
 				return
 			}
 
-			userID, err := manager.ValidateToken(parts[1])
+			claims, err := sessions.Verify(r.Context(), parts[1])
 			if err != nil {
-				stdhttp.Error(w, "invalid token", stdhttp.StatusUnauthorized)
+				if errors.Is(err, application.ErrInvalidToken) || errors.Is(err, application.ErrTokenRevoked) {
+					writeProblem(w, r, problemUnauthorized, "invalid token")
+					return
+				}
+				writeInternalError(w, r, err)
 				return
 			}
 
-			ctx := authctx.WithUserID(r.Context(), userID)
+			ctx := authctx.WithClaims(r.Context(), claims)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
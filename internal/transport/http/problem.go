@@ -0,0 +1,170 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"backend-challenge/internal/application"
+	"backend-challenge/internal/domain"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// problemContentType is the media type of RFC 7807 error bodies.
+const problemContentType = "application/problem+json"
+
+// problemTypeBase prefixes every problem type. Types are relative URIs that
+// identify the kind of error; clients should match on them or on code, never
+// on title or detail.
+const problemTypeBase = "/problems/"
+
+// problemKind is one kind of error the API reports.
+type problemKind struct {
+	slug   string
+	title  string
+	status int
+	code   string
+}
+
+var (
+	problemInvalidPayload     = problemKind{"invalid-payload", "Invalid payload", http.StatusBadRequest, "INVALID_PAYLOAD"}
+	problemValidation         = problemKind{"validation", "Validation failed", http.StatusBadRequest, "VALIDATION_FAILED"}
+	problemInvalidQuery       = problemKind{"invalid-query", "Invalid query parameter", http.StatusBadRequest, "INVALID_QUERY"}
+	problemInvalidToken       = problemKind{"invalid-token", "Invalid or expired token", http.StatusBadRequest, "INVALID_TOKEN"}
+	problemNoFields           = problemKind{"no-fields-to-update", "No fields to update", http.StatusBadRequest, "NO_FIELDS_TO_UPDATE"}
+	problemUnauthorized       = problemKind{"unauthorized", "Unauthorized", http.StatusUnauthorized, "UNAUTHORIZED"}
+	problemInvalidCredentials = problemKind{"invalid-credentials", "Invalid credentials", http.StatusUnauthorized, "INVALID_CREDENTIALS"}
+	problemForbidden          = problemKind{"forbidden", "Forbidden", http.StatusForbidden, "FORBIDDEN"}
+	problemEmailNotVerified   = problemKind{"email-not-verified", "Email not verified", http.StatusForbidden, "EMAIL_NOT_VERIFIED"}
+	problemWrongPassword      = problemKind{"current-password-mismatch", "Current password is incorrect", http.StatusForbidden, "CURRENT_PASSWORD_MISMATCH"}
+	problemNotFound           = problemKind{"not-found", "Not found", http.StatusNotFound, "NOT_FOUND"}
+	problemMethodNotAllowed   = problemKind{"method-not-allowed", "Method not allowed", http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED"}
+	problemDuplicateEmail     = problemKind{"duplicate-email", "Email already in use", http.StatusConflict, "DUPLICATE_EMAIL"}
+	problemMFAState           = problemKind{"mfa-state", "Two-factor authentication is in the wrong state", http.StatusConflict, "MFA_STATE"}
+	problemPreconditionFailed = problemKind{"precondition-failed", "Precondition failed", http.StatusPreconditionFailed, "PRECONDITION_FAILED"}
+	problemTooManyRequests    = problemKind{"too-many-requests", "Too many requests", http.StatusTooManyRequests, "TOO_MANY_REQUESTS"}
+	problemInternal           = problemKind{"internal", "Internal server error", http.StatusInternalServerError, "INTERNAL"}
+)
+
+// internalDetail replaces the message of unexpected errors, which may carry
+// database or other internal details.
+const internalDetail = "an unexpected error occurred"
+
+// problem is an RFC 7807 problem details body.
+type problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []fieldError `json:"errors,omitempty"`
+}
+
+// fieldError describes one invalid request field.
+type fieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// writeProblem sends a problem of the given kind.
+func writeProblem(w http.ResponseWriter, r *http.Request, kind problemKind, detail string, fields ...fieldError) {
+	w.Header().Set("Content-Type", problemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(kind.status)
+	_ = json.NewEncoder(w).Encode(problem{
+		Type:      problemTypeBase + kind.slug,
+		Title:     kind.title,
+		Status:    kind.status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      kind.code,
+		RequestID: middleware.GetReqID(r.Context()),
+		Errors:    fields,
+	})
+}
+
+// writeInternalError logs err with the request ID and sends an opaque 500.
+func writeInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	log.Printf("request %s: %s %s: %v", middleware.GetReqID(r.Context()), r.Method, r.URL.Path, err)
+	writeProblem(w, r, problemInternal, internalDetail)
+}
+
+// handleError maps application and domain errors to problems. Errors it does
+// not recognise are logged and reported as internal errors.
+func handleError(w http.ResponseWriter, r *http.Request, err error) {
+	var (
+		throttled *application.ThrottleError
+		invalid   domain.ValidationErrors
+	)
+	switch {
+	case errors.As(err, &invalid):
+		writeProblem(w, r, problemValidation, "one or more fields are invalid", invalidFields(invalid)...)
+	case errors.As(err, &throttled):
+		seconds := int(math.Ceil(throttled.RetryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		writeProblem(w, r, problemTooManyRequests, err.Error())
+	case errors.Is(err, application.ErrDuplicateEmail):
+		writeProblem(w, r, problemDuplicateEmail, err.Error())
+	case errors.Is(err, application.ErrInvalidCredentials):
+		writeProblem(w, r, problemInvalidCredentials, err.Error())
+	case errors.Is(err, application.ErrInvalidMFACode),
+		errors.Is(err, application.ErrInvalidMFAChallenge),
+		errors.Is(err, application.ErrInvalidRefreshToken),
+		errors.Is(err, application.ErrRefreshTokenReused),
+		errors.Is(err, application.ErrInvalidToken),
+		errors.Is(err, application.ErrTokenRevoked):
+		writeProblem(w, r, problemUnauthorized, err.Error())
+	case errors.Is(err, application.ErrMFAAlreadyEnabled),
+		errors.Is(err, application.ErrMFANotEnrolled):
+		writeProblem(w, r, problemMFAState, err.Error())
+	case errors.Is(err, application.ErrEmailNotVerified):
+		writeProblem(w, r, problemEmailNotVerified, err.Error())
+	case errors.Is(err, application.ErrCurrentPasswordMismatch):
+		writeProblem(w, r, problemWrongPassword, err.Error())
+	case errors.Is(err, application.ErrForbidden):
+		writeProblem(w, r, problemForbidden, err.Error())
+	case errors.Is(err, application.ErrNotFound):
+		writeProblem(w, r, problemNotFound, err.Error())
+	case errors.Is(err, application.ErrNoFieldsToUpdate):
+		writeProblem(w, r, problemNoFields, err.Error())
+	case errors.Is(err, application.ErrVersionMismatch):
+		writeProblem(w, r, problemPreconditionFailed, err.Error())
+	case errors.Is(err, application.ErrInvalidResetToken),
+		errors.Is(err, application.ErrInvalidVerificationToken):
+		writeProblem(w, r, problemInvalidToken, err.Error())
+	case errors.Is(err, application.ErrInvalidCursor),
+		errors.Is(err, application.ErrInvalidPageSize):
+		writeProblem(w, r, problemInvalidQuery, err.Error())
+	case errors.Is(err, domain.ErrInvalidEmail),
+		errors.Is(err, domain.ErrInvalidName),
+		errors.Is(err, domain.ErrInvalidPassword):
+		writeProblem(w, r, problemValidation, err.Error())
+	default:
+		writeInternalError(w, r, err)
+	}
+}
+
+func invalidFields(invalid domain.ValidationErrors) []fieldError {
+	fields := make([]fieldError, 0, len(invalid))
+	for _, fe := range invalid {
+		fields = append(fields, fieldError{Field: fe.Field, Code: fe.Code, Message: fe.Message})
+	}
+	return fields
+}
+
+// notFound answers requests for routes that do not exist.
+func notFound(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, problemNotFound, "no route for "+r.URL.Path)
+}
+
+// methodNotAllowed answers requests with a method the route does not serve.
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, problemMethodNotAllowed, r.Method+" is not allowed on "+r.URL.Path)
+}
@@ -3,6 +3,7 @@ package http
 import (
 	stdhttp "net/http"
 
+	"backend-challenge/internal/application"
 	jwtinfra "backend-challenge/internal/infrastructure/jwt"
 
 	"github.com/go-chi/chi/v5"
@@ -10,23 +11,41 @@ import (
 )
 
 // NewRouter wires routes and middleware.
-func NewRouter(handler *Handler, jwtManager *jwtinfra.Manager) stdhttp.Handler {
+func NewRouter(handler *Handler, sessions *application.SessionService, jwtManager *jwtinfra.Manager) stdhttp.Handler {
 	r := chi.NewRouter()
 
 	r.Use(middleware.RequestID)
+	// RealIP trusts X-Forwarded-For and X-Real-IP, so the server must sit
+	// behind a proxy that sets them; login throttling keys on the result.
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Recoverer)
 	r.Use(LoggingMiddleware)
+	r.NotFound(notFound)
+	r.MethodNotAllowed(methodNotAllowed)
 
+	r.Get("/.well-known/jwks.json", JWKSHandler(jwtManager))
 	r.Post("/auth/register", handler.Register)
 	r.Post("/auth/login", handler.Login)
+	r.Post("/auth/login/mfa", handler.LoginMFA)
+	r.Post("/auth/refresh", handler.Refresh)
+	r.Post("/auth/password/forgot", handler.ForgotPassword)
+	r.Post("/auth/password/reset", handler.ResetPassword)
+	r.Get("/auth/verify", handler.VerifyEmail)
 
 	r.Group(func(group chi.Router) {
-		group.Use(AuthMiddleware(jwtManager))
+		group.Use(AuthMiddleware(sessions))
+		group.Post("/auth/logout", handler.Logout)
+		group.Post("/auth/logout-all", handler.LogoutAll)
+		group.Post("/auth/verify/resend", handler.ResendVerification)
+		group.Post("/auth/mfa/enroll", handler.EnrollMFA)
+		group.Post("/auth/mfa/confirm", handler.ConfirmMFA)
+		group.Post("/auth/mfa/disable", handler.DisableMFA)
 		group.Get("/users", handler.ListUsers)
 		group.Get("/users/{id}", handler.GetUser)
 		group.Patch("/users/{id}", handler.UpdateUser)
 		group.Delete("/users/{id}", handler.DeleteUser)
+		group.Post("/users/{id}/restore", handler.RestoreUser)
+		group.Post("/users/{id}/password", handler.ChangePassword)
 	})
 
 	return r
@@ -6,10 +6,12 @@ import (
 	reflect "reflect"
 	"sync"
 
+	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/runtime/protoimpl"
 	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 const (
@@ -18,16 +20,139 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// AuthPolicy declares who may call an RPC. The gRPC server refuses methods
+// that do not declare one.
+type AuthPolicy int32
+
+const (
+	AuthPolicy_AUTH_POLICY_UNSPECIFIED AuthPolicy = 0
+	// Callable without a token.
+	AuthPolicy_AUTH_POLICY_PUBLIC AuthPolicy = 1
+	// Requires a valid access token.
+	AuthPolicy_AUTH_POLICY_AUTHENTICATED AuthPolicy = 2
+	// Requires a token for the user named by the request's id field.
+	AuthPolicy_AUTH_POLICY_SELF AuthPolicy = 3
+	// Requires a token carrying the admin role.
+	AuthPolicy_AUTH_POLICY_ADMIN AuthPolicy = 4
+)
+
+// Enum value maps for AuthPolicy.
+var (
+	AuthPolicy_name = map[int32]string{
+		0: "AUTH_POLICY_UNSPECIFIED",
+		1: "AUTH_POLICY_PUBLIC",
+		2: "AUTH_POLICY_AUTHENTICATED",
+		3: "AUTH_POLICY_SELF",
+		4: "AUTH_POLICY_ADMIN",
+	}
+	AuthPolicy_value = map[string]int32{
+		"AUTH_POLICY_UNSPECIFIED":   0,
+		"AUTH_POLICY_PUBLIC":        1,
+		"AUTH_POLICY_AUTHENTICATED": 2,
+		"AUTH_POLICY_SELF":          3,
+		"AUTH_POLICY_ADMIN":         4,
+	}
+)
+
+func (x AuthPolicy) Enum() *AuthPolicy {
+	p := new(AuthPolicy)
+	*p = x
+	return p
+}
+
+func (x AuthPolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AuthPolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_user_proto_enumTypes[0].Descriptor()
+}
+
+func (AuthPolicy) Type() protoreflect.EnumType {
+	return &file_proto_user_proto_enumTypes[0]
+}
+
+func (x AuthPolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AuthPolicy.Descriptor instead.
+func (AuthPolicy) EnumDescriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{0}
+}
+
+// UserEventType says what happened to a user.
+type UserEventType int32
+
+const (
+	UserEventType_USER_EVENT_TYPE_UNSPECIFIED UserEventType = 0
+	UserEventType_USER_EVENT_TYPE_CREATED     UserEventType = 1
+	UserEventType_USER_EVENT_TYPE_UPDATED     UserEventType = 2
+	UserEventType_USER_EVENT_TYPE_DELETED     UserEventType = 3
+)
+
+// Enum value maps for UserEventType.
+var (
+	UserEventType_name = map[int32]string{
+		0: "USER_EVENT_TYPE_UNSPECIFIED",
+		1: "USER_EVENT_TYPE_CREATED",
+		2: "USER_EVENT_TYPE_UPDATED",
+		3: "USER_EVENT_TYPE_DELETED",
+	}
+	UserEventType_value = map[string]int32{
+		"USER_EVENT_TYPE_UNSPECIFIED": 0,
+		"USER_EVENT_TYPE_CREATED":     1,
+		"USER_EVENT_TYPE_UPDATED":     2,
+		"USER_EVENT_TYPE_DELETED":     3,
+	}
+)
+
+func (x UserEventType) Enum() *UserEventType {
+	p := new(UserEventType)
+	*p = x
+	return p
+}
+
+func (x UserEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UserEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_user_proto_enumTypes[1].Descriptor()
+}
+
+func (UserEventType) Type() protoreflect.EnumType {
+	return &file_proto_user_proto_enumTypes[1]
+}
+
+func (x UserEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UserEventType.Descriptor instead.
+func (UserEventType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{1}
+}
+
 // User represents a user projection used in responses.
 type User struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id        string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name      string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Email     string `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
-	CreatedAt string `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Id            string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Email         string   `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	CreatedAt     string   `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	EmailVerified bool     `protobuf:"varint,5,opt,name=email_verified,json=emailVerified,proto3" json:"email_verified,omitempty"`
+	MfaEnabled    bool     `protobuf:"varint,6,opt,name=mfa_enabled,json=mfaEnabled,proto3" json:"mfa_enabled,omitempty"`
+	Roles         []string `protobuf:"bytes,7,rep,name=roles,proto3" json:"roles,omitempty"`
+	// deleted_at is set for deleted users, which only admins see by listing with
+	// include_deleted.
+	DeletedAt string `protobuf:"bytes,8,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	// etag changes whenever the user does. Send it back in
+	// UpdateUserRequest.etag to update only the version that was read.
+	Etag string `protobuf:"bytes,9,opt,name=etag,proto3" json:"etag,omitempty"`
 }
 
 func (x *User) Reset() {
@@ -89,6 +214,41 @@ func (x *User) GetCreatedAt() string {
 	return ""
 }
 
+func (x *User) GetEmailVerified() bool {
+	if x != nil {
+		return x.EmailVerified
+	}
+	return false
+}
+
+func (x *User) GetMfaEnabled() bool {
+	if x != nil {
+		return x.MfaEnabled
+	}
+	return false
+}
+
+func (x *User) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *User) GetDeletedAt() string {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return ""
+}
+
+func (x *User) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
 // CreateUserRequest contains fields required to create a new user.
 type CreateUserRequest struct {
 	state         protoimpl.MessageState
@@ -152,14 +312,16 @@ func (x *CreateUserRequest) GetPassword() string {
 	return ""
 }
 
-// CreateUserResponse wraps the created user and issued token.
+// CreateUserResponse wraps the created user and issued tokens. The tokens
+// are empty when the server requires a verified email before signing in.
 type CreateUserResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	User  *User  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
-	Token string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	User         *User  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Token        string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	RefreshToken string `protobuf:"bytes,3,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
 }
 
 func (x *CreateUserResponse) Reset() {
@@ -207,6 +369,157 @@ func (x *CreateUserResponse) GetToken() string {
 	return ""
 }
 
+func (x *CreateUserResponse) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+// LoginRequest signs a user in with their email and password.
+type LoginRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Email    string `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Password string `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+}
+
+func (x *LoginRequest) Reset() {
+	*x = LoginRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginRequest) ProtoMessage() {}
+
+func (x *LoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*LoginRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *LoginRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+// LoginResponse wraps the signed-in user and issued tokens. Accounts with
+// two-factor authentication get mfa_required and a challenge for LoginMFA
+// instead.
+type LoginResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	User               *User  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Token              string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	MfaRequired        bool   `protobuf:"varint,3,opt,name=mfa_required,json=mfaRequired,proto3" json:"mfa_required,omitempty"`
+	ChallengeToken     string `protobuf:"bytes,4,opt,name=challenge_token,json=challengeToken,proto3" json:"challenge_token,omitempty"`
+	ChallengeExpiresAt string `protobuf:"bytes,5,opt,name=challenge_expires_at,json=challengeExpiresAt,proto3" json:"challenge_expires_at,omitempty"`
+	RefreshToken       string `protobuf:"bytes,6,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+}
+
+func (x *LoginResponse) Reset() {
+	*x = LoginResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginResponse) ProtoMessage() {}
+
+func (x *LoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*LoginResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *LoginResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *LoginResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *LoginResponse) GetMfaRequired() bool {
+	if x != nil {
+		return x.MfaRequired
+	}
+	return false
+}
+
+func (x *LoginResponse) GetChallengeToken() string {
+	if x != nil {
+		return x.ChallengeToken
+	}
+	return ""
+}
+
+func (x *LoginResponse) GetChallengeExpiresAt() string {
+	if x != nil {
+		return x.ChallengeExpiresAt
+	}
+	return ""
+}
+
+func (x *LoginResponse) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
 // GetUserRequest fetches a user by ID.
 type GetUserRequest struct {
 	state         protoimpl.MessageState
@@ -214,12 +527,15 @@ type GetUserRequest struct {
 	unknownFields protoimpl.UnknownFields
 
 	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// read_mask, when set, limits the returned user to these fields. The
+	// others are left unset.
+	ReadMask *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=read_mask,json=readMask,proto3" json:"read_mask,omitempty"`
 }
 
 func (x *GetUserRequest) Reset() {
 	*x = GetUserRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_user_proto_msgTypes[3]
+		mi := &file_proto_user_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -232,7 +548,7 @@ func (x *GetUserRequest) String() string {
 func (*GetUserRequest) ProtoMessage() {}
 
 func (x *GetUserRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_user_proto_msgTypes[3]
+	mi := &file_proto_user_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -244,7 +560,7 @@ func (x *GetUserRequest) ProtoReflect() protoreflect.Message {
 }
 
 func (*GetUserRequest) Descriptor() ([]byte, []int) {
-	return file_proto_user_proto_rawDescGZIP(), []int{3}
+	return file_proto_user_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *GetUserRequest) GetId() string {
@@ -254,6 +570,13 @@ func (x *GetUserRequest) GetId() string {
 	return ""
 }
 
+func (x *GetUserRequest) GetReadMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.ReadMask
+	}
+	return nil
+}
+
 // GetUserResponse contains a user projection.
 type GetUserResponse struct {
 	state         protoimpl.MessageState
@@ -266,7 +589,7 @@ type GetUserResponse struct {
 func (x *GetUserResponse) Reset() {
 	*x = GetUserResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_user_proto_msgTypes[4]
+		mi := &file_proto_user_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -279,7 +602,7 @@ func (x *GetUserResponse) String() string {
 func (*GetUserResponse) ProtoMessage() {}
 
 func (x *GetUserResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_user_proto_msgTypes[4]
+	mi := &file_proto_user_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -291,7 +614,7 @@ func (x *GetUserResponse) ProtoReflect() protoreflect.Message {
 }
 
 func (*GetUserResponse) Descriptor() ([]byte, []int) {
-	return file_proto_user_proto_rawDescGZIP(), []int{4}
+	return file_proto_user_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *GetUserResponse) GetUser() *User {
@@ -301,164 +624,2485 @@ func (x *GetUserResponse) GetUser() *User {
 	return nil
 }
 
-var File_proto_user_proto protoreflect.FileDescriptor
+// ListUsersRequest lists one page of users, newest first. Only admins may
+// call it.
+type ListUsersRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-var file_proto_user_proto_rawDescOnce sync.Once
-var file_proto_user_proto_rawDescData = buildProtoUserRawDesc()
+	// page_size is the most users to return. Zero means 50; larger values are
+	// capped at 100.
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// page_token is the next_page_token of the previous page, or empty for the
+	// first page.
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// read_mask, when set, limits the returned users to these fields, and only
+	// these are read from the database. The others are left unset.
+	ReadMask *fieldmaskpb.FieldMask `protobuf:"bytes,3,opt,name=read_mask,json=readMask,proto3" json:"read_mask,omitempty"`
+	// include_deleted also lists deleted users that have not been purged yet.
+	IncludeDeleted bool `protobuf:"varint,4,opt,name=include_deleted,json=includeDeleted,proto3" json:"include_deleted,omitempty"`
+}
 
-func file_proto_user_proto_rawDescGZIP() []byte {
-	file_proto_user_proto_rawDescOnce.Do(func() {
-		file_proto_user_proto_rawDescData = protoimpl.X.CompressGZIP(file_proto_user_proto_rawDescData)
-	})
-	return file_proto_user_proto_rawDescData
+func (x *ListUsersRequest) Reset() {
+	*x = ListUsersRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
 
-var file_proto_user_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
-var file_proto_user_proto_goTypes = []interface{}{
-	(*User)(nil),               // 0: user.v1.User
-	(*CreateUserRequest)(nil),  // 1: user.v1.CreateUserRequest
-	(*CreateUserResponse)(nil), // 2: user.v1.CreateUserResponse
-	(*GetUserRequest)(nil),     // 3: user.v1.GetUserRequest
-	(*GetUserResponse)(nil),    // 4: user.v1.GetUserResponse
+func (x *ListUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
-var file_proto_user_proto_depIdxs = []int32{
-	0, // 0: user.v1.CreateUserResponse.user:type_name -> user.v1.User
-	0, // 1: user.v1.GetUserResponse.user:type_name -> user.v1.User
-	1, // 2: user.v1.UserService.CreateUser:input_type -> user.v1.CreateUserRequest
-	2, // 3: user.v1.UserService.CreateUser:output_type -> user.v1.CreateUserResponse
-	3, // 4: user.v1.UserService.GetUser:input_type -> user.v1.GetUserRequest
-	4, // 5: user.v1.UserService.GetUser:output_type -> user.v1.GetUserResponse
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+
+func (*ListUsersRequest) ProtoMessage() {}
+
+func (x *ListUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func init() { file_proto_user_proto_init() }
+func (*ListUsersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{7}
+}
 
-func file_proto_user_proto_init() {
-	if File_proto_user_proto != nil {
-		return
+func (x *ListUsersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_proto_user_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*User); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_proto_user_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateUserRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_proto_user_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateUserResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+	return 0
+}
+
+func (x *ListUsersRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListUsersRequest) GetReadMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.ReadMask
+	}
+	return nil
+}
+
+func (x *ListUsersRequest) GetIncludeDeleted() bool {
+	if x != nil {
+		return x.IncludeDeleted
+	}
+	return false
+}
+
+// ListUsersResponse contains one page of user projections.
+type ListUsersResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Users []*User `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	// next_page_token fetches the following page. It is empty on the last
+	// page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+}
+
+func (x *ListUsersResponse) Reset() {
+	*x = ListUsersResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsersResponse) ProtoMessage() {}
+
+func (x *ListUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_proto_user_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetUserRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*ListUsersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListUsersResponse) GetUsers() []*User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *ListUsersResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// UpdateUserRequest changes the fields of user named in update_mask, either
+// "name" or "email". Without a mask, every non-empty field is applied.
+type UpdateUserRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	User       *User                  `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	UpdateMask *fieldmaskpb.FieldMask `protobuf:"bytes,3,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	// etag, when set, must equal the user's current etag, or the update fails
+	// with ABORTED.
+	Etag string `protobuf:"bytes,4,opt,name=etag,proto3" json:"etag,omitempty"`
+}
+
+func (x *UpdateUserRequest) Reset() {
+	*x = UpdateUserRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateUserRequest) ProtoMessage() {}
+
+func (x *UpdateUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_proto_user_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetUserResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*UpdateUserRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateUserRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateUserRequest) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *UpdateUserRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+func (x *UpdateUserRequest) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+// UpdateUserResponse contains the updated user.
+type UpdateUserResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	User *User `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+}
+
+func (x *UpdateUserResponse) Reset() {
+	*x = UpdateUserResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateUserResponse) ProtoMessage() {}
+
+func (x *UpdateUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*UpdateUserResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UpdateUserResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+// DeleteUserRequest deletes a user by ID. The user can be restored until it
+// is purged.
+type DeleteUserRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *DeleteUserRequest) Reset() {
+	*x = DeleteUserRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUserRequest) ProtoMessage() {}
+
+func (x *DeleteUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*DeleteUserRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DeleteUserRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// DeleteUserResponse is returned once the user has been deleted.
+type DeleteUserResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DeleteUserResponse) Reset() {
+	*x = DeleteUserResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUserResponse) ProtoMessage() {}
+
+func (x *DeleteUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*DeleteUserResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{12}
+}
+
+// RestoreUserRequest brings back a deleted user that has not been purged yet.
+// Only admins may call it.
+type RestoreUserRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *RestoreUserRequest) Reset() {
+	*x = RestoreUserRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RestoreUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreUserRequest) ProtoMessage() {}
+
+func (x *RestoreUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*RestoreUserRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RestoreUserRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// RestoreUserResponse contains the restored user.
+type RestoreUserResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	User *User `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+}
+
+func (x *RestoreUserResponse) Reset() {
+	*x = RestoreUserResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RestoreUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreUserResponse) ProtoMessage() {}
+
+func (x *RestoreUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*RestoreUserResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RestoreUserResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+// ForgotPasswordRequest asks for a reset token to be emailed.
+type ForgotPasswordRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Email string `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+}
+
+func (x *ForgotPasswordRequest) Reset() {
+	*x = ForgotPasswordRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ForgotPasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForgotPasswordRequest) ProtoMessage() {}
+
+func (x *ForgotPasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*ForgotPasswordRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ForgotPasswordRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+// ForgotPasswordResponse is empty so it never reveals whether an account exists.
+type ForgotPasswordResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ForgotPasswordResponse) Reset() {
+	*x = ForgotPasswordResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ForgotPasswordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForgotPasswordResponse) ProtoMessage() {}
+
+func (x *ForgotPasswordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*ForgotPasswordResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{16}
+}
+
+// ResetPasswordRequest redeems a reset token and sets a new password.
+type ResetPasswordRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Token    string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Password string `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+}
+
+func (x *ResetPasswordRequest) Reset() {
+	*x = ResetPasswordRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResetPasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetPasswordRequest) ProtoMessage() {}
+
+func (x *ResetPasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*ResetPasswordRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ResetPasswordRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *ResetPasswordRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+// ResetPasswordResponse is returned once the password has been changed.
+type ResetPasswordResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ResetPasswordResponse) Reset() {
+	*x = ResetPasswordResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResetPasswordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetPasswordResponse) ProtoMessage() {}
+
+func (x *ResetPasswordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*ResetPasswordResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{18}
+}
+
+// VerifyEmailRequest redeems the token from a verification email.
+type VerifyEmailRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Token string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+}
+
+func (x *VerifyEmailRequest) Reset() {
+	*x = VerifyEmailRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerifyEmailRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyEmailRequest) ProtoMessage() {}
+
+func (x *VerifyEmailRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*VerifyEmailRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *VerifyEmailRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+// VerifyEmailResponse contains the now verified user.
+type VerifyEmailResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	User *User `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+}
+
+func (x *VerifyEmailResponse) Reset() {
+	*x = VerifyEmailResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerifyEmailResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyEmailResponse) ProtoMessage() {}
+
+func (x *VerifyEmailResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*VerifyEmailResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *VerifyEmailResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+// ChangePasswordRequest replaces the caller's password.
+type ChangePasswordRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id              string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CurrentPassword string `protobuf:"bytes,2,opt,name=current_password,json=currentPassword,proto3" json:"current_password,omitempty"`
+	NewPassword     string `protobuf:"bytes,3,opt,name=new_password,json=newPassword,proto3" json:"new_password,omitempty"`
+}
+
+func (x *ChangePasswordRequest) Reset() {
+	*x = ChangePasswordRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChangePasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangePasswordRequest) ProtoMessage() {}
+
+func (x *ChangePasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*ChangePasswordRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ChangePasswordRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ChangePasswordRequest) GetCurrentPassword() string {
+	if x != nil {
+		return x.CurrentPassword
+	}
+	return ""
+}
+
+func (x *ChangePasswordRequest) GetNewPassword() string {
+	if x != nil {
+		return x.NewPassword
+	}
+	return ""
+}
+
+// ChangePasswordResponse is returned once the password has been changed.
+type ChangePasswordResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ChangePasswordResponse) Reset() {
+	*x = ChangePasswordResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChangePasswordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangePasswordResponse) ProtoMessage() {}
+
+func (x *ChangePasswordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*ChangePasswordResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{22}
+}
+
+// EnrollMFARequest starts TOTP enrollment for the caller.
+type EnrollMFARequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *EnrollMFARequest) Reset() {
+	*x = EnrollMFARequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EnrollMFARequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnrollMFARequest) ProtoMessage() {}
+
+func (x *EnrollMFARequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*EnrollMFARequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{23}
+}
+
+// EnrollMFAResponse carries the new secret and an otpauth:// URI for it.
+type EnrollMFAResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Secret string `protobuf:"bytes,1,opt,name=secret,proto3" json:"secret,omitempty"`
+	Uri    string `protobuf:"bytes,2,opt,name=uri,proto3" json:"uri,omitempty"`
+}
+
+func (x *EnrollMFAResponse) Reset() {
+	*x = EnrollMFAResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EnrollMFAResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnrollMFAResponse) ProtoMessage() {}
+
+func (x *EnrollMFAResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*EnrollMFAResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *EnrollMFAResponse) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+func (x *EnrollMFAResponse) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+// ConfirmMFARequest activates the pending enrollment with a first code.
+type ConfirmMFARequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Code string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+}
+
+func (x *ConfirmMFARequest) Reset() {
+	*x = ConfirmMFARequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConfirmMFARequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmMFARequest) ProtoMessage() {}
+
+func (x *ConfirmMFARequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*ConfirmMFARequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ConfirmMFARequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+// ConfirmMFAResponse lists the recovery codes, which are never shown again.
+type ConfirmMFAResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RecoveryCodes []string `protobuf:"bytes,1,rep,name=recovery_codes,json=recoveryCodes,proto3" json:"recovery_codes,omitempty"`
+}
+
+func (x *ConfirmMFAResponse) Reset() {
+	*x = ConfirmMFAResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConfirmMFAResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmMFAResponse) ProtoMessage() {}
+
+func (x *ConfirmMFAResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*ConfirmMFAResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ConfirmMFAResponse) GetRecoveryCodes() []string {
+	if x != nil {
+		return x.RecoveryCodes
+	}
+	return nil
+}
+
+// DisableMFARequest turns two-factor authentication off with a current code.
+type DisableMFARequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Code string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+}
+
+func (x *DisableMFARequest) Reset() {
+	*x = DisableMFARequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DisableMFARequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisableMFARequest) ProtoMessage() {}
+
+func (x *DisableMFARequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*DisableMFARequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *DisableMFARequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+// DisableMFAResponse is returned once two-factor authentication is off.
+type DisableMFAResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DisableMFAResponse) Reset() {
+	*x = DisableMFAResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DisableMFAResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisableMFAResponse) ProtoMessage() {}
+
+func (x *DisableMFAResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*DisableMFAResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{28}
+}
+
+// LoginMFARequest exchanges an mfa_required challenge and a TOTP or recovery code.
+type LoginMFARequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ChallengeToken string `protobuf:"bytes,1,opt,name=challenge_token,json=challengeToken,proto3" json:"challenge_token,omitempty"`
+	Code           string `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+}
+
+func (x *LoginMFARequest) Reset() {
+	*x = LoginMFARequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LoginMFARequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginMFARequest) ProtoMessage() {}
+
+func (x *LoginMFARequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*LoginMFARequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *LoginMFARequest) GetChallengeToken() string {
+	if x != nil {
+		return x.ChallengeToken
+	}
+	return ""
+}
+
+func (x *LoginMFARequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+// LoginMFAResponse wraps the signed-in user and issued tokens.
+type LoginMFAResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	User         *User  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Token        string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	RefreshToken string `protobuf:"bytes,3,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+}
+
+func (x *LoginMFAResponse) Reset() {
+	*x = LoginMFAResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LoginMFAResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginMFAResponse) ProtoMessage() {}
+
+func (x *LoginMFAResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*LoginMFAResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *LoginMFAResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *LoginMFAResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *LoginMFAResponse) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+// WatchUsersRequest starts a stream of user changes. Set resume_token to the
+// token of the last change seen to pick up where a previous stream left off.
+type WatchUsersRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ResumeToken string `protobuf:"bytes,1,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+}
+
+func (x *WatchUsersRequest) Reset() {
+	*x = WatchUsersRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchUsersRequest) ProtoMessage() {}
+
+func (x *WatchUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*WatchUsersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *WatchUsersRequest) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+// WatchUsersResponse is one user change. user is empty for deletions.
+type WatchUsersResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type        UserEventType `protobuf:"varint,1,opt,name=type,proto3,enum=user.v1.UserEventType" json:"type,omitempty"`
+	UserId      string        `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	User        *User         `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+	OccurredAt  string        `protobuf:"bytes,4,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	ResumeToken string        `protobuf:"bytes,5,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+}
+
+func (x *WatchUsersResponse) Reset() {
+	*x = WatchUsersResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_user_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchUsersResponse) ProtoMessage() {}
+
+func (x *WatchUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*WatchUsersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *WatchUsersResponse) GetType() UserEventType {
+	if x != nil {
+		return x.Type
+	}
+	return UserEventType_USER_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *WatchUsersResponse) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *WatchUsersResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *WatchUsersResponse) GetOccurredAt() string {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return ""
+}
+
+func (x *WatchUsersResponse) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+var file_proto_user_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (AuthPolicy)(0),
+		Field:         50001,
+		Name:          "user.v1.auth_policy",
+		Tag:           "varint,50001,opt,name=user.v1.auth_policy,json=authPolicy,enum=user.v1.AuthPolicy",
+		Filename:      "proto/user.proto",
+	},
+}
+
+// Extension fields to MethodOptions.
+var (
+	//
+	// optional user.v1.AuthPolicy auth_policy = 50001;
+	E_AuthPolicy = &file_proto_user_proto_extTypes[0] // AuthPolicy
+)
+
+var File_proto_user_proto protoreflect.FileDescriptor
+
+var file_proto_user_proto_rawDescOnce sync.Once
+var file_proto_user_proto_rawDescData = buildProtoUserRawDesc()
+
+func file_proto_user_proto_rawDescGZIP() []byte {
+	file_proto_user_proto_rawDescOnce.Do(func() {
+		file_proto_user_proto_rawDescData = protoimpl.X.CompressGZIP(file_proto_user_proto_rawDescData)
+	})
+	return file_proto_user_proto_rawDescData
+}
+
+var file_proto_user_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_user_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
+var file_proto_user_proto_goTypes = []interface{}{
+	(AuthPolicy)(0),                    // 0: user.v1.AuthPolicy
+	(UserEventType)(0),                 // 1: user.v1.UserEventType
+	(*User)(nil),                       // 2: user.v1.User
+	(*CreateUserRequest)(nil),          // 3: user.v1.CreateUserRequest
+	(*CreateUserResponse)(nil),         // 4: user.v1.CreateUserResponse
+	(*LoginRequest)(nil),               // 5: user.v1.LoginRequest
+	(*LoginResponse)(nil),              // 6: user.v1.LoginResponse
+	(*GetUserRequest)(nil),             // 7: user.v1.GetUserRequest
+	(*GetUserResponse)(nil),            // 8: user.v1.GetUserResponse
+	(*ListUsersRequest)(nil),           // 9: user.v1.ListUsersRequest
+	(*ListUsersResponse)(nil),          // 10: user.v1.ListUsersResponse
+	(*UpdateUserRequest)(nil),          // 11: user.v1.UpdateUserRequest
+	(*UpdateUserResponse)(nil),         // 12: user.v1.UpdateUserResponse
+	(*DeleteUserRequest)(nil),          // 13: user.v1.DeleteUserRequest
+	(*DeleteUserResponse)(nil),         // 14: user.v1.DeleteUserResponse
+	(*RestoreUserRequest)(nil),         // 15: user.v1.RestoreUserRequest
+	(*RestoreUserResponse)(nil),        // 16: user.v1.RestoreUserResponse
+	(*ForgotPasswordRequest)(nil),      // 17: user.v1.ForgotPasswordRequest
+	(*ForgotPasswordResponse)(nil),     // 18: user.v1.ForgotPasswordResponse
+	(*ResetPasswordRequest)(nil),       // 19: user.v1.ResetPasswordRequest
+	(*ResetPasswordResponse)(nil),      // 20: user.v1.ResetPasswordResponse
+	(*VerifyEmailRequest)(nil),         // 21: user.v1.VerifyEmailRequest
+	(*VerifyEmailResponse)(nil),        // 22: user.v1.VerifyEmailResponse
+	(*ChangePasswordRequest)(nil),      // 23: user.v1.ChangePasswordRequest
+	(*ChangePasswordResponse)(nil),     // 24: user.v1.ChangePasswordResponse
+	(*EnrollMFARequest)(nil),           // 25: user.v1.EnrollMFARequest
+	(*EnrollMFAResponse)(nil),          // 26: user.v1.EnrollMFAResponse
+	(*ConfirmMFARequest)(nil),          // 27: user.v1.ConfirmMFARequest
+	(*ConfirmMFAResponse)(nil),         // 28: user.v1.ConfirmMFAResponse
+	(*DisableMFARequest)(nil),          // 29: user.v1.DisableMFARequest
+	(*DisableMFAResponse)(nil),         // 30: user.v1.DisableMFAResponse
+	(*LoginMFARequest)(nil),            // 31: user.v1.LoginMFARequest
+	(*LoginMFAResponse)(nil),           // 32: user.v1.LoginMFAResponse
+	(*WatchUsersRequest)(nil),          // 33: user.v1.WatchUsersRequest
+	(*WatchUsersResponse)(nil),         // 34: user.v1.WatchUsersResponse
+	(*fieldmaskpb.FieldMask)(nil),      // 35: google.protobuf.FieldMask
+	(*descriptorpb.MethodOptions)(nil), // 36: google.protobuf.MethodOptions
+}
+var file_proto_user_proto_depIdxs = []int32{
+	2,  // 0: user.v1.CreateUserResponse.user:type_name -> user.v1.User
+	2,  // 1: user.v1.LoginResponse.user:type_name -> user.v1.User
+	35, // 2: user.v1.GetUserRequest.read_mask:type_name -> google.protobuf.FieldMask
+	2,  // 3: user.v1.GetUserResponse.user:type_name -> user.v1.User
+	35, // 4: user.v1.ListUsersRequest.read_mask:type_name -> google.protobuf.FieldMask
+	2,  // 5: user.v1.ListUsersResponse.users:type_name -> user.v1.User
+	2,  // 6: user.v1.UpdateUserRequest.user:type_name -> user.v1.User
+	35, // 7: user.v1.UpdateUserRequest.update_mask:type_name -> google.protobuf.FieldMask
+	2,  // 8: user.v1.UpdateUserResponse.user:type_name -> user.v1.User
+	2,  // 9: user.v1.RestoreUserResponse.user:type_name -> user.v1.User
+	2,  // 10: user.v1.VerifyEmailResponse.user:type_name -> user.v1.User
+	2,  // 11: user.v1.LoginMFAResponse.user:type_name -> user.v1.User
+	1,  // 12: user.v1.WatchUsersResponse.type:type_name -> user.v1.UserEventType
+	2,  // 13: user.v1.WatchUsersResponse.user:type_name -> user.v1.User
+	36, // 14: user.v1.auth_policy:extendee -> google.protobuf.MethodOptions
+	0,  // 15: user.v1.auth_policy:type_name -> user.v1.AuthPolicy
+	3,  // 16: user.v1.UserService.CreateUser:input_type -> user.v1.CreateUserRequest
+	5,  // 17: user.v1.UserService.Login:input_type -> user.v1.LoginRequest
+	7,  // 18: user.v1.UserService.GetUser:input_type -> user.v1.GetUserRequest
+	9,  // 19: user.v1.UserService.ListUsers:input_type -> user.v1.ListUsersRequest
+	11, // 20: user.v1.UserService.UpdateUser:input_type -> user.v1.UpdateUserRequest
+	13, // 21: user.v1.UserService.DeleteUser:input_type -> user.v1.DeleteUserRequest
+	15, // 22: user.v1.UserService.RestoreUser:input_type -> user.v1.RestoreUserRequest
+	17, // 23: user.v1.UserService.ForgotPassword:input_type -> user.v1.ForgotPasswordRequest
+	19, // 24: user.v1.UserService.ResetPassword:input_type -> user.v1.ResetPasswordRequest
+	21, // 25: user.v1.UserService.VerifyEmail:input_type -> user.v1.VerifyEmailRequest
+	23, // 26: user.v1.UserService.ChangePassword:input_type -> user.v1.ChangePasswordRequest
+	25, // 27: user.v1.UserService.EnrollMFA:input_type -> user.v1.EnrollMFARequest
+	27, // 28: user.v1.UserService.ConfirmMFA:input_type -> user.v1.ConfirmMFARequest
+	29, // 29: user.v1.UserService.DisableMFA:input_type -> user.v1.DisableMFARequest
+	31, // 30: user.v1.UserService.LoginMFA:input_type -> user.v1.LoginMFARequest
+	33, // 31: user.v1.UserService.WatchUsers:input_type -> user.v1.WatchUsersRequest
+	4,  // 32: user.v1.UserService.CreateUser:output_type -> user.v1.CreateUserResponse
+	6,  // 33: user.v1.UserService.Login:output_type -> user.v1.LoginResponse
+	8,  // 34: user.v1.UserService.GetUser:output_type -> user.v1.GetUserResponse
+	10, // 35: user.v1.UserService.ListUsers:output_type -> user.v1.ListUsersResponse
+	12, // 36: user.v1.UserService.UpdateUser:output_type -> user.v1.UpdateUserResponse
+	14, // 37: user.v1.UserService.DeleteUser:output_type -> user.v1.DeleteUserResponse
+	16, // 38: user.v1.UserService.RestoreUser:output_type -> user.v1.RestoreUserResponse
+	18, // 39: user.v1.UserService.ForgotPassword:output_type -> user.v1.ForgotPasswordResponse
+	20, // 40: user.v1.UserService.ResetPassword:output_type -> user.v1.ResetPasswordResponse
+	22, // 41: user.v1.UserService.VerifyEmail:output_type -> user.v1.VerifyEmailResponse
+	24, // 42: user.v1.UserService.ChangePassword:output_type -> user.v1.ChangePasswordResponse
+	26, // 43: user.v1.UserService.EnrollMFA:output_type -> user.v1.EnrollMFAResponse
+	28, // 44: user.v1.UserService.ConfirmMFA:output_type -> user.v1.ConfirmMFAResponse
+	30, // 45: user.v1.UserService.DisableMFA:output_type -> user.v1.DisableMFAResponse
+	32, // 46: user.v1.UserService.LoginMFA:output_type -> user.v1.LoginMFAResponse
+	34, // 47: user.v1.UserService.WatchUsers:output_type -> user.v1.WatchUsersResponse
+	32, // [32:48] is the sub-list for method output_type
+	16, // [16:32] is the sub-list for method input_type
+	15, // [15:16] is the sub-list for extension type_name
+	14, // [14:15] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_proto_user_proto_init() }
+
+func file_proto_user_proto_init() {
+	if File_proto_user_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_proto_user_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*User); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateUserRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateUserResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LoginRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LoginResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetUserRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetUserResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListUsersRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListUsersResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateUserRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateUserResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteUserRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteUserResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RestoreUserRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RestoreUserResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ForgotPasswordRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ForgotPasswordResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResetPasswordRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResetPasswordResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyEmailRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyEmailResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChangePasswordRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChangePasswordResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EnrollMFARequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EnrollMFAResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConfirmMFARequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConfirmMFAResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DisableMFARequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DisableMFAResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LoginMFARequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LoginMFAResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchUsersRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_user_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchUsersResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_proto_user_proto_rawDesc,
+			NumEnums:      2,
+			NumMessages:   33,
+			NumExtensions: 1,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_user_proto_goTypes,
+		DependencyIndexes: file_proto_user_proto_depIdxs,
+		EnumInfos:         file_proto_user_proto_enumTypes,
+		MessageInfos:      file_proto_user_proto_msgTypes,
+		ExtensionInfos:    file_proto_user_proto_extTypes,
+	}.Build()
+	File_proto_user_proto = out.File
+	file_proto_user_proto_rawDesc = nil
+	file_proto_user_proto_goTypes = nil
+	file_proto_user_proto_depIdxs = nil
+}
+
+var file_proto_user_proto_rawDesc []byte
+
+func buildProtoUserRawDesc() []byte {
+	fd := &descriptorpb.FileDescriptorProto{
+		Name:    strPtr("proto/user.proto"),
+		Package: strPtr("user.v1"),
+		Dependency: []string{
+			"google/protobuf/descriptor.proto",
+			"google/protobuf/field_mask.proto",
+		},
+		Syntax: strPtr("proto3"),
+		Options: &descriptorpb.FileOptions{
+			GoPackage: strPtr("backend-challenge/proto/userpb;userpb"),
+		},
+	}
+
+	fd.EnumType = []*descriptorpb.EnumDescriptorProto{
+		buildAuthPolicyEnum(),
+		buildUserEventTypeEnum(),
+	}
+
+	fd.MessageType = []*descriptorpb.DescriptorProto{
+		buildUserMessage(),
+		buildCreateUserRequestMessage(),
+		buildCreateUserResponseMessage(),
+		buildLoginRequestMessage(),
+		buildLoginResponseMessage(),
+		buildGetUserRequestMessage(),
+		buildGetUserResponseMessage(),
+		buildListUsersRequestMessage(),
+		buildListUsersResponseMessage(),
+		buildUpdateUserRequestMessage(),
+		buildUpdateUserResponseMessage(),
+		buildDeleteUserRequestMessage(),
+		buildDeleteUserResponseMessage(),
+		buildRestoreUserRequestMessage(),
+		buildRestoreUserResponseMessage(),
+		buildForgotPasswordRequestMessage(),
+		buildForgotPasswordResponseMessage(),
+		buildResetPasswordRequestMessage(),
+		buildResetPasswordResponseMessage(),
+		buildVerifyEmailRequestMessage(),
+		buildVerifyEmailResponseMessage(),
+		buildChangePasswordRequestMessage(),
+		buildChangePasswordResponseMessage(),
+		buildEnrollMFARequestMessage(),
+		buildEnrollMFAResponseMessage(),
+		buildConfirmMFARequestMessage(),
+		buildConfirmMFAResponseMessage(),
+		buildDisableMFARequestMessage(),
+		buildDisableMFAResponseMessage(),
+		buildLoginMFARequestMessage(),
+		buildLoginMFAResponseMessage(),
+		buildWatchUsersRequestMessage(),
+		buildWatchUsersResponseMessage(),
+	}
+
+	fd.Extension = []*descriptorpb.FieldDescriptorProto{
+		{
+			Name:     strPtr("auth_policy"),
+			Extendee: strPtr(".google.protobuf.MethodOptions"),
+			Number:   int32Ptr(50001),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_ENUM.Enum(),
+			TypeName: strPtr(".user.v1.AuthPolicy"),
+			JsonName: strPtr("authPolicy"),
+		},
+	}
+
+	fd.Service = []*descriptorpb.ServiceDescriptorProto{
+		buildUserServiceDescriptor(),
+	}
+
+	b, err := proto.Marshal(fd)
+	if err != nil {
+		panic(err)
+	}
+
+	file_proto_user_proto_rawDesc = b
+	return b
+}
+
+func buildAuthPolicyEnum() *descriptorpb.EnumDescriptorProto {
+	return &descriptorpb.EnumDescriptorProto{
+		Name: strPtr("AuthPolicy"),
+		Value: []*descriptorpb.EnumValueDescriptorProto{
+			{Name: strPtr("AUTH_POLICY_UNSPECIFIED"), Number: int32Ptr(0)},
+			{Name: strPtr("AUTH_POLICY_PUBLIC"), Number: int32Ptr(1)},
+			{Name: strPtr("AUTH_POLICY_AUTHENTICATED"), Number: int32Ptr(2)},
+			{Name: strPtr("AUTH_POLICY_SELF"), Number: int32Ptr(3)},
+			{Name: strPtr("AUTH_POLICY_ADMIN"), Number: int32Ptr(4)},
+		},
+	}
+}
+
+func buildUserEventTypeEnum() *descriptorpb.EnumDescriptorProto {
+	return &descriptorpb.EnumDescriptorProto{
+		Name: strPtr("UserEventType"),
+		Value: []*descriptorpb.EnumValueDescriptorProto{
+			{Name: strPtr("USER_EVENT_TYPE_UNSPECIFIED"), Number: int32Ptr(0)},
+			{Name: strPtr("USER_EVENT_TYPE_CREATED"), Number: int32Ptr(1)},
+			{Name: strPtr("USER_EVENT_TYPE_UPDATED"), Number: int32Ptr(2)},
+			{Name: strPtr("USER_EVENT_TYPE_DELETED"), Number: int32Ptr(3)},
+		},
+	}
+}
+
+func buildUserMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("User"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("id"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("id"),
+			},
+			{
+				Name:     strPtr("name"),
+				Number:   int32Ptr(2),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("name"),
+			},
+			{
+				Name:     strPtr("email"),
+				Number:   int32Ptr(3),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("email"),
+			},
+			{
+				Name:     strPtr("created_at"),
+				Number:   int32Ptr(4),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("createdAt"),
+			},
+			{
+				Name:     strPtr("email_verified"),
+				Number:   int32Ptr(5),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_BOOL.Enum(),
+				JsonName: strPtr("emailVerified"),
+			},
+			{
+				Name:     strPtr("mfa_enabled"),
+				Number:   int32Ptr(6),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_BOOL.Enum(),
+				JsonName: strPtr("mfaEnabled"),
+			},
+			{
+				Name:     strPtr("roles"),
+				Number:   int32Ptr(7),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("roles"),
+			},
+			{
+				Name:     strPtr("deleted_at"),
+				Number:   int32Ptr(8),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("deletedAt"),
+			},
+			{
+				Name:     strPtr("etag"),
+				Number:   int32Ptr(9),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("etag"),
+			},
+		},
+	}
+}
+
+func buildCreateUserRequestMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("CreateUserRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("name"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("name"),
+			},
+			{
+				Name:     strPtr("email"),
+				Number:   int32Ptr(2),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("email"),
+			},
+			{
+				Name:     strPtr("password"),
+				Number:   int32Ptr(3),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("password"),
+			},
+		},
+	}
+}
+
+func buildCreateUserResponseMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("CreateUserResponse"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("user"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: strPtr(".user.v1.User"),
+				JsonName: strPtr("user"),
+			},
+			{
+				Name:     strPtr("token"),
+				Number:   int32Ptr(2),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("token"),
+			},
+			{
+				Name:     strPtr("refresh_token"),
+				Number:   int32Ptr(3),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("refreshToken"),
+			},
+		},
+	}
+}
+
+func buildLoginRequestMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("LoginRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("email"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("email"),
+			},
+			{
+				Name:     strPtr("password"),
+				Number:   int32Ptr(2),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("password"),
+			},
+		},
+	}
+}
+
+func buildLoginResponseMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("LoginResponse"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("user"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: strPtr(".user.v1.User"),
+				JsonName: strPtr("user"),
+			},
+			{
+				Name:     strPtr("token"),
+				Number:   int32Ptr(2),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("token"),
+			},
+			{
+				Name:     strPtr("mfa_required"),
+				Number:   int32Ptr(3),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_BOOL.Enum(),
+				JsonName: strPtr("mfaRequired"),
+			},
+			{
+				Name:     strPtr("challenge_token"),
+				Number:   int32Ptr(4),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("challengeToken"),
+			},
+			{
+				Name:     strPtr("challenge_expires_at"),
+				Number:   int32Ptr(5),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("challengeExpiresAt"),
+			},
+			{
+				Name:     strPtr("refresh_token"),
+				Number:   int32Ptr(6),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("refreshToken"),
+			},
+		},
+	}
+}
+
+func buildGetUserRequestMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("GetUserRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("id"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("id"),
+			},
+			{
+				Name:     strPtr("read_mask"),
+				Number:   int32Ptr(2),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: strPtr(".google.protobuf.FieldMask"),
+				JsonName: strPtr("readMask"),
+			},
+		},
+	}
+}
+
+func buildGetUserResponseMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("GetUserResponse"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("user"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: strPtr(".user.v1.User"),
+				JsonName: strPtr("user"),
+			},
+		},
+	}
+}
+
+func buildListUsersRequestMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("ListUsersRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("page_size"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+				JsonName: strPtr("pageSize"),
+			},
+			{
+				Name:     strPtr("page_token"),
+				Number:   int32Ptr(2),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("pageToken"),
+			},
+			{
+				Name:     strPtr("read_mask"),
+				Number:   int32Ptr(3),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: strPtr(".google.protobuf.FieldMask"),
+				JsonName: strPtr("readMask"),
+			},
+			{
+				Name:     strPtr("include_deleted"),
+				Number:   int32Ptr(4),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_BOOL.Enum(),
+				JsonName: strPtr("includeDeleted"),
+			},
+		},
+	}
+}
+
+func buildListUsersResponseMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("ListUsersResponse"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("users"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: strPtr(".user.v1.User"),
+				JsonName: strPtr("users"),
+			},
+			{
+				Name:     strPtr("next_page_token"),
+				Number:   int32Ptr(2),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("nextPageToken"),
+			},
+		},
+	}
+}
+
+func buildUpdateUserRequestMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("UpdateUserRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("id"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("id"),
+			},
+			{
+				Name:     strPtr("user"),
+				Number:   int32Ptr(2),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: strPtr(".user.v1.User"),
+				JsonName: strPtr("user"),
+			},
+			{
+				Name:     strPtr("update_mask"),
+				Number:   int32Ptr(3),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: strPtr(".google.protobuf.FieldMask"),
+				JsonName: strPtr("updateMask"),
+			},
+			{
+				Name:     strPtr("etag"),
+				Number:   int32Ptr(4),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("etag"),
+			},
+		},
+	}
+}
+
+func buildUpdateUserResponseMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("UpdateUserResponse"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("user"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: strPtr(".user.v1.User"),
+				JsonName: strPtr("user"),
+			},
+		},
+	}
+}
+
+func buildDeleteUserRequestMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("DeleteUserRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("id"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("id"),
+			},
+		},
 	}
-	type x struct{}
-	out := protoimpl.TypeBuilder{
-		File: protoimpl.DescBuilder{
-			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_proto_user_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   5,
-			NumExtensions: 0,
-			NumServices:   1,
+}
+
+func buildDeleteUserResponseMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("DeleteUserResponse"),
+	}
+}
+
+func buildRestoreUserRequestMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("RestoreUserRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("id"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("id"),
+			},
 		},
-		GoTypes:           file_proto_user_proto_goTypes,
-		DependencyIndexes: file_proto_user_proto_depIdxs,
-		MessageInfos:      file_proto_user_proto_msgTypes,
-	}.Build()
-	File_proto_user_proto = out.File
-	file_proto_user_proto_rawDesc = nil
-	file_proto_user_proto_goTypes = nil
-	file_proto_user_proto_depIdxs = nil
+	}
 }
 
-var file_proto_user_proto_rawDesc []byte
+func buildRestoreUserResponseMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("RestoreUserResponse"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("user"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: strPtr(".user.v1.User"),
+				JsonName: strPtr("user"),
+			},
+		},
+	}
+}
 
-func buildProtoUserRawDesc() []byte {
-	fd := &descriptorpb.FileDescriptorProto{
-		Name:    strPtr("proto/user.proto"),
-		Package: strPtr("user.v1"),
-		Syntax:  strPtr("proto3"),
-		Options: &descriptorpb.FileOptions{
-			GoPackage: strPtr("backend-challenge/proto/userpb;userpb"),
+func buildForgotPasswordRequestMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("ForgotPasswordRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("email"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("email"),
+			},
 		},
 	}
+}
 
-	fd.MessageType = []*descriptorpb.DescriptorProto{
-		buildUserMessage(),
-		buildCreateUserRequestMessage(),
-		buildCreateUserResponseMessage(),
-		buildGetUserRequestMessage(),
-		buildGetUserResponseMessage(),
+func buildForgotPasswordResponseMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("ForgotPasswordResponse"),
 	}
+}
 
-	fd.Service = []*descriptorpb.ServiceDescriptorProto{
-		buildUserServiceDescriptor(),
+func buildResetPasswordRequestMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("ResetPasswordRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("token"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("token"),
+			},
+			{
+				Name:     strPtr("password"),
+				Number:   int32Ptr(2),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("password"),
+			},
+		},
 	}
+}
 
-	b, err := proto.Marshal(fd)
-	if err != nil {
-		panic(err)
+func buildResetPasswordResponseMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("ResetPasswordResponse"),
 	}
+}
 
-	file_proto_user_proto_rawDesc = b
-	return b
+func buildVerifyEmailRequestMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("VerifyEmailRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("token"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("token"),
+			},
+		},
+	}
 }
 
-func buildUserMessage() *descriptorpb.DescriptorProto {
+func buildVerifyEmailResponseMessage() *descriptorpb.DescriptorProto {
 	return &descriptorpb.DescriptorProto{
-		Name: strPtr("User"),
+		Name: strPtr("VerifyEmailResponse"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("user"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: strPtr(".user.v1.User"),
+				JsonName: strPtr("user"),
+			},
+		},
+	}
+}
+
+func buildChangePasswordRequestMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("ChangePasswordRequest"),
 		Field: []*descriptorpb.FieldDescriptorProto{
 			{
 				Name:     strPtr("id"),
@@ -468,62 +3112,133 @@ func buildUserMessage() *descriptorpb.DescriptorProto {
 				JsonName: strPtr("id"),
 			},
 			{
-				Name:     strPtr("name"),
+				Name:     strPtr("current_password"),
 				Number:   int32Ptr(2),
 				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
 				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
-				JsonName: strPtr("name"),
+				JsonName: strPtr("currentPassword"),
 			},
 			{
-				Name:     strPtr("email"),
+				Name:     strPtr("new_password"),
 				Number:   int32Ptr(3),
 				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
 				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
-				JsonName: strPtr("email"),
+				JsonName: strPtr("newPassword"),
+			},
+		},
+	}
+}
+
+func buildChangePasswordResponseMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("ChangePasswordResponse"),
+	}
+}
+
+func buildEnrollMFARequestMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("EnrollMFARequest"),
+	}
+}
+
+func buildEnrollMFAResponseMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("EnrollMFAResponse"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("secret"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("secret"),
 			},
 			{
-				Name:     strPtr("created_at"),
-				Number:   int32Ptr(4),
+				Name:     strPtr("uri"),
+				Number:   int32Ptr(2),
 				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
 				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
-				JsonName: strPtr("createdAt"),
+				JsonName: strPtr("uri"),
 			},
 		},
 	}
 }
 
-func buildCreateUserRequestMessage() *descriptorpb.DescriptorProto {
+func buildConfirmMFARequestMessage() *descriptorpb.DescriptorProto {
 	return &descriptorpb.DescriptorProto{
-		Name: strPtr("CreateUserRequest"),
+		Name: strPtr("ConfirmMFARequest"),
 		Field: []*descriptorpb.FieldDescriptorProto{
 			{
-				Name:     strPtr("name"),
+				Name:     strPtr("code"),
 				Number:   int32Ptr(1),
 				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
 				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
-				JsonName: strPtr("name"),
+				JsonName: strPtr("code"),
+			},
+		},
+	}
+}
+
+func buildConfirmMFAResponseMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("ConfirmMFAResponse"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     strPtr("recovery_codes"),
+				Number:   int32Ptr(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("recoveryCodes"),
 			},
+		},
+	}
+}
+
+func buildDisableMFARequestMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("DisableMFARequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
 			{
-				Name:     strPtr("email"),
-				Number:   int32Ptr(2),
+				Name:     strPtr("code"),
+				Number:   int32Ptr(1),
 				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
 				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
-				JsonName: strPtr("email"),
+				JsonName: strPtr("code"),
 			},
+		},
+	}
+}
+
+func buildDisableMFAResponseMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("DisableMFAResponse"),
+	}
+}
+
+func buildLoginMFARequestMessage() *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: strPtr("LoginMFARequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
 			{
-				Name:     strPtr("password"),
-				Number:   int32Ptr(3),
+				Name:     strPtr("challenge_token"),
+				Number:   int32Ptr(1),
 				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
 				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
-				JsonName: strPtr("password"),
+				JsonName: strPtr("challengeToken"),
+			},
+			{
+				Name:     strPtr("code"),
+				Number:   int32Ptr(2),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("code"),
 			},
 		},
 	}
 }
 
-func buildCreateUserResponseMessage() *descriptorpb.DescriptorProto {
+func buildLoginMFAResponseMessage() *descriptorpb.DescriptorProto {
 	return &descriptorpb.DescriptorProto{
-		Name: strPtr("CreateUserResponse"),
+		Name: strPtr("LoginMFAResponse"),
 		Field: []*descriptorpb.FieldDescriptorProto{
 			{
 				Name:     strPtr("user"),
@@ -540,37 +3255,73 @@ func buildCreateUserResponseMessage() *descriptorpb.DescriptorProto {
 				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
 				JsonName: strPtr("token"),
 			},
+			{
+				Name:     strPtr("refresh_token"),
+				Number:   int32Ptr(3),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("refreshToken"),
+			},
 		},
 	}
 }
 
-func buildGetUserRequestMessage() *descriptorpb.DescriptorProto {
+func buildWatchUsersRequestMessage() *descriptorpb.DescriptorProto {
 	return &descriptorpb.DescriptorProto{
-		Name: strPtr("GetUserRequest"),
+		Name: strPtr("WatchUsersRequest"),
 		Field: []*descriptorpb.FieldDescriptorProto{
 			{
-				Name:     strPtr("id"),
+				Name:     strPtr("resume_token"),
 				Number:   int32Ptr(1),
 				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
 				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
-				JsonName: strPtr("id"),
+				JsonName: strPtr("resumeToken"),
 			},
 		},
 	}
 }
 
-func buildGetUserResponseMessage() *descriptorpb.DescriptorProto {
+func buildWatchUsersResponseMessage() *descriptorpb.DescriptorProto {
 	return &descriptorpb.DescriptorProto{
-		Name: strPtr("GetUserResponse"),
+		Name: strPtr("WatchUsersResponse"),
 		Field: []*descriptorpb.FieldDescriptorProto{
 			{
-				Name:     strPtr("user"),
+				Name:     strPtr("type"),
 				Number:   int32Ptr(1),
 				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_ENUM.Enum(),
+				TypeName: strPtr(".user.v1.UserEventType"),
+				JsonName: strPtr("type"),
+			},
+			{
+				Name:     strPtr("user_id"),
+				Number:   int32Ptr(2),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("userId"),
+			},
+			{
+				Name:     strPtr("user"),
+				Number:   int32Ptr(3),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
 				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
 				TypeName: strPtr(".user.v1.User"),
 				JsonName: strPtr("user"),
 			},
+			{
+				Name:     strPtr("occurred_at"),
+				Number:   int32Ptr(4),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("occurredAt"),
+			},
+			{
+				Name:     strPtr("resume_token"),
+				Number:   int32Ptr(5),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: strPtr("resumeToken"),
+			},
 		},
 	}
 }
@@ -583,11 +3334,130 @@ func buildUserServiceDescriptor() *descriptorpb.ServiceDescriptorProto {
 				Name:       strPtr("CreateUser"),
 				InputType:  strPtr(".user.v1.CreateUserRequest"),
 				OutputType: strPtr(".user.v1.CreateUserResponse"),
+				Options: encodeMethodOptions(
+					50001, AuthPolicy_AUTH_POLICY_PUBLIC,
+				),
+			},
+			{
+				Name:       strPtr("Login"),
+				InputType:  strPtr(".user.v1.LoginRequest"),
+				OutputType: strPtr(".user.v1.LoginResponse"),
+				Options: encodeMethodOptions(
+					50001, AuthPolicy_AUTH_POLICY_PUBLIC,
+				),
 			},
 			{
 				Name:       strPtr("GetUser"),
 				InputType:  strPtr(".user.v1.GetUserRequest"),
 				OutputType: strPtr(".user.v1.GetUserResponse"),
+				Options: encodeMethodOptions(
+					50001, AuthPolicy_AUTH_POLICY_AUTHENTICATED,
+				),
+			},
+			{
+				Name:       strPtr("ListUsers"),
+				InputType:  strPtr(".user.v1.ListUsersRequest"),
+				OutputType: strPtr(".user.v1.ListUsersResponse"),
+				Options: encodeMethodOptions(
+					50001, AuthPolicy_AUTH_POLICY_ADMIN,
+				),
+			},
+			{
+				Name:       strPtr("UpdateUser"),
+				InputType:  strPtr(".user.v1.UpdateUserRequest"),
+				OutputType: strPtr(".user.v1.UpdateUserResponse"),
+				Options: encodeMethodOptions(
+					50001, AuthPolicy_AUTH_POLICY_AUTHENTICATED,
+				),
+			},
+			{
+				Name:       strPtr("DeleteUser"),
+				InputType:  strPtr(".user.v1.DeleteUserRequest"),
+				OutputType: strPtr(".user.v1.DeleteUserResponse"),
+				Options: encodeMethodOptions(
+					50001, AuthPolicy_AUTH_POLICY_AUTHENTICATED,
+				),
+			},
+			{
+				Name:       strPtr("RestoreUser"),
+				InputType:  strPtr(".user.v1.RestoreUserRequest"),
+				OutputType: strPtr(".user.v1.RestoreUserResponse"),
+				Options: encodeMethodOptions(
+					50001, AuthPolicy_AUTH_POLICY_ADMIN,
+				),
+			},
+			{
+				Name:       strPtr("ForgotPassword"),
+				InputType:  strPtr(".user.v1.ForgotPasswordRequest"),
+				OutputType: strPtr(".user.v1.ForgotPasswordResponse"),
+				Options: encodeMethodOptions(
+					50001, AuthPolicy_AUTH_POLICY_PUBLIC,
+				),
+			},
+			{
+				Name:       strPtr("ResetPassword"),
+				InputType:  strPtr(".user.v1.ResetPasswordRequest"),
+				OutputType: strPtr(".user.v1.ResetPasswordResponse"),
+				Options: encodeMethodOptions(
+					50001, AuthPolicy_AUTH_POLICY_PUBLIC,
+				),
+			},
+			{
+				Name:       strPtr("VerifyEmail"),
+				InputType:  strPtr(".user.v1.VerifyEmailRequest"),
+				OutputType: strPtr(".user.v1.VerifyEmailResponse"),
+				Options: encodeMethodOptions(
+					50001, AuthPolicy_AUTH_POLICY_PUBLIC,
+				),
+			},
+			{
+				Name:       strPtr("ChangePassword"),
+				InputType:  strPtr(".user.v1.ChangePasswordRequest"),
+				OutputType: strPtr(".user.v1.ChangePasswordResponse"),
+				Options: encodeMethodOptions(
+					50001, AuthPolicy_AUTH_POLICY_SELF,
+				),
+			},
+			{
+				Name:       strPtr("EnrollMFA"),
+				InputType:  strPtr(".user.v1.EnrollMFARequest"),
+				OutputType: strPtr(".user.v1.EnrollMFAResponse"),
+				Options: encodeMethodOptions(
+					50001, AuthPolicy_AUTH_POLICY_AUTHENTICATED,
+				),
+			},
+			{
+				Name:       strPtr("ConfirmMFA"),
+				InputType:  strPtr(".user.v1.ConfirmMFARequest"),
+				OutputType: strPtr(".user.v1.ConfirmMFAResponse"),
+				Options: encodeMethodOptions(
+					50001, AuthPolicy_AUTH_POLICY_AUTHENTICATED,
+				),
+			},
+			{
+				Name:       strPtr("DisableMFA"),
+				InputType:  strPtr(".user.v1.DisableMFARequest"),
+				OutputType: strPtr(".user.v1.DisableMFAResponse"),
+				Options: encodeMethodOptions(
+					50001, AuthPolicy_AUTH_POLICY_AUTHENTICATED,
+				),
+			},
+			{
+				Name:       strPtr("LoginMFA"),
+				InputType:  strPtr(".user.v1.LoginMFARequest"),
+				OutputType: strPtr(".user.v1.LoginMFAResponse"),
+				Options: encodeMethodOptions(
+					50001, AuthPolicy_AUTH_POLICY_PUBLIC,
+				),
+			},
+			{
+				Name:            strPtr("WatchUsers"),
+				InputType:       strPtr(".user.v1.WatchUsersRequest"),
+				OutputType:      strPtr(".user.v1.WatchUsersResponse"),
+				ServerStreaming: boolPtr(true),
+				Options: encodeMethodOptions(
+					50001, AuthPolicy_AUTH_POLICY_ADMIN,
+				),
 			},
 		},
 	}
@@ -595,3 +3465,20 @@ func buildUserServiceDescriptor() *descriptorpb.ServiceDescriptorProto {
 
 func strPtr(s string) *string { return &s }
 func int32Ptr(v int32) *int32 { return &v }
+func boolPtr(v bool) *bool    { return &v }
+
+// encodeMethodOptions stores enum-valued extension fields as raw varints.
+// The extensions are declared in this file, so they are not registered yet
+// while the descriptor is being built.
+func encodeMethodOptions(fieldValues ...interface{}) *descriptorpb.MethodOptions {
+	opts := &descriptorpb.MethodOptions{}
+	var raw []byte
+	for i := 0; i+1 < len(fieldValues); i += 2 {
+		field := protowire.Number(fieldValues[i].(int))
+		value := fieldValues[i+1].(protoreflect.Enum).Number()
+		raw = protowire.AppendTag(raw, field, protowire.VarintType)
+		raw = protowire.AppendVarint(raw, uint64(value))
+	}
+	opts.ProtoReflect().SetUnknown(raw)
+	return opts
+}
@@ -2,12 +2,14 @@ package userpb
 
 import (
 	"context"
+	"io"
 	"net"
 	"testing"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 const bufSize = 1024 * 1024
@@ -25,6 +27,73 @@ func (f *fakeUserService) GetUser(ctx context.Context, req *GetUserRequest) (*Ge
 	return &GetUserResponse{User: &User{Id: req.GetId(), Name: "Test", Email: "test@example.com"}}, nil
 }
 
+func (f *fakeUserService) Login(ctx context.Context, req *LoginRequest) (*LoginResponse, error) {
+	return &LoginResponse{User: &User{Id: "1", Email: req.GetEmail()}, Token: "token"}, nil
+}
+
+func (f *fakeUserService) ListUsers(ctx context.Context, req *ListUsersRequest) (*ListUsersResponse, error) {
+	return &ListUsersResponse{Users: []*User{{Id: "1"}, {Id: "2"}}}, nil
+}
+
+func (f *fakeUserService) UpdateUser(ctx context.Context, req *UpdateUserRequest) (*UpdateUserResponse, error) {
+	user := &User{Id: req.GetId()}
+	for _, path := range req.GetUpdateMask().GetPaths() {
+		if path == "name" {
+			user.Name = req.GetUser().GetName()
+		}
+	}
+	return &UpdateUserResponse{User: user}, nil
+}
+
+func (f *fakeUserService) DeleteUser(ctx context.Context, req *DeleteUserRequest) (*DeleteUserResponse, error) {
+	return &DeleteUserResponse{}, nil
+}
+
+func (f *fakeUserService) RestoreUser(ctx context.Context, req *RestoreUserRequest) (*RestoreUserResponse, error) {
+	return &RestoreUserResponse{User: &User{Id: req.GetId()}}, nil
+}
+
+func (f *fakeUserService) ForgotPassword(ctx context.Context, req *ForgotPasswordRequest) (*ForgotPasswordResponse, error) {
+	return &ForgotPasswordResponse{}, nil
+}
+
+func (f *fakeUserService) ResetPassword(ctx context.Context, req *ResetPasswordRequest) (*ResetPasswordResponse, error) {
+	return &ResetPasswordResponse{}, nil
+}
+
+func (f *fakeUserService) VerifyEmail(ctx context.Context, req *VerifyEmailRequest) (*VerifyEmailResponse, error) {
+	return &VerifyEmailResponse{User: &User{Id: "1", EmailVerified: true}}, nil
+}
+
+func (f *fakeUserService) ChangePassword(ctx context.Context, req *ChangePasswordRequest) (*ChangePasswordResponse, error) {
+	return &ChangePasswordResponse{}, nil
+}
+
+func (f *fakeUserService) EnrollMFA(ctx context.Context, req *EnrollMFARequest) (*EnrollMFAResponse, error) {
+	return &EnrollMFAResponse{Secret: "SECRET", Uri: "otpauth://totp/test"}, nil
+}
+
+func (f *fakeUserService) ConfirmMFA(ctx context.Context, req *ConfirmMFARequest) (*ConfirmMFAResponse, error) {
+	return &ConfirmMFAResponse{RecoveryCodes: []string{"AAAA-BBBB", "CCCC-DDDD"}}, nil
+}
+
+func (f *fakeUserService) DisableMFA(ctx context.Context, req *DisableMFARequest) (*DisableMFAResponse, error) {
+	return &DisableMFAResponse{}, nil
+}
+
+func (f *fakeUserService) LoginMFA(ctx context.Context, req *LoginMFARequest) (*LoginMFAResponse, error) {
+	return &LoginMFAResponse{User: &User{Id: "1", MfaEnabled: true}, Token: "token"}, nil
+}
+
+func (f *fakeUserService) WatchUsers(req *WatchUsersRequest, stream UserService_WatchUsersServer) error {
+	for _, id := range []string{"1", "2"} {
+		if err := stream.Send(&WatchUsersResponse{Type: UserEventType_USER_EVENT_TYPE_UPDATED, UserId: id, ResumeToken: req.GetResumeToken() + id}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (f *fakeUserService) mustEmbedUnimplementedUserServiceServer() {}
 
 func TestUserServiceClientServer(t *testing.T) {
@@ -65,4 +134,107 @@ func TestUserServiceClientServer(t *testing.T) {
 	if getResp.User.GetEmail() != "test@example.com" {
 		t.Fatalf("unexpected get response: %+v", getResp)
 	}
+
+	loginResp, err := client.Login(ctx, &LoginRequest{Email: "test@example.com", Password: "pass"})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if loginResp.GetToken() == "" || loginResp.GetMfaRequired() {
+		t.Fatalf("unexpected login response: %+v", loginResp)
+	}
+
+	listResp, err := client.ListUsers(ctx, &ListUsersRequest{})
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+	if len(listResp.GetUsers()) != 2 {
+		t.Fatalf("unexpected list response: %+v", listResp)
+	}
+
+	updateResp, err := client.UpdateUser(ctx, &UpdateUserRequest{
+		Id:         "1",
+		User:       &User{Name: "Renamed", Email: "ignored@example.com"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if updateResp.GetUser().GetName() != "Renamed" || updateResp.GetUser().GetEmail() != "" {
+		t.Fatalf("unexpected update response: %+v", updateResp)
+	}
+
+	if _, err := client.DeleteUser(ctx, &DeleteUserRequest{Id: "1"}); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	restoreResp, err := client.RestoreUser(ctx, &RestoreUserRequest{Id: "1"})
+	if err != nil {
+		t.Fatalf("RestoreUser: %v", err)
+	}
+	if restoreResp.GetUser().GetId() != "1" {
+		t.Fatalf("unexpected restore response: %+v", restoreResp)
+	}
+
+	if _, err := client.ForgotPassword(ctx, &ForgotPasswordRequest{Email: "test@example.com"}); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	if _, err := client.ResetPassword(ctx, &ResetPasswordRequest{Token: "token", Password: "newpass123"}); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+
+	verifyResp, err := client.VerifyEmail(ctx, &VerifyEmailRequest{Token: "token"})
+	if err != nil {
+		t.Fatalf("VerifyEmail: %v", err)
+	}
+	if !verifyResp.User.GetEmailVerified() {
+		t.Fatalf("unexpected verify response: %+v", verifyResp)
+	}
+
+	if _, err := client.ChangePassword(ctx, &ChangePasswordRequest{Id: "1", CurrentPassword: "pass", NewPassword: "newpass123"}); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+
+	confirmResp, err := client.ConfirmMFA(ctx, &ConfirmMFARequest{Code: "123456"})
+	if err != nil {
+		t.Fatalf("ConfirmMFA: %v", err)
+	}
+	if len(confirmResp.GetRecoveryCodes()) != 2 {
+		t.Fatalf("unexpected confirm response: %+v", confirmResp)
+	}
+	mfaResp, err := client.LoginMFA(ctx, &LoginMFARequest{ChallengeToken: "challenge", Code: "123456"})
+	if err != nil {
+		t.Fatalf("LoginMFA: %v", err)
+	}
+	if !mfaResp.GetUser().GetMfaEnabled() || mfaResp.GetToken() == "" {
+		t.Fatalf("unexpected login response: %+v", mfaResp)
+	}
+
+	watch, err := client.WatchUsers(ctx, &WatchUsersRequest{ResumeToken: "t"})
+	if err != nil {
+		t.Fatalf("WatchUsers: %v", err)
+	}
+	for _, want := range []string{"t1", "t2"} {
+		change, err := watch.Recv()
+		if err != nil {
+			t.Fatalf("WatchUsers recv: %v", err)
+		}
+		if change.GetResumeToken() != want || change.GetType() != UserEventType_USER_EVENT_TYPE_UPDATED {
+			t.Fatalf("unexpected watch response: %+v", change)
+		}
+	}
+	if _, err := watch.Recv(); err != io.EOF {
+		t.Fatalf("expected end of stream got %v", err)
+	}
+}
+
+func TestFileDescriptorResolvesMethodTypes(t *testing.T) {
+	methods := File_proto_user_proto.Services().Get(0).Methods()
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		if want := string(method.Name()) + "Request"; string(method.Input().Name()) != want {
+			t.Fatalf("%s input resolved to %s", method.Name(), method.Input().FullName())
+		}
+		if want := string(method.Name()) + "Response"; string(method.Output().Name()) != want {
+			t.Fatalf("%s output resolved to %s", method.Name(), method.Output().FullName())
+		}
+	}
 }
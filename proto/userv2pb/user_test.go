@@ -0,0 +1,39 @@
+package userv2pb
+
+import (
+	"testing"
+
+	"backend-challenge/proto/userpb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestFileDescriptorResolvesMethodTypes(t *testing.T) {
+	methods := File_proto_v2_user_proto.Services().Get(0).Methods()
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		if want := string(method.Name()) + "Request"; string(method.Input().Name()) != want {
+			t.Fatalf("%s input resolved to %s", method.Name(), method.Input().FullName())
+		}
+		if want := string(method.Name()) + "Response"; string(method.Output().Name()) != want {
+			t.Fatalf("%s output resolved to %s", method.Name(), method.Output().FullName())
+		}
+		if !proto.HasExtension(method.Options(), userpb.E_AuthPolicy) {
+			t.Fatalf("%s has no auth policy", method.Name())
+		}
+	}
+}
+
+func TestWellKnownTypes(t *testing.T) {
+	fields := File_proto_v2_user_proto.Messages().ByName("User").Fields()
+	if got := fields.ByName("create_time").Message().FullName(); got != "google.protobuf.Timestamp" {
+		t.Fatalf("create_time resolved to %s", got)
+	}
+	update := File_proto_v2_user_proto.Messages().ByName("UpdateUserRequest").Fields()
+	if got := update.ByName("name").Message().FullName(); got != "google.protobuf.StringValue" {
+		t.Fatalf("name resolved to %s", got)
+	}
+	if got := update.ByName("update_mask").Message().FullName(); got != "google.protobuf.FieldMask" {
+		t.Fatalf("update_mask resolved to %s", got)
+	}
+}
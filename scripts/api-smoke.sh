@@ -28,6 +28,7 @@ REGISTER_RESPONSE="$(curl -sS -X POST "${API_BASE_URL}/auth/register" \
 echo "${REGISTER_RESPONSE}" | jq '.'
 
 TOKEN="$(echo "${REGISTER_RESPONSE}" | jq -r '.token')"
+REFRESH_TOKEN="$(echo "${REGISTER_RESPONSE}" | jq -r '.refreshToken')"
 USER_ID="$(echo "${REGISTER_RESPONSE}" | jq -r '.user.id')"
 
 if [[ -z "${TOKEN}" || -z "${USER_ID}" || "${TOKEN}" == "null" || "${USER_ID}" == "null" ]]; then
@@ -40,9 +41,16 @@ curl -sS -X POST "${API_BASE_URL}/auth/login" \
   -H "Content-Type: application/json" \
   -d '{"email":"smoke@example.com","password":"changeme123"}' | jq '.'
 
-print_section "List Users"
+print_section "Refresh"
+REFRESH_RESPONSE="$(curl -sS -X POST "${API_BASE_URL}/auth/refresh" \
+  -H "Content-Type: application/json" \
+  -d "{\"refreshToken\":\"${REFRESH_TOKEN}\"}")"
+echo "${REFRESH_RESPONSE}" | jq '.'
+TOKEN="$(echo "${REFRESH_RESPONSE}" | jq -r '.token')"
+
+print_section "List Users (admins only, expect 403)"
 curl -sS "${API_BASE_URL}/users" \
-  -H "Authorization: Bearer ${TOKEN}" | jq '.'
+  -H "Authorization: Bearer ${TOKEN}" -o /dev/null -w "Status: %{http_code}\n"
 
 print_section "Get User"
 curl -sS "${API_BASE_URL}/users/${USER_ID}" \
@@ -54,6 +62,31 @@ curl -sS -X PATCH "${API_BASE_URL}/users/${USER_ID}" \
   -H "Content-Type: application/json" \
   -d '{"name":"Smoke Test Updated"}' | jq '.'
 
+print_section "Forgot Password"
+curl -sS -X POST "${API_BASE_URL}/auth/password/forgot" \
+  -H "Content-Type: application/json" \
+  -d '{"email":"smoke@example.com"}' -o /dev/null -w "Status: %{http_code}\n"
+
+print_section "Logout"
+curl -sS -X POST "${API_BASE_URL}/auth/logout" \
+  -H "Authorization: Bearer ${TOKEN}" -o /dev/null -w "Status: %{http_code}\n"
+
+print_section "Login Again"
+TOKEN="$(curl -sS -X POST "${API_BASE_URL}/auth/login" \
+  -H "Content-Type: application/json" \
+  -d '{"email":"smoke@example.com","password":"changeme123"}' | jq -r '.token')"
+
+print_section "Change Password"
+curl -sS -X POST "${API_BASE_URL}/users/${USER_ID}/password" \
+  -H "Authorization: Bearer ${TOKEN}" \
+  -H "Content-Type: application/json" \
+  -d '{"currentPassword":"changeme123","newPassword":"changeme456"}' -o /dev/null -w "Status: %{http_code}\n"
+
+print_section "Login With New Password"
+TOKEN="$(curl -sS -X POST "${API_BASE_URL}/auth/login" \
+  -H "Content-Type: application/json" \
+  -d '{"email":"smoke@example.com","password":"changeme456"}' | jq -r '.token')"
+
 print_section "Delete User"
 curl -sS -X DELETE "${API_BASE_URL}/users/${USER_ID}" \
   -H "Authorization: Bearer ${TOKEN}" -o /dev/null -w "Status: %{http_code}\n"